@@ -0,0 +1,86 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// AllowlistWriter is a zapcore.WriteSyncer that writes only the allowed fields of the json logs to the output.
+// See: NewAllowlistWriter
+type AllowlistWriter struct {
+	ws      zapcore.WriteSyncer
+	allowed map[string]struct{}
+}
+
+// NewAllowlistWriter returns an AllowlistWriter that strips the fields not in keys from the logs written to ws.
+// The message, the level and the time are always kept, and the other keys such as the caller must be in keys.
+// Only the top level keys are checked, and the logs that are not json objects are dropped.
+// Use it with AddSink to send a sanitized copy to a third-party service while the full logs stay in the file.
+// e.g. zl.AddSink(zl.NewAllowlistWriter(zl.NewOTLPWriter(config), "trace_id", "status", "duration"))
+func NewAllowlistWriter(ws zapcore.WriteSyncer, keys ...string) *AllowlistWriter {
+	allowed := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		allowed[k] = struct{}{}
+	}
+	return &AllowlistWriter{ws: ws, allowed: allowed}
+}
+
+func (w *AllowlistWriter) isAllowed(key string) bool {
+	if key == fieldKey(MessageKey) || key == fieldKey(LevelKey) || key == fieldKey(TimeKey) {
+		return true
+	}
+	_, ok := w.allowed[key]
+	return ok
+}
+
+// Write writes p with the fields not allowed stripped. The order of the fields is kept.
+func (w *AllowlistWriter) Write(p []byte) (int, error) {
+	line, err := w.filter(p)
+	if err != nil {
+		return len(p), nil // Dropped because it can not be checked.
+	}
+	if _, err := w.ws.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *AllowlistWriter) filter(p []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("zl: not a json object")
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if !w.isAllowed(key) {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(raw)
+	}
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
+
+// Sync syncs the output.
+func (w *AllowlistWriter) Sync() error {
+	return w.ws.Sync()
+}
@@ -0,0 +1,46 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewAllowlistWriter(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	var buf bytes.Buffer
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	AddSink(NewAllowlistWriter(zapcore.AddSync(&buf), "status", "user"))
+	Init()
+
+	Info("REQUEST", zap.Int("status", 200), zap.String("email", "alice@example.com"),
+		zap.Any("user", map[string]string{"name": "alice"}))
+	Sync()
+
+	assert.Equal(t, `{"severity":"INFO","message":"REQUEST","status":200,"user":{"name":"alice"}}`+"\n", buf.String())
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"email":"alice@example.com"`, "the full log stays in the file")
+	assert.Contains(t, string(b), `"caller":`)
+	ResetGlobalLoggerSettings()
+}
+
+func TestAllowlistWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewAllowlistWriter(zapcore.AddSync(&buf), "a")
+	n, err := w.Write([]byte("not json\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 9, n)
+	_, err = w.Write([]byte(`{"b":1,"a":[1,{"b":2}],"message":"M"}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":[1,{"b":2}],"message":"M"}`+"\n", buf.String())
+	assert.NoError(t, w.Sync())
+}
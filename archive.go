@@ -0,0 +1,97 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const archiveTimeout = 10 * time.Minute
+
+// Uploader uploads the rotated log files to the storage. e.g. Amazon S3, Google Cloud Storage
+// name is the base name of the file.
+//
+// e.g. Google Cloud Storage
+//
+//	zl.UploaderFunc(func(ctx context.Context, name string, body io.Reader) error {
+//		w := client.Bucket("my-bucket").Object("logs/" + name).NewWriter(ctx)
+//		if _, err := io.Copy(w, body); err != nil {
+//			return err
+//		}
+//		return w.Close()
+//	})
+type Uploader interface {
+	Upload(ctx context.Context, name string, body io.Reader) error
+}
+
+// UploaderFunc is an adapter to use the function as Uploader.
+type UploaderFunc func(ctx context.Context, name string, body io.Reader) error
+
+// Upload calls f(ctx, name, body).
+func (f UploaderFunc) Upload(ctx context.Context, name string, body io.Reader) error {
+	return f(ctx, name, body)
+}
+
+// NewArchiver returns the function for SetOnRotate that uploads the rotated file by the uploader.
+// The SHA-256 checksum is also uploaded as `NAME.sha256` in the format of sha256sum,
+// so that the integrity of the archived files can be verified.
+// If deleteLocal is true, the rotated file is deleted after the upload succeeds.
+// The errors are written to the standard logger because the log file may not be writable.
+//
+//	zl.SetOnRotate(zl.NewArchiver(uploader, true))
+func NewArchiver(uploader Uploader, deleteLocal bool) func(oldPath string) {
+	return func(oldPath string) {
+		ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+		defer cancel()
+		if err := archive(ctx, uploader, oldPath); err != nil {
+			log.Printf("failed to archive %s: %v", oldPath, err)
+			return
+		}
+		if deleteLocal {
+			if err := os.Remove(oldPath); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+func archive(ctx context.Context, uploader Uploader, path string) error {
+	sum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	if err := uploader.Upload(ctx, name, f); err != nil {
+		return err
+	}
+	checksum := fmt.Sprintf("%s  %s\n", sum, name)
+	return uploader.Upload(ctx, name+".sha256", bytes.NewBufferString(checksum))
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of the file.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
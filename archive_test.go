@@ -0,0 +1,54 @@
+package zl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewArchiver(t *testing.T) {
+	newFile := func(t *testing.T) string {
+		path := filepath.Join(t.TempDir(), "app-2023-09-09T00-00-00.000.jsonl")
+		assert.NoError(t, os.WriteFile(path, []byte("test\n"), 0o600))
+		return path
+	}
+
+	t.Run("upload and delete", func(t *testing.T) {
+		uploaded := map[string]string{}
+		uploader := UploaderFunc(func(ctx context.Context, name string, body io.Reader) error {
+			b, err := io.ReadAll(body)
+			uploaded[name] = string(b)
+			return err
+		})
+		path := newFile(t)
+		NewArchiver(uploader, true)(path)
+
+		assert.Equal(t, map[string]string{
+			"app-2023-09-09T00-00-00.000.jsonl": "test\n",
+			"app-2023-09-09T00-00-00.000.jsonl.sha256": "f2ca1bb6c7e907d06dafe4687e579fce76b37e4e93b7605022da52e6ccc26fd2" +
+				"  app-2023-09-09T00-00-00.000.jsonl\n",
+		}, uploaded)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("upload failed", func(t *testing.T) {
+		uploader := UploaderFunc(func(ctx context.Context, name string, body io.Reader) error {
+			return errors.New("upload failed")
+		})
+		path := newFile(t)
+		NewArchiver(uploader, true)(path)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("keep local", func(t *testing.T) {
+		uploader := UploaderFunc(func(ctx context.Context, name string, body io.Reader) error { return nil })
+		path := newFile(t)
+		NewArchiver(uploader, false)(path)
+		assert.FileExists(t, path)
+	})
+}
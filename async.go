@@ -0,0 +1,263 @@
+package zl
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// AsyncPolicy is the policy when the queue of the asynchronous logging is full.
+type AsyncPolicy int
+
+const (
+	// AsyncBlock blocks the logging until the queue has space. No logs are lost.
+	// It is Default setting.
+	AsyncBlock AsyncPolicy = iota
+
+	// AsyncDropOldest drops the oldest queued log to make space for the new log.
+	// The logging never blocks. The number of dropped logs can be got by AsyncDropped.
+	AsyncDropOldest
+)
+
+var asyncPolicyStrings = [2]string{
+	"Block",
+	"DropOldest",
+}
+
+// String is return AsyncPolicy type string.
+func (p AsyncPolicy) String() string {
+	return asyncPolicyStrings[p]
+}
+
+var (
+	asyncBufferSize    int
+	asyncFlushInterval time.Duration
+	asyncPolicy        AsyncPolicy
+
+	// queue is shared by all the loggers so that only one background worker runs.
+	queue   *asyncQueue
+	queueMu sync.Mutex
+)
+
+// SetAsync enables the asynchronous logging that moves the writing of the logs to a background worker,
+// so that the file and network I/O is taken off the hot paths.
+// bufferSize is the number of the logs that can be queued,
+// and flushInterval is the interval to sync the outputs. e.g. SetAsync(4096, time.Second)
+// The queued logs are written by Sync, so Sync must be called before the program exits.
+// 0 bufferSize (default) means the logs are written synchronously.
+func SetAsync(bufferSize int, flushInterval time.Duration) {
+	asyncBufferSize = bufferSize
+	asyncFlushInterval = flushInterval
+}
+
+// SetAsyncPolicy set the policy when the queue of the asynchronous logging is full.
+// option can use (AsyncBlock, AsyncDropOldest).
+func SetAsyncPolicy(option AsyncPolicy) {
+	asyncPolicy = option
+}
+
+// AsyncDropped returns the number of the logs dropped by AsyncDropOldest.
+func AsyncDropped() uint64 {
+	return droppedAsync.Load()
+}
+
+type asyncEntry struct {
+	s *asyncWriteSyncer
+	p []byte
+}
+
+type asyncQueue struct {
+	entries chan asyncEntry
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	policy  AsyncPolicy
+
+	// dirty is the outputs written since the last sync. It is used only by the worker.
+	dirty map[*asyncWriteSyncer]struct{}
+	// worker is the goroutine id of the worker.
+	worker atomic.Uint64
+}
+
+// getAsyncQueue returns the shared queue. It starts the worker at the first call.
+func getAsyncQueue() *asyncQueue {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if queue == nil {
+		queue = newAsyncQueue(asyncBufferSize, asyncFlushInterval, asyncPolicy)
+	}
+	return queue
+}
+
+func newAsyncQueue(size int, interval time.Duration, policy AsyncPolicy) *asyncQueue {
+	q := &asyncQueue{
+		entries: make(chan asyncEntry, size),
+		flushes: make(chan chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		policy:  policy,
+		dirty:   make(map[*asyncWriteSyncer]struct{}),
+	}
+	go q.run(interval)
+	return q
+}
+
+func (q *asyncQueue) run(interval time.Duration) {
+	defer close(q.done)
+	q.worker.Store(goroutineID())
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case e := <-q.entries:
+			q.write(e)
+		case <-tick:
+			q.sync()
+		case flushed := <-q.flushes:
+			q.drain()
+			q.sync()
+			close(flushed)
+		case <-q.stop:
+			q.drain()
+			q.sync()
+			return
+		}
+	}
+}
+
+func (q *asyncQueue) write(e asyncEntry) {
+	if _, err := e.s.ws.Write(e.p); err != nil {
+		log.Println(err)
+	}
+	q.dirty[e.s] = struct{}{}
+}
+
+func (q *asyncQueue) drain() {
+	for {
+		select {
+		case e := <-q.entries:
+			q.write(e)
+		default:
+			return
+		}
+	}
+}
+
+func (q *asyncQueue) sync() {
+	for s := range q.dirty {
+		_ = s.ws.Sync()
+		delete(q.dirty, s)
+	}
+}
+
+func (q *asyncQueue) enqueue(e asyncEntry) {
+	if q.policy == AsyncBlock {
+		select {
+		case q.entries <- e:
+			return
+		default:
+		}
+		// The worker cannot wait for itself when it logs while writing, e.g. the internal logs of the disk guard,
+		// so it writes them synchronously. The goroutine id is got only when the queue is full because it is slow.
+		if goroutineID() == q.worker.Load() {
+			q.write(e)
+			return
+		}
+		q.entries <- e
+		return
+	}
+	for {
+		select {
+		case q.entries <- e:
+			return
+		default:
+		}
+		select {
+		case <-q.entries:
+			addDropped(&droppedAsync, 1)
+		default:
+		}
+	}
+}
+
+// flush waits until all the queued logs are written and synced.
+func (q *asyncQueue) flush() {
+	flushed := make(chan struct{})
+	select {
+	case q.flushes <- flushed:
+		<-flushed
+	case <-q.done:
+	}
+}
+
+// close writes the queued logs and stops the worker.
+func (q *asyncQueue) close() {
+	close(q.stop)
+	<-q.done
+}
+
+// asyncWriteSyncer is a zapcore.WriteSyncer that writes to ws by the background worker.
+type asyncWriteSyncer struct {
+	ws    zapcore.WriteSyncer
+	queue *asyncQueue
+}
+
+func newAsyncWriteSyncer(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if asyncBufferSize <= 0 {
+		return ws
+	}
+	return &asyncWriteSyncer{ws: ws, queue: getAsyncQueue()}
+}
+
+// Write queues a copy of p because zap reuses the buffer.
+func (s *asyncWriteSyncer) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+	s.queue.enqueue(asyncEntry{s: s, p: b})
+	return len(p), nil
+}
+
+// Sync writes all the queued logs and syncs the outputs.
+func (s *asyncWriteSyncer) Sync() error {
+	s.queue.flush()
+	return nil
+}
+
+// asyncQueueDepth returns the number of the queued logs.
+func asyncQueueDepth() int {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if queue == nil {
+		return 0
+	}
+	return len(queue.entries)
+}
+
+// flushAsync writes all the queued logs if the asynchronous logging is enabled.
+func flushAsync() {
+	queueMu.Lock()
+	q := queue
+	queueMu.Unlock()
+	if q != nil {
+		q.flush()
+	}
+}
+
+func resetAsync() {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if queue != nil {
+		queue.close()
+		queue = nil
+	}
+	asyncBufferSize = 0
+	asyncFlushInterval = 0
+	asyncPolicy = AsyncBlock
+}
@@ -0,0 +1,153 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// blockingWriter blocks the writes until release is closed.
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+	syncs   int
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncs++
+	return nil
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestSetAsync(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetAsync(16, time.Hour)
+	Init()
+
+	for i := 0; i < 100; i++ {
+		Info("ASYNC")
+	}
+	Sync()
+	b, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, strings.Count(string(b), `"message":"ASYNC"`))
+	assert.Equal(t, uint64(0), AsyncDropped())
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetAsyncPolicy(t *testing.T) {
+	t.Run("drop oldest", func(t *testing.T) {
+		w := &blockingWriter{release: make(chan struct{})}
+		SetAsync(2, 0)
+		SetAsyncPolicy(AsyncDropOldest)
+		ws := newAsyncWriteSyncer(w)
+		for _, s := range []string{"1", "2", "3", "4", "5"} {
+			_, err := ws.Write([]byte(s))
+			assert.NoError(t, err)
+		}
+		close(w.release)
+		assert.NoError(t, ws.Sync())
+
+		// The worker may have taken one log before the queue became full.
+		assert.Regexp(t, "^[123]?45$", w.String())
+		assert.Equal(t, uint64(5-len(w.String())), AsyncDropped())
+		assert.Equal(t, 1, w.syncs)
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("block", func(t *testing.T) {
+		w := &blockingWriter{release: make(chan struct{})}
+		SetAsync(1, 0)
+		ws := newAsyncWriteSyncer(w)
+		done := make(chan struct{})
+		go func() {
+			for _, s := range []string{"1", "2", "3"} {
+				_, _ = ws.Write([]byte(s))
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatal("the write must be blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(w.release)
+		<-done
+		assert.NoError(t, ws.Sync())
+		assert.Equal(t, "123", w.String())
+		assert.Equal(t, uint64(0), AsyncDropped())
+		ResetGlobalLoggerSettings()
+	})
+}
+
+func Test_asyncQueue_flushInterval(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	close(w.release)
+	SetAsync(1, time.Millisecond)
+	ws := newAsyncWriteSyncer(w)
+	_, err := ws.Write([]byte("1"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.syncs > 0
+	}, time.Second, time.Millisecond)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_newAsyncWriteSyncer_disabled(t *testing.T) {
+	ws := zapcore.AddSync(&bytes.Buffer{})
+	assert.Equal(t, ws, newAsyncWriteSyncer(ws))
+}
+
+func TestSetAsync_internalLogOfWorker(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	free := uint64(0)
+	stubFreeDiskSpace(t, &free)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetDiskGuard(1<<30, DiskGuardConsoleOnly)
+	SetAsync(1, 0)
+	Init()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			Info("ASYNC")
+		}
+		Sync()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the worker is deadlocked by its own internal log")
+	}
+}
@@ -0,0 +1,253 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// AuditFileNameDefault is the default file of the audit logs. See: SetAuditFile
+	AuditFileNameDefault = "./log/audit.jsonl"
+
+	// AuditSignatureEvent is the event of the audit log that has the signature. See: SetAuditSigner
+	AuditSignatureEvent = "AUDIT_SIGNATURE"
+
+	auditEventKey     = "event"
+	auditSeqKey       = "seq"
+	auditPrevHashKey  = "prev_hash"
+	auditSignatureKey = "signature"
+	auditHashSuffix   = `,"hash":"`
+)
+
+var (
+	auditFileName  = AuditFileNameDefault
+	auditSigner    ed25519.PrivateKey
+	auditSignEvery int
+
+	audit   *auditLog
+	auditMu sync.Mutex
+)
+
+// SetAuditFile set the file of the audit logs written by Audit. Default is AuditFileNameDefault.
+// The file is not rotated, and it must not be shared with the other logs.
+func SetAuditFile(fileName string) {
+	auditFileName = fileName
+}
+
+// SetAuditSigner set the key to sign the audit logs.
+// The AuditSignatureEvent log that has the signature of the hash of the previous log is written every n logs,
+// so that the audit logs can not be rewritten without the key even if the whole chain is recalculated.
+func SetAuditSigner(key ed25519.PrivateKey, n int) {
+	auditSigner = key
+	auditSignEvery = n
+}
+
+// auditLog is the state of the hash chain of the audit file.
+type auditLog struct {
+	file     *os.File
+	seq      uint64
+	prevHash string
+	unsigned int
+}
+
+// Audit writes the audit log of event to the file set by SetAuditFile.
+// The audit logs are separated from the application logs and written regardless of SetLevel and SetOutput.
+// Each log has the sequence number and the SHA-256 hash chained from the previous log,
+// so that any modification, deletion or reordering of them can be detected by VerifyAuditLog.
+// The file is opened in the append-only mode and synced at each log.
+// e.g. zl.Audit("USER_DELETED", zap.String("actor", actor), zap.String("target", userID))
+func Audit(event string, fields ...zap.Field) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	a, err := getAuditLog()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := a.write(event, redactFields(fields)); err != nil {
+		log.Println(err)
+		return
+	}
+	if auditSigner != nil && auditSignEvery > 0 {
+		a.unsigned++
+		if a.unsigned >= auditSignEvery {
+			sig := ed25519.Sign(auditSigner, []byte(a.prevHash))
+			err := a.write(AuditSignatureEvent, []zap.Field{
+				zap.String(auditSignatureKey, base64.StdEncoding.EncodeToString(sig)),
+			})
+			if err != nil {
+				log.Println(err)
+			}
+			a.unsigned = 0
+		}
+	}
+}
+
+// getAuditLog opens the audit file and continues the chain from the last log. It must be called with auditMu locked.
+func getAuditLog() (*auditLog, error) {
+	if audit != nil {
+		return audit, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(auditFileName), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(auditFileName, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	a := &auditLog{file: f}
+	var last auditEntry
+	if err := scanAuditLog(f, func(_ int, e auditEntry, _ []byte) error {
+		last = e
+		return nil
+	}); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	a.seq, a.prevHash = last.Seq, last.Hash
+	audit = a
+	return a, nil
+}
+
+func (a *auditLog) write(event string, fields []zap.Field) error {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:    fieldKey(TimeKey),
+		MessageKey: auditEventKey,
+		EncodeTime: zapcore.RFC3339NanoTimeEncoder,
+	})
+	fields = append(fields[:len(fields):len(fields)],
+		zap.Uint64(auditSeqKey, a.seq+1),
+		zap.String(auditPrevHashKey, a.prevHash),
+	)
+	buf, err := enc.EncodeEntry(zapcore.Entry{Time: clock.Now(), Message: event}, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	body := bytes.TrimSuffix(buf.Bytes(), []byte("}\n"))
+	hash := auditHash(append(body[:len(body):len(body)], '}'))
+	line := append(body, []byte(auditHashSuffix+hash+"\"}\n")...)
+	if _, err := a.file.Write(line); err != nil {
+		return err
+	}
+	if err := a.file.Sync(); err != nil {
+		return err
+	}
+	a.seq++
+	a.prevHash = hash
+	return nil
+}
+
+func auditHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditEntry is the chain fields of an audit log.
+type auditEntry struct {
+	Event     string `json:"event"`
+	Seq       uint64 `json:"seq"`
+	PrevHash  string `json:"prev_hash"`
+	Signature string `json:"signature"`
+	Hash      string `json:"-"`
+}
+
+// scanAuditLog calls fn with each verified log in r. It returns an error at the first broken log.
+func scanAuditLog(r io.Reader, fn func(line int, e auditEntry, body []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var prev auditEntry
+	ln := 0
+	for scanner.Scan() {
+		ln++
+		line := scanner.Bytes()
+		i := bytes.LastIndex(line, []byte(auditHashSuffix))
+		if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+			return fmt.Errorf("zl: audit log line %d: no hash", ln)
+		}
+		body := append(line[:i:i], '}')
+		var e auditEntry
+		if err := json.Unmarshal(body, &e); err != nil {
+			return fmt.Errorf("zl: audit log line %d: %w", ln, err)
+		}
+		e.Hash = string(line[i+len(auditHashSuffix) : len(line)-2])
+		if auditHash(body) != e.Hash {
+			return fmt.Errorf("zl: audit log line %d: hash mismatch", ln)
+		}
+		if e.Seq != prev.Seq+1 || e.PrevHash != prev.Hash {
+			return fmt.Errorf("zl: audit log line %d: broken chain", ln)
+		}
+		if err := fn(ln, e, body); err != nil {
+			return err
+		}
+		prev = e
+	}
+	return scanner.Err()
+}
+
+// AuditReport is the result of VerifyAuditLog.
+type AuditReport struct {
+	Entries    int       // The number of the audit logs including the signatures.
+	Signatures int       // The number of the verified signatures.
+	LastSeq    uint64    // The sequence number of the last log.
+	LastHash   string    // The hash of the last log. Keep it elsewhere to detect the truncation of the file.
+	Verified   time.Time // The time of the verification.
+}
+
+// VerifyAuditLog verifies the hash chain of the audit file written by Audit.
+// If publicKey is not nil, the signatures of SetAuditSigner are also verified.
+// It returns an error with the line number at the first modified, deleted or reordered log.
+// The truncation of the last logs can be detected by comparing AuditReport.LastHash with the kept one.
+func VerifyAuditLog(fileName string, publicKey ed25519.PublicKey) (*AuditReport, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	report := &AuditReport{Verified: clock.Now()}
+	var prevHash string
+	err = scanAuditLog(f, func(line int, e auditEntry, _ []byte) error {
+		if e.Event == AuditSignatureEvent && publicKey != nil {
+			sig, err := base64.StdEncoding.DecodeString(e.Signature)
+			if err != nil || !ed25519.Verify(publicKey, []byte(prevHash), sig) {
+				return fmt.Errorf("zl: audit log line %d: invalid signature", line)
+			}
+			report.Signatures++
+		}
+		report.Entries++
+		report.LastSeq, report.LastHash = e.Seq, e.Hash
+		prevHash = e.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// closeAudit closes the audit file.
+func closeAudit() {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if audit != nil {
+		if err := audit.file.Close(); err != nil {
+			log.Println(err)
+		}
+		audit = nil
+	}
+}
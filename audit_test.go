@@ -0,0 +1,98 @@
+package zl
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAudit(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	SetAuditFile(file)
+	SetClock(&fixedClock{time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)})
+
+	Audit("USER_DELETED", zap.String("actor", "alice"), zap.String("target", "bob"))
+	Audit("ROLE_CHANGED", zap.String("actor", "alice"))
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `^\{"timestamp":"2023-09-09T15:00:00Z","event":"USER_DELETED","actor":"alice","target":"bob",`+
+		`"seq":1,"prev_hash":"","hash":"[0-9a-f]{64}"\}$`, lines[0])
+	assert.Regexp(t, `"seq":2,"prev_hash":"`+lines[0][len(lines[0])-66:len(lines[0])-2]+`","hash":"[0-9a-f]{64}"\}$`, lines[1])
+
+	// The chain is continued after the file is reopened.
+	ResetGlobalLoggerSettings()
+	SetAuditFile(file)
+	Audit("LOGOUT")
+	report, err := VerifyAuditLog(file, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Entries)
+	assert.Equal(t, uint64(3), report.LastSeq)
+	assert.Len(t, report.LastHash, 64)
+	ResetGlobalLoggerSettings()
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	SetAuditFile(file)
+	for _, event := range []string{"A", "B", "C"} {
+		Audit(event, zap.String("actor", "alice"))
+	}
+	closeAudit()
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	lines := strings.SplitAfter(string(b), "\n")
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"modified", lines[0] + strings.Replace(lines[1], "alice", "mallory", 1) + lines[2], "line 2: hash mismatch"},
+		{"deleted", lines[0] + lines[2], "line 2: broken chain"},
+		{"reordered", lines[1] + lines[0] + lines[2], "line 1: broken chain"},
+		{"no hash", lines[0] + "{}\n", "line 2: no hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "audit.jsonl")
+			assert.NoError(t, os.WriteFile(f, []byte(tt.content), 0o600))
+			_, err := VerifyAuditLog(f, nil)
+			assert.EqualError(t, err, "zl: audit log "+tt.wantErr)
+		})
+	}
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetAuditSigner(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	SetAuditFile(file)
+	SetAuditSigner(priv, 2)
+	for _, event := range []string{"A", "B", "C", "D", "E"} {
+		Audit(event)
+	}
+	closeAudit()
+
+	report, err := VerifyAuditLog(file, pub)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, report.Entries)
+	assert.Equal(t, 2, report.Signatures)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	_, err = VerifyAuditLog(file, other)
+	assert.EqualError(t, err, "zl: audit log line 3: invalid signature")
+	ResetGlobalLoggerSettings()
+}
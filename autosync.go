@@ -0,0 +1,69 @@
+package zl
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	autoSyncMu   sync.Mutex
+	autoSyncStop func()
+)
+
+// AutoSync flushes the logs queued by SetAsync and buffered by the sinks (e.g. BatchWriter), and syncs the outputs
+// every interval in the background, so that the long-lived daemons do not lose the buffered logs
+// when they are killed by SIGKILL or the OOM killer.
+// Unlike Sync, it does not display the error report of PrettyOutput.
+// It replaces the previous AutoSync, and 0 or less interval stops it. It returns the func to stop it.
+// It must be called after Init.
+func AutoSync(interval time.Duration) (stop func()) {
+	checkInit()
+	autoSyncMu.Lock()
+	defer autoSyncMu.Unlock()
+	stopAutoSync()
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	logger := zapLogger
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				flushAsync()
+				reportDropped()
+				if err := logger.Sync(); err != nil {
+					log.Println(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	autoSyncStop = func() {
+		stopOnce.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+	return autoSyncStop
+}
+
+func resetAutoSync() {
+	autoSyncMu.Lock()
+	defer autoSyncMu.Unlock()
+	stopAutoSync()
+}
+
+// stopAutoSync stops the current AutoSync. autoSyncMu must be held.
+func stopAutoSync() {
+	if autoSyncStop != nil {
+		autoSyncStop()
+		autoSyncStop = nil
+	}
+}
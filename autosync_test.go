@@ -0,0 +1,47 @@
+package zl
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoSync(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	var mu sync.Mutex
+	sent := 0
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	AddSink(NewBatchWriter(func(batch [][]byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent += len(batch)
+		return nil
+	}, 0, 0, 0))
+	Init()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+
+	stop := AutoSync(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		Info("AUTO_SYNC")
+	}
+	assert.Eventually(t, func() bool { return count() == 3 }, time.Second, 5*time.Millisecond)
+
+	stop()
+	stop()
+	Info("AFTER_STOP")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 3, count(), "the logs are kept in the batch after stop")
+
+	AutoSync(10 * time.Millisecond)
+	AutoSync(0)
+	assert.Nil(t, autoSyncStop)
+}
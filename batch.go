@@ -0,0 +1,143 @@
+package zl
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// BatchFlushFunc sends a batch of the encoded logs to a network sink.
+// Each log in batch is a json line with the trailing newline.
+type BatchFlushFunc func(batch [][]byte) error
+
+// BatchWriter is a zapcore.WriteSyncer that collects the logs and sends them by BatchFlushFunc in batches.
+// It is the batching layer shared by the network sinks. See: AddSink
+type BatchWriter struct {
+	flush      BatchFlushFunc
+	maxEntries int
+	maxBytes   int
+
+	// mu is also held while sending so that the batches are sent in order.
+	mu    sync.Mutex
+	batch [][]byte
+	size  int
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// healthMu guards the result of the sends without waiting for the running send. See: Healthy
+	healthMu    sync.Mutex
+	sendErr     error
+	failedSince time.Time
+}
+
+// NewBatchWriter returns a BatchWriter.
+// The batch is sent when it has maxBatchEntries logs, when adding a log makes it larger than maxBatchBytes,
+// every flushInterval, and by Sync and Close. 0 means each of them is not used.
+// The batch is sent on the goroutine of the logging, so use it with SetAsync if the sink is slow.
+// e.g. NewBatchWriter(send, 1000, 1<<20, time.Second)
+func NewBatchWriter(
+	flush BatchFlushFunc, maxBatchEntries, maxBatchBytes int, flushInterval time.Duration,
+) *BatchWriter {
+	w := &BatchWriter{
+		flush:      flush,
+		maxEntries: maxBatchEntries,
+		maxBytes:   maxBatchBytes,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go w.run(flushInterval)
+	return w
+}
+
+func (w *BatchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	if interval <= 0 {
+		<-w.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Sync(); err != nil {
+				log.Println(err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Write adds a copy of p to the batch because zap reuses the buffer.
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.maxBytes > 0 && len(w.batch) > 0 && w.size+len(b) > w.maxBytes {
+		if err := w.send(); err != nil {
+			return 0, err
+		}
+	}
+	w.batch = append(w.batch, b)
+	w.size += len(b)
+	if w.maxEntries > 0 && len(w.batch) >= w.maxEntries {
+		if err := w.send(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Sync sends the batch.
+func (w *BatchWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.send()
+}
+
+// Close stops the flush interval and sends the batch.
+func (w *BatchWriter) Close() error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+	return w.Sync()
+}
+
+// send sends the batch. It must be called with mu locked.
+// The batch is cleared even if it fails so that a broken sink does not hold the logs forever.
+func (w *BatchWriter) send() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	batch := w.batch
+	w.batch = nil
+	w.size = 0
+	err := w.flush(batch)
+	w.healthMu.Lock()
+	if err != nil && w.sendErr == nil {
+		w.failedSince = clock.Now()
+	}
+	w.sendErr = err
+	w.healthMu.Unlock()
+	if err != nil {
+		addDropped(&droppedBatch, uint64(len(batch)))
+		return err
+	}
+	return nil
+}
+
+// Healthy returns the error of the last send if the sends have kept failing for the window of SetHealthWindow.
+// It is nil after a send succeeds. See: zl.Healthy
+func (w *BatchWriter) Healthy() error {
+	w.healthMu.Lock()
+	defer w.healthMu.Unlock()
+	if w.sendErr == nil || clock.Now().Sub(w.failedSince) < healthWindow {
+		return nil
+	}
+	return fmt.Errorf("zl: sink has failed since %s: %w", w.failedSince.Format(time.RFC3339), w.sendErr)
+}
@@ -0,0 +1,84 @@
+package zl
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder records the batches sent by BatchWriter.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []string
+	err     error
+}
+
+func (r *batchRecorder) flush(batch [][]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var s []string
+	for _, b := range batch {
+		s = append(s, string(b))
+	}
+	r.batches = append(r.batches, strings.Join(s, ","))
+	return r.err
+}
+
+func (r *batchRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.batches...)
+}
+
+func TestNewBatchWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int
+		want       []string
+	}{
+		{"max entries", 2, 0, []string{"a,bb", "ccc,dd", "e"}},
+		{"max bytes", 0, 4, []string{"a,bb", "ccc", "dd,e"}},
+		{"no limits", 0, 0, []string{"a,bb,ccc,dd,e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &batchRecorder{}
+			w := NewBatchWriter(r.flush, tt.maxEntries, tt.maxBytes, 0)
+			p := []byte("")
+			for _, s := range []string{"a", "bb", "ccc", "dd", "e"} {
+				p = append(p[:0], s...) // The buffer is reused like zap.
+				_, err := w.Write(p)
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, w.Close())
+			assert.Equal(t, tt.want, r.get())
+		})
+	}
+}
+
+func TestBatchWriter_flushInterval(t *testing.T) {
+	r := &batchRecorder{}
+	w := NewBatchWriter(r.flush, 0, 0, time.Millisecond)
+	_, err := w.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(r.get()) == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close(), "Close can be called twice")
+	assert.Equal(t, []string{"a"}, r.get())
+}
+
+func TestBatchWriter_Sync(t *testing.T) {
+	r := &batchRecorder{err: errors.New("send error")}
+	w := NewBatchWriter(r.flush, 0, 0, 0)
+	assert.NoError(t, w.Sync(), "an empty batch is not sent")
+	_, err := w.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.EqualError(t, w.Sync(), "send error")
+	assert.NoError(t, w.Close(), "the failed batch is cleared")
+	assert.Equal(t, []string{"a"}, r.get())
+}
@@ -0,0 +1,68 @@
+package zl
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+const (
+	shortRevisionLength = 7
+	dirtySuffix         = "-dirty"
+)
+
+var (
+	gitVersionFallback bool
+	// readBuildInfo is a variable so that tests can replace it.
+	readBuildInfo = debug.ReadBuildInfo
+)
+
+// getBuildVersion returns the version embedded in the binary by the go command.
+// It is the short vcs.revision (with "-dirty" suffix when vcs.modified is true),
+// or the main module version when the binary is built with `go install module@version`.
+// See: https://pkg.go.dev/runtime/debug#BuildInfo
+func getBuildVersion() string {
+	info, ok := readBuildInfo()
+	if !ok {
+		return ""
+	}
+	revision := buildSetting(info, "vcs.revision")
+	if revision != "" {
+		if len(revision) > shortRevisionLength {
+			revision = revision[:shortRevisionLength]
+		}
+		if buildSetting(info, "vcs.modified") == "true" {
+			revision += dirtySuffix
+		}
+		return revision
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// getVCSTime returns the commit time embedded in the binary (RFC3339 format).
+func getVCSTime() string {
+	info, ok := readBuildInfo()
+	if !ok {
+		return ""
+	}
+	return buildSetting(info, "vcs.time")
+}
+
+// getGoVersion returns the Go version used to build the binary.
+func getGoVersion() string {
+	if info, ok := readBuildInfo(); ok && info.GoVersion != "" {
+		return info.GoVersion
+	}
+	return runtime.Version()
+}
+
+func buildSetting(info *debug.BuildInfo, key string) string {
+	for i := range info.Settings {
+		if info.Settings[i].Key == key {
+			return info.Settings[i].Value
+		}
+	}
+	return ""
+}
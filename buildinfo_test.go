@@ -0,0 +1,98 @@
+package zl
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stubBuildInfo(t *testing.T, info *debug.BuildInfo) {
+	t.Helper()
+	org := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return info, info != nil }
+	resetProcessInfo()
+	t.Cleanup(func() {
+		readBuildInfo = org
+		resetProcessInfo()
+	})
+}
+
+func Test_getBuildVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     *debug.BuildInfo
+		expected string
+	}{
+		{
+			name: "vcs revision",
+			info: &debug.BuildInfo{Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "e86b9a7c4d2f0e1b3a5c7d9e0f1a2b3c4d5e6f70"},
+				{Key: "vcs.modified", Value: "false"},
+			}},
+			expected: "e86b9a7",
+		},
+		{
+			name: "vcs modified",
+			info: &debug.BuildInfo{Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "e86b9a7c4d2f0e1b3a5c7d9e0f1a2b3c4d5e6f70"},
+				{Key: "vcs.modified", Value: "true"},
+			}},
+			expected: "e86b9a7-dirty",
+		},
+		{
+			name:     "module version",
+			info:     &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}},
+			expected: "v1.2.3",
+		},
+		{
+			name:     "devel",
+			info:     &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}},
+			expected: "",
+		},
+		{
+			name:     "no build info",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubBuildInfo(t, tt.info)
+			assert.Equal(t, tt.expected, getBuildVersion())
+		})
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	t.Run("set version", func(t *testing.T) {
+		stubBuildInfo(t, &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}})
+		SetVersion("v1.0.0")
+		assert.Equal(t, "v1.0.0", GetVersion())
+		ResetGlobalLoggerSettings()
+	})
+	t.Run("build info", func(t *testing.T) {
+		stubBuildInfo(t, &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}})
+		assert.Equal(t, "v1.2.3", GetVersion())
+	})
+	t.Run("undefined", func(t *testing.T) {
+		stubBuildInfo(t, nil)
+		assert.Equal(t, "undefined", GetVersion())
+	})
+}
+
+func Test_getAdditionalFields_buildInfo(t *testing.T) {
+	stubBuildInfo(t, &debug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Settings:  []debug.BuildSetting{{Key: "vcs.time", Value: "2023-09-09T06:53:17Z"}},
+	})
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	SetEnableKeys(VCSTimeKey, GoVersionKey)
+
+	fields := getAdditionalFields()
+	assert.Len(t, fields, 2)
+	assert.Equal(t, string(VCSTimeKey), fields[0].Key)
+	assert.Equal(t, "2023-09-09T06:53:17Z", fields[0].String)
+	assert.Equal(t, string(GoVersionKey), fields[1].Key)
+	assert.Equal(t, "go1.21.0", fields[1].String)
+	ResetGlobalLoggerSettings()
+}
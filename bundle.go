@@ -0,0 +1,219 @@
+package zl
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultBundleMaxAge is the default age of the oldest log included by ExportBundle.
+const DefaultBundleMaxAge = 24 * time.Hour
+
+// BundleOptions is the options of ExportBundle.
+type BundleOptions struct {
+	// Dir is the directory of the log files. Default is the directory of the file set by SetRotateFileName.
+	Dir string
+	// MaxAge is the age of the oldest log to include. Default is DefaultBundleMaxAge.
+	MaxAge time.Duration
+}
+
+// bundleBuildInfo is the build.json of the support bundle.
+type bundleBuildInfo struct {
+	Version     string `json:"version,omitempty"`
+	Service     string `json:"service,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	GoVersion   string `json:"go_version"`
+	VCSTime     string `json:"vcs_time,omitempty"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+	Hostname    string `json:"hostname,omitempty"`
+	PID         int    `json:"pid"`
+	Exported    string `json:"exported"`
+}
+
+// ExportBundle writes the support bundle for the bug reports to w as a zip file. It has
+//   - build.json: the version, the service and the build info of the binary.
+//   - stats.json: the statistics of the logger. See: Stats
+//   - recent.jsonl: the logs kept by the flight recorder. See: SetFlightRecorder
+//   - logs/: the logs of the current and the rotated files in Dir within MaxAge. The compressed files are decompressed.
+//
+// The values of the keys set by SetRedactKeys are redacted, and the scrubbers set by SetScrubbers are applied,
+// so that the logs written before the settings are also redacted.
+// The logs are not synced. Call Sync before it to include the buffered logs.
+func ExportBundle(w io.Writer, opts BundleOptions) error {
+	if opts.Dir == "" {
+		opts.Dir = filepath.Dir(bundleFileName())
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultBundleMaxAge
+	}
+	zw := zip.NewWriter(w)
+	if err := writeBundleJSON(zw, "build.json", newBundleBuildInfo()); err != nil {
+		return err
+	}
+	if err := writeBundleJSON(zw, "stats.json", Stats()); err != nil {
+		return err
+	}
+	if err := writeBundleRecent(zw); err != nil {
+		return err
+	}
+	since := clock.Now().Add(-opts.MaxAge)
+	files, err := queryFiles(opts.Dir, since)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := writeBundleLogFile(zw, file, since); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func bundleFileName() string {
+	if fileName == "" {
+		return FileNameDefault
+	}
+	return currentFileName()
+}
+
+func newBundleBuildInfo() bundleBuildInfo {
+	host, _ := os.Hostname()
+	return bundleBuildInfo{
+		Version:     GetVersion(),
+		Service:     serviceName,
+		Environment: environment,
+		GoVersion:   getGoVersion(),
+		VCSTime:     getVCSTime(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Hostname:    host,
+		PID:         os.Getpid(),
+		Exported:    clock.Now().Format(time.RFC3339Nano),
+	}
+}
+
+func writeBundleJSON(zw *zip.Writer, name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func writeBundleRecent(zw *zip.Writer) error {
+	recorderMu.Lock()
+	r := recorder
+	recorderMu.Unlock()
+	if r == nil {
+		return nil
+	}
+	f, err := zw.Create("recent.jsonl")
+	if err != nil {
+		return err
+	}
+	for _, line := range r.recent() {
+		if _, err := f.Write(append(redactLine([]byte(strings.TrimSuffix(line, "\n"))), '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBundleLogFile writes the lines of file since since. The lines without the time are always written.
+func writeBundleLogFile(zw *zip.Writer, file string, since time.Time) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	r, err := newDecompressReader(src, file)
+	if err != nil {
+		return fmt.Errorf("zl: bundle %s: %w", file, err)
+	}
+	defer r.Close()
+
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), CompressionGzip.extension()), CompressionZstd.extension())
+	dst, err := zw.Create("logs/" + name)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxQueryLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if e, ok := (Filter{Level: DebugLevel}).match(line); ok && !e.Time.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if _, err := dst.Write(append(redactLine(line), '\n')); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("zl: bundle %s: %w", file, err)
+	}
+	return nil
+}
+
+// redactLine returns the json log line with the redact keys and the scrubbers applied.
+// The line that is not a json log is scrubbed as a string.
+// The hash keys are not applied because the values have already been hashed when they were written.
+func redactLine(line []byte) []byte {
+	if len(redactKeys) == 0 && len(scrubbers) == 0 {
+		return line
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil {
+		s, _ := scrubString(string(line))
+		return []byte(s)
+	}
+	if _, ok := redactBundleJSON(v); !ok {
+		return line
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return line
+	}
+	return b
+}
+
+func redactBundleJSON(v interface{}) (interface{}, bool) {
+	changed := false
+	switch val := v.(type) {
+	case string:
+		return scrubString(val)
+	case map[string]interface{}:
+		for k, child := range val {
+			if isRedactKey(k) {
+				val[k] = RedactedValue
+				changed = true
+			} else if redacted, ok := redactBundleJSON(child); ok {
+				val[k] = redacted
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i := range val {
+			if redacted, ok := redactBundleJSON(val[i]); ok {
+				val[i] = redacted
+				changed = true
+			}
+		}
+	}
+	return v, changed
+}
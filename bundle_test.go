@@ -0,0 +1,90 @@
+package zl
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func readBundle(t *testing.T, b []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		body, _ := io.ReadAll(r)
+		_ = r.Close()
+		files[f.Name] = string(body)
+	}
+	return files
+}
+
+func TestExportBundle(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	SetClock(&fixedClock{t: now})
+	SetOutput(FileOutput)
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey, CallerKey, FunctionKey)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetVersion("v1.2.3")
+	SetFlightRecorder(2, "")
+	Init()
+	Info("USER_LOGIN", zap.String("password", "secret1"))
+	Sync()
+
+	writeCompressedLog(t, filepath.Join(dir, "app-2026-10-14T09-00-00.000.jsonl.gz"), CompressionGzip,
+		`{"severity":"INFO","timestamp":"2026-10-12T09:00:00Z","message":"TOO_OLD"}`+"\n"+
+			`{"severity":"INFO","timestamp":"2026-10-14T09:00:00Z","message":"RECENT","user":{"password":"secret2"}}`+"\n")
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "app-2026-10-14T09-00-00.000.jsonl.gz"), now, now))
+	SetRedactKeys("password")
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportBundle(&buf, BundleOptions{}))
+	files := readBundle(t, buf.Bytes())
+
+	var info bundleBuildInfo
+	assert.NoError(t, json.Unmarshal([]byte(files["build.json"]), &info))
+	assert.Equal(t, "v1.2.3", info.Version)
+	assert.Equal(t, os.Getpid(), info.PID)
+
+	var stats LoggerStats
+	assert.NoError(t, json.Unmarshal([]byte(files["stats.json"]), &stats))
+	assert.Equal(t, uint64(1), stats.Entries["INFO"])
+
+	assert.Contains(t, files["recent.jsonl"], `"message":"USER_LOGIN"`)
+	assert.Contains(t, files["logs/app.jsonl"], `"password":"[REDACTED]"`)
+	assert.NotContains(t, files["logs/app.jsonl"], "secret1")
+	assert.Equal(t,
+		`{"message":"RECENT","severity":"INFO","timestamp":"2026-10-14T09:00:00Z","user":{"password":"[REDACTED]"}}`+"\n",
+		files["logs/app-2026-10-14T09-00-00.000.jsonl"])
+}
+
+func TestExportBundle_noDir(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	err := ExportBundle(io.Discard, BundleOptions{Dir: filepath.Join(t.TempDir(), "none")})
+	assert.Error(t, err)
+}
+
+func Test_redactLine(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	line := []byte(`{"message":"M","token":"x"}`)
+	assert.Equal(t, line, redactLine(line))
+	SetScrubbers(EmailScrubber)
+	assert.Equal(t, []byte(`{"message":"M","token":"x"}`), redactLine(line))
+	assert.Equal(t, "mail to [EMAIL]", string(redactLine([]byte("mail to alice@example.com"))))
+}
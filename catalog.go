@@ -0,0 +1,101 @@
+package zl
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// UnknownMessageMessage is the message of the internal WARN log of the messages that are not registered by RegisterMessages.
+const UnknownMessageMessage = "ZL_UNKNOWN_MESSAGE"
+
+var (
+	messageCatalog   map[string]struct{}
+	messageCatalogMu sync.RWMutex
+
+	// unknownMessages has the reported messages so that each of them is reported once.
+	unknownMessages sync.Map
+)
+
+// RegisterMessages registers the message keys such as "USER_CREATED" that are allowed to be logged.
+// When PrettyOutput, which is recommended for the development, is used and any message is registered,
+// the internal WARN log of UnknownMessageMessage is written once for each unregistered message,
+// so that the dashboards and the alerts built on the message keys are kept stable.
+// It can be called multiple times, such as in the init functions of the packages.
+func RegisterMessages(keys ...string) {
+	messageCatalogMu.Lock()
+	defer messageCatalogMu.Unlock()
+	if messageCatalog == nil {
+		messageCatalog = make(map[string]struct{}, len(keys))
+	}
+	for _, k := range keys {
+		messageCatalog[k] = struct{}{}
+	}
+}
+
+// isRegisteredMessage reports whether message is registered. All the messages are registered if the catalog is empty.
+func isRegisteredMessage(message string) bool {
+	messageCatalogMu.RLock()
+	defer messageCatalogMu.RUnlock()
+	if len(messageCatalog) == 0 {
+		return true
+	}
+	_, ok := messageCatalog[message]
+	return ok
+}
+
+func reportUnknownMessage(ent zapcore.Entry) {
+	if internalLogger == nil || isRegisteredMessage(ent.Message) {
+		return
+	}
+	if _, loaded := unknownMessages.LoadOrStore(ent.Message, struct{}{}); loaded {
+		return
+	}
+	iWarn(UnknownMessageMessage,
+		zap.String("log_message", ent.Message),
+		zap.String("log_caller", ent.Caller.TrimmedPath()),
+	)
+}
+
+// messageCatalogCore is a zapcore.Core that reports the unregistered messages.
+// It is not used by the internal logger because the internal messages are not registered.
+type messageCatalogCore struct {
+	zapcore.Core
+}
+
+func newMessageCatalogCore(core zapcore.Core) zapcore.Core {
+	if outputType != PrettyOutput {
+		return core
+	}
+	return &messageCatalogCore{Core: core}
+}
+
+func (c *messageCatalogCore) With(fields []zap.Field) zapcore.Core {
+	return &messageCatalogCore{Core: c.Core.With(fields)}
+}
+
+// Check delegates to the wrapped core so that a Tee checks the level of each core,
+// and reports the message only if it is written.
+func (c *messageCatalogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ret := c.Core.Check(ent, ce)
+	if ret != nil && ret != ce {
+		reportUnknownMessage(ent)
+	}
+	return ret
+}
+
+// withoutMessageCatalog removes the messageCatalogCore from the core of the internal logger.
+func withoutMessageCatalog(core zapcore.Core) zapcore.Core {
+	if c, ok := core.(*messageCatalogCore); ok {
+		return c.Core
+	}
+	return core
+}
+
+func resetMessageCatalog() {
+	messageCatalogMu.Lock()
+	defer messageCatalogMu.Unlock()
+	messageCatalog = nil
+	unknownMessages = sync.Map{}
+}
@@ -0,0 +1,50 @@
+package zl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterMessages(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(PrettyOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	RegisterMessages("USER_CREATED")
+	RegisterMessages("USER_DELETED")
+	Init()
+
+	Info("USER_CREATED")
+	Info("USER_UPDATED")
+	New().Named("user").Warn("USER_UPDATED")
+	Info("USER_DELETED")
+
+	warns := obs.FilterMessage(UnknownMessageMessage).All()
+	if !assert.Len(t, warns, 1) {
+		return
+	}
+	assert.Equal(t, "USER_UPDATED", warns[0].ContextMap()["log_message"])
+	assert.Equal(t, 2, obs.FilterMessage("USER_UPDATED").Len())
+}
+
+func TestRegisterMessages_fileOutput(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	RegisterMessages("USER_CREATED")
+	Init()
+
+	Info("USER_UPDATED")
+	assert.Equal(t, 0, obs.FilterMessage(UnknownMessageMessage).Len())
+}
+
+func Test_isRegisteredMessage(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	assert.True(t, isRegisteredMessage("ANY"))
+	RegisterMessages("USER_CREATED")
+	assert.True(t, isRegisteredMessage("USER_CREATED"))
+	assert.False(t, isRegisteredMessage("ANY"))
+}
@@ -0,0 +1,64 @@
+package zl
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrChaos is the error of the writes and the syncs failed by ChaosSink.
+var ErrChaos = errors.New("zl: chaos failure")
+
+// ChaosSink is a zapcore.WriteSyncer that randomly fails a percentage of the writes and the syncs of a sink,
+// so that the failover, the buffering and the alerting of the logging can be verified in the resilience tests.
+// The failed writes are not written to the sink.
+// e.g.
+//
+//	zl.AddSink(zl.NewChaosSink(zl.NewBatchWriter(send, 1000, 1<<20, time.Second), 0.1)) // 10% of the writes fail.
+type ChaosSink struct {
+	zapcore.WriteSyncer
+	rate   atomic.Uint64 // rate is the bits of the float64 failure rate.
+	random func() float64
+}
+
+// NewChaosSink returns the ChaosSink of ws that fails failureRate (0 to 1) of the writes and the syncs.
+func NewChaosSink(ws zapcore.WriteSyncer, failureRate float64) *ChaosSink {
+	s := &ChaosSink{WriteSyncer: ws, random: rand.Float64}
+	s.SetFailureRate(failureRate)
+	return s
+}
+
+// SetFailureRate changes the failure rate (0 to 1). It can be called while the logs are written.
+func (s *ChaosSink) SetFailureRate(failureRate float64) {
+	s.rate.Store(math.Float64bits(math.Max(0, math.Min(1, failureRate))))
+}
+
+func (s *ChaosSink) fail() bool {
+	rate := math.Float64frombits(s.rate.Load())
+	return rate > 0 && s.random() < rate
+}
+
+func (s *ChaosSink) Write(p []byte) (int, error) {
+	if s.fail() {
+		return 0, ErrChaos
+	}
+	return s.WriteSyncer.Write(p)
+}
+
+func (s *ChaosSink) Sync() error {
+	if s.fail() {
+		return ErrChaos
+	}
+	return s.WriteSyncer.Sync()
+}
+
+// Healthy returns the health of the sink if it implements HealthChecker. The chaos failures are not reported.
+func (s *ChaosSink) Healthy() error {
+	if c, ok := s.WriteSyncer.(HealthChecker); ok {
+		return c.Healthy()
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package zl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestChaosSink(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewChaosSink(zapcore.AddSync(&buf), 0.5)
+	values := []float64{0.1, 0.9, 0.5, 0.2}
+	s.random = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+
+	_, err := s.Write([]byte("a"))
+	assert.ErrorIs(t, err, ErrChaos)
+	n, err := s.Write([]byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = s.Write([]byte("c"))
+	assert.NoError(t, err, "the rate is exclusive")
+	assert.ErrorIs(t, s.Sync(), ErrChaos)
+	assert.Equal(t, "bc", buf.String())
+
+	s.SetFailureRate(0)
+	_, err = s.Write([]byte("d"))
+	assert.NoError(t, err)
+	s.SetFailureRate(2)
+	s.random = func() float64 { return 0.99 }
+	_, err = s.Write([]byte("e"))
+	assert.ErrorIs(t, err, ErrChaos, "the rate is up to 1")
+	assert.NoError(t, s.Healthy())
+}
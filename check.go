@@ -0,0 +1,76 @@
+package zl
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// CheckedEntry is a log entry that is checked to be written by Check.
+// It is used to skip the expensive construction of the fields when the level is disabled.
+type CheckedEntry struct {
+	logger  *Logger // logger is nil if it is checked by the global Check.
+	level   zapcore.Level
+	message string
+}
+
+// Enabled reports whether the log of level is written to the console or the file.
+func Enabled(level zapcore.Level) bool {
+	checkInit()
+	return levelEnabled(withoutLevelOverride(zapLogger.Core()), level)
+}
+
+// Check returns a CheckedEntry if the log of level is written, otherwise returns nil.
+// e.g.
+//
+//	if ce := zl.Check(zl.DebugLevel, "USER"); ce != nil {
+//		ce.Write(zap.Any("user", expensiveUser()))
+//	}
+func Check(level zapcore.Level, message string) *CheckedEntry {
+	level = overrideLevel(message, level)
+	if !Enabled(level) {
+		return nil
+	}
+	return &CheckedEntry{level: level, message: message}
+}
+
+// Enabled reports whether the log of level is written to the console or the file.
+func (l *Logger) Enabled(level zapcore.Level) bool {
+	return levelEnabled(withoutLevelOverride(l.zapLogger.Core()), level)
+}
+
+// Check returns a CheckedEntry if the log of level is written, otherwise returns nil.
+// The default fields of the Logger are added when the entry is written.
+func (l *Logger) Check(level zapcore.Level, message string) *CheckedEntry {
+	level = overrideLevel(message, level)
+	if !l.Enabled(level) {
+		return nil
+	}
+	return &CheckedEntry{logger: l, level: level, message: message}
+}
+
+// Write writes the entry with the fields. It does nothing if ce is nil.
+func (ce *CheckedEntry) Write(fields ...zap.Field) {
+	if ce == nil {
+		return
+	}
+	if ce.logger == nil {
+		logger(ce.message, ce.level, fields).Log(ce.level, ce.message, fields...)
+		return
+	}
+	fs := ce.logger.appendFields(fields)
+	ce.logger.logger(ce.message, ce.level, fs.fields).Log(ce.level, ce.message, fs.fields...)
+	fs.free()
+}
+
+// enabled reports whether the log of level is written to the pretty or the zap logger.
+// The Logger methods return before building the fields if it is false.
+func (l *Logger) enabled(level zapcore.Level) bool {
+	return levelEnabled(l.zapLogger.Core(), level)
+}
+
+func levelEnabled(core zapcore.Core, level zapcore.Level) bool {
+	if outputType == PrettyOutput && level >= minSeverityLevel() {
+		return true
+	}
+	return core.Enabled(level)
+}
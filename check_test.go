@@ -0,0 +1,57 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLogger_enabled(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetOutput(PrettyOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetLevel(WarnLevel)
+	Init()
+	l := New()
+	assert.False(t, l.enabled(InfoLevel))
+	assert.True(t, l.enabled(WarnLevel))
+
+	SetLevel(DebugLevel) // The pretty logger follows the level changed after Init.
+	assert.True(t, New().enabled(DebugLevel))
+	ResetGlobalLoggerSettings()
+}
+
+func TestCheck(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	Init()
+
+	assert.False(t, Enabled(DebugLevel))
+	assert.True(t, Enabled(InfoLevel))
+	assert.Nil(t, Check(DebugLevel, "DEBUG"))
+	Check(DebugLevel, "DEBUG").Write(zap.String("key", "value")) // nil is safe.
+	if ce := Check(InfoLevel, "GLOBAL"); ce != nil {
+		ce.Write(zap.String("key", "value"))
+	}
+	l := New(zap.String("default", "d"))
+	assert.Nil(t, l.Check(DebugLevel, "DEBUG"))
+	if ce := l.Check(WarnLevel, "LOGGER"); ce != nil {
+		ce.Write(zap.String("key", "value"))
+	}
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"INFO","function":"github.com/nkmr-jp/zl.TestCheck","message":"GLOBAL","key":"value"}`+"\n"+
+			`{"severity":"WARN","function":"github.com/nkmr-jp/zl.TestCheck","message":"LOGGER","key":"value","default":"d"}`+"\n",
+		string(b),
+	)
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,22 @@
+package zl
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var clock zapcore.Clock = zapcore.DefaultClock
+
+// SetClock set the clock for the timestamps of the json logs and the time column of PrettyOutput.
+// It is useful to freeze or step the time in the tests and the golden file comparisons.
+// Default is zapcore.DefaultClock.
+func SetClock(c zapcore.Clock) {
+	clock = c
+}
+
+// Now returns the current time of the clock set by SetClock.
+// It is used for the elapsed times of the subpackages such as zlhttp so that they follow SetClock.
+func Now() time.Time {
+	return clock.Now()
+}
@@ -0,0 +1,62 @@
+package zl
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedClock is a zapcore.Clock that returns the time set to t.
+type fixedClock struct {
+	t time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.t
+}
+
+func (c *fixedClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func TestNow(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	now := time.Date(2023, 9, 9, 15, 53, 17, 0, time.UTC)
+	SetClock(&fixedClock{t: now})
+	assert.Equal(t, now, Now())
+}
+
+func TestSetClock(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	c := &fixedClock{t: time.Date(2023, 9, 9, 15, 53, 17, 0, time.UTC)}
+	SetClock(c)
+	SetRotateFileName(file)
+	SetOmitKeys(CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetColor(ColorNever)
+	SetPrettyTimeMode(TimeElapsed)
+	Init()
+	var buf bytes.Buffer
+	pretty.Logger = log.New(&buf, "", log.Ltime)
+
+	Info("FIRST")
+	c.t = c.t.Add(3120 * time.Millisecond)
+	Info("SECOND")
+	Sync()
+
+	assert.Equal(t, "+00:00.000 INFO FIRST\n+00:03.120 INFO SECOND\n", buf.String())
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"INFO","timestamp":"2023-09-09T15:53:17Z","message":"FIRST"}`+"\n"+
+			`{"severity":"INFO","timestamp":"2023-09-09T15:53:20.12Z","message":"SECOND"}`+"\n",
+		string(b),
+	)
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,140 @@
+// Command zlv displays the json log files written by zl in the same format as PrettyOutput.
+//
+// Usage:
+//
+//	zlv [flags] [file ...]
+//
+// The logs are read from stdin if no file is given.
+// e.g.
+//
+//	zlv -f ./log/app.jsonl --level warn --since 10m
+//	zlv -f ./log/app.jsonl --follow --console console,user_id
+//	kubectl logs my-pod | zlv --fields kv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+)
+
+// followInterval is the interval to check the appended logs with --follow.
+const followInterval = 200 * time.Millisecond
+
+type fileFlags []string
+
+func (f *fileFlags) String() string { return strings.Join(*f, ",") }
+
+func (f *fileFlags) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "zlv:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	var files fileFlags
+	fs := flag.NewFlagSet("zlv", flag.ContinueOnError)
+	fs.Var(&files, "f", "json log file to display. It can be set multiple times.")
+	level := fs.String("level", "debug", "minimum level to display (debug, info, warn, error, fatal)")
+	since := fs.String("since", "", "display the logs since the duration ago (e.g. 10m) or the RFC3339 time")
+	follow := fs.Bool("follow", false, "wait for the logs appended to the last file like `tail -f`")
+	fields := fs.String("fields", "none", "display mode of the fields (none, kv, json)")
+	console := fs.String("console", "console", "comma separated keys of the console fields")
+	noColor := fs.Bool("no-color", false, "disable the colors")
+	stacktrace := fs.Bool("stacktrace", false, "display the error and the stacktrace under the line")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files = append(files, fs.Args()...)
+
+	config := zl.ViewConfig{NoColor: *noColor}
+	if err := config.Level.UnmarshalText([]byte(*level)); err != nil {
+		return err
+	}
+	var err error
+	if config.Since, err = parseSince(*since, time.Now()); err != nil {
+		return err
+	}
+	switch *fields {
+	case "none":
+		zl.SetPrettyFields(zl.PrettyFieldsNone)
+	case "kv":
+		zl.SetPrettyFields(zl.PrettyFieldsKeyValue)
+	case "json":
+		zl.SetPrettyFields(zl.PrettyFieldsJSON)
+	default:
+		return fmt.Errorf("unknown fields mode: %s", *fields)
+	}
+	if *console != "" {
+		zl.SetConsoleFields(strings.Split(*console, ",")...)
+	}
+	if *noColor {
+		zl.SetColor(zl.ColorNever)
+	}
+	zl.SetPrettyStacktrace(*stacktrace)
+
+	if len(files) == 0 {
+		return zl.View(stdout, stdin, config)
+	}
+	for i, name := range files {
+		if err := viewFile(stdout, name, config, *follow && i == len(files)-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func viewFile(w io.Writer, name string, config zl.ViewConfig, follow bool) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if follow {
+		r = &followReader{r: f}
+	}
+	return zl.View(w, r, config)
+}
+
+// parseSince returns the time of the duration before now, or the RFC3339 time. Empty returns the zero time.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since: %s", since)
+	}
+	return t, nil
+}
+
+// followReader is an io.Reader that waits for the data appended to r instead of returning io.EOF.
+type followReader struct {
+	r io.Reader
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 || !errors.Is(err, io.EOF) {
+			return n, err
+		}
+		time.Sleep(followInterval)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_run(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	logs := `{"severity":"INFO","timestamp":"2026-10-14T10:00:00Z","message":"INFO_MESSAGE"}
+{"severity":"WARN","timestamp":"2026-10-14T10:00:01Z","message":"WARN_MESSAGE","console":"detail"}
+`
+	assert.NoError(t, os.WriteFile(file, []byte(logs), 0o600))
+
+	var out bytes.Buffer
+	err := run([]string{"-f", file, "--level", "warn", "--no-color"}, nil, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026/10/14 10:00:01 WARN WARN_MESSAGE detail\n", out.String())
+
+	out.Reset()
+	err = run([]string{"--no-color", "--console", ""}, strings.NewReader(logs), &out)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+
+	assert.Error(t, run([]string{"--level", "unknown"}, nil, &out))
+	assert.Error(t, run([]string{"--fields", "unknown"}, nil, &out))
+	assert.Error(t, run([]string{"-f", filepath.Join(t.TempDir(), "none.jsonl")}, nil, &out))
+}
+
+func Test_parseSince(t *testing.T) {
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"10m", now.Add(-10 * time.Minute), false},
+		{"2026-10-14T09:00:00Z", time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			got, err := parseSince(tt.since, now)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.True(t, tt.want.Equal(got))
+		})
+	}
+}
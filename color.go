@@ -0,0 +1,91 @@
+package zl
+
+import (
+	"io"
+	"os"
+	"regexp"
+)
+
+// ColorMode determines whether the console log is colored when PrettyOutput is used.
+type ColorMode int
+
+const (
+	// ColorAuto colors the console log only when it is a terminal
+	// and neither the NO_COLOR environment variable nor TERM=dumb is set.
+	// See: https://no-color.org/
+	// It is Default setting.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways always colors the console log.
+	ColorAlways
+
+	// ColorNever never colors the console log.
+	ColorNever
+)
+
+var colorModeStrings = [3]string{
+	"Auto",
+	"Always",
+	"Never",
+}
+
+var (
+	colorMode ColorMode
+
+	// ansiRegexp matches SGR sequences and OSC 8 hyperlinks.
+	ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b]8;[^\x1b]*\x1b\\\\")
+)
+
+// String is return ColorMode type string.
+func (c ColorMode) String() string {
+	return colorModeStrings[c]
+}
+
+// SetColor is set whether the console log is colored when PrettyOutput is used.
+// option can use (ColorAuto, ColorAlways, ColorNever).
+func SetColor(option ColorMode) {
+	colorMode = option
+}
+
+// colorEnabled reports whether the output to w should be colored.
+// In ColorAuto mode, writers other than *os.File (ex. bytes.Buffer) are colored
+// because it cannot be determined whether they are terminals.
+// On Windows, the console is colored only when virtual terminal processing can be enabled.
+func colorEnabled(w io.Writer) bool {
+	f, isFile := w.(*os.File)
+	switch colorMode {
+	case ColorAlways:
+		if isFile {
+			enableVirtualTerminal(f)
+		}
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if _, ok := w.(testLogWriter); ok {
+		return false // The output of InitForTest is not a terminal.
+	}
+	if !isFile {
+		return true
+	}
+	return isTerminal(f) && enableVirtualTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stripColor removes the ANSI escape sequences from s.
+func stripColor(s string) string {
+	return ansiRegexp.ReplaceAllString(s, "")
+}
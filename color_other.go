@@ -0,0 +1,10 @@
+//go:build !windows
+
+package zl
+
+import "os"
+
+// enableVirtualTerminal does nothing because terminals other than the Windows console support ANSI escape sequences.
+func enableVirtualTerminal(_ *os.File) bool {
+	return true
+}
@@ -0,0 +1,71 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func Test_colorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ColorMode
+		env      map[string]string
+		out      interface{ Write([]byte) (int, error) }
+		expected bool
+	}{
+		{name: "auto buffer", mode: ColorAuto, out: &bytes.Buffer{}, expected: true},
+		{name: "auto NO_COLOR", mode: ColorAuto, env: map[string]string{"NO_COLOR": "1"}, out: &bytes.Buffer{}},
+		{name: "auto TERM=dumb", mode: ColorAuto, env: map[string]string{"TERM": "dumb"}, out: &bytes.Buffer{}},
+		{name: "always", mode: ColorAlways, env: map[string]string{"NO_COLOR": "1"}, out: &bytes.Buffer{}, expected: true},
+		{name: "never", mode: ColorNever, out: &bytes.Buffer{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("TERM", "xterm-256color")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			SetColor(tt.mode)
+			assert.Equal(t, tt.expected, colorEnabled(tt.out))
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+	f, err := os.CreateTemp(t.TempDir(), "terminal")
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.False(t, isTerminal(f))
+	assert.False(t, colorEnabled(f))
+}
+
+func TestSetColor(t *testing.T) {
+	SetColor(ColorNever)
+	omitKeys = []Key{TimeKey}
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.logWithError("READ_FILE_ERROR", ErrorLevel, os.ErrNotExist, []zap.Field{Console("console")})
+	assert.Contains(t, buf.String(), "ERROR READ_FILE_ERROR file does not exist console\n")
+	ResetGlobalLoggerSettings()
+}
+
+func Test_stripColor(t *testing.T) {
+	assert.Equal(t, "INFO link", stripColor("\x1b[94mINFO\x1b[0m \x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"))
+}
+
+func Test_enableVirtualTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("depends on the console")
+	}
+	assert.True(t, enableVirtualTerminal(os.Stderr))
+}
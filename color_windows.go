@@ -0,0 +1,30 @@
+//go:build windows
+
+package zl
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is ENABLE_VIRTUAL_TERMINAL_PROCESSING console mode flag.
+// See: https://learn.microsoft.com/en-us/windows/console/setconsolemode
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableVirtualTerminal enables processing of ANSI escape sequences on the Windows console (cmd.exe, PowerShell).
+// It returns false when f is not a console or the console does not support it (older than Windows 10),
+// in which case the pretty logger falls back to plain text.
+func enableVirtualTerminal(f *os.File) bool {
+	var mode uint32
+	h := syscall.Handle(f.Fd())
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
@@ -0,0 +1,141 @@
+package zl
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the compression format of the rotated log files.
+type Compression int
+
+const (
+	// CompressionGzip compresses the rotated log files using gzip.
+	// It is Default setting.
+	CompressionGzip Compression = iota
+
+	// CompressionZstd compresses the rotated log files using zstd.
+	// It gives a better ratio and uses less CPU than gzip for the JSON logs.
+	CompressionZstd
+)
+
+var compressionStrings = [2]string{
+	"Gzip",
+	"Zstd",
+}
+
+// backupTimeFormat is the timestamp format of the backup file names of lumberjack.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var (
+	compression      Compression
+	compressionLevel int
+)
+
+// String is return Compression type string.
+func (c Compression) String() string {
+	return compressionStrings[c]
+}
+
+func (c Compression) extension() string {
+	if c == CompressionZstd {
+		return ".zst"
+	}
+	return ".gz"
+}
+
+// SetRotateCompression set the compression format of the rotated log files.
+// It is used when SetRotateCompress is enabled.
+// option can use (CompressionGzip, CompressionZstd).
+func SetRotateCompression(option Compression) {
+	compression = option
+}
+
+// SetRotateCompressionLevel set the compression level of the rotated log files.
+// It is the level of compress/gzip (1-9) for CompressionGzip, and the level of zstd (1-22) for CompressionZstd.
+// 0 (default) means the default level of each format.
+func SetRotateCompressionLevel(level int) {
+	compressionLevel = level
+}
+
+// compressFile compresses path to path with the extension of codec and removes path.
+func compressFile(path string, codec Compression, level int) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dstPath := path + codec.extension()
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	w, err := newCompressWriter(dst, codec, level)
+	if err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+	_ = src.Close()
+	return dstPath, os.Remove(path)
+}
+
+func newCompressWriter(w io.Writer, codec Compression, level int) (io.WriteCloser, error) {
+	if codec == CompressionZstd {
+		var opts []zstd.EOption
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		return zstd.NewWriter(w, opts...)
+	}
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// removeOldBackups removes the compressed backups that lumberjack does not know,
+// in the same way as MaxBackups and MaxAge of lumberjack.
+func (r *rotator) removeOldBackups(compressedExt string) {
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(r.Filename, ext) + "-"
+	files, err := filepath.Glob(prefix + "*" + ext + compressedExt)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	// The newer files come first because the file names contain the timestamps.
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+
+	cutoff := rotateNow().Add(-time.Duration(r.MaxAge) * 24 * time.Hour)
+	for i, file := range files {
+		ts := strings.TrimSuffix(strings.TrimPrefix(file, prefix), ext+compressedExt)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, r.location())
+		if err != nil {
+			continue
+		}
+		if (r.MaxBackups > 0 && i >= r.MaxBackups) || (r.MaxAge > 0 && t.Before(cutoff)) {
+			if err := os.Remove(file); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package zl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_compressFile(t *testing.T) {
+	tests := []struct {
+		codec  Compression
+		level  int
+		reader func(r io.Reader) (io.Reader, error)
+	}{
+		{CompressionGzip, 0, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{CompressionGzip, 9, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{CompressionZstd, 0, func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+		{CompressionZstd, 19, func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", tt.codec, tt.level), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.jsonl")
+			assert.NoError(t, os.WriteFile(path, []byte(`{"message":"test"}`), 0o600))
+
+			compressed, err := compressFile(path, tt.codec, tt.level)
+			assert.NoError(t, err)
+			assert.Equal(t, path+tt.codec.extension(), compressed)
+			assert.NoFileExists(t, path)
+
+			f, err := os.Open(compressed)
+			assert.NoError(t, err)
+			defer f.Close()
+			r, err := tt.reader(f)
+			assert.NoError(t, err)
+			b, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"message":"test"}`, string(b))
+		})
+	}
+}
+
+func TestSetRotateCompression(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetRotateCompress(true)
+	SetRotateCompression(CompressionZstd)
+	SetRotateCompressionLevel(3)
+	SetRotateMaxBackups(2)
+
+	r := newRotator()
+	assert.True(t, r.managed)
+	assert.False(t, r.Compress)
+	for i := 0; i < 3; i++ {
+		_, err := r.Write([]byte("test\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, r.rotate())
+		r.hooks.Wait()
+		time.Sleep(2 * time.Millisecond) // The backup file name is the time in milliseconds.
+	}
+	assert.NoError(t, r.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.jsonl.zst"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_rotator_removeOldBackups(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	now := time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC)
+	rotateNow = func() time.Time { return now }
+	for _, name := range []string{
+		"app-2023-09-08T00-00-00.000.jsonl.zst",
+		"app-2023-09-01T00-00-00.000.jsonl.zst", // older than MaxAge
+		"app-2023-09-09T00-00-00.000.jsonl",     // not compressed
+		"other.jsonl.zst",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	newRotator().removeOldBackups(".zst")
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "app-2023-09-08T00-00-00.000.jsonl.zst"),
+		filepath.Join(dir, "app-2023-09-09T00-00-00.000.jsonl"),
+		filepath.Join(dir, "other.jsonl.zst"),
+	}, files)
+	rotateNow = time.Now
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,75 @@
+package zl
+
+import (
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ConsoleEncoding is the encoding of the console log of ConsoleAndFileOutput and ConsoleOutput.
+type ConsoleEncoding int
+
+const (
+	// ConsoleEncodingJSON writes the same json log as the file to the console.
+	// It is Default setting.
+	ConsoleEncodingJSON ConsoleEncoding = iota
+
+	// ConsoleEncodingText writes the tab separated log with the colored level to the console
+	// by the console encoder of zap, like zap.NewDevelopmentConfig. The file is still written in json.
+	// The level is colored in the same way as SetColor.
+	ConsoleEncodingText
+)
+
+var consoleEncodingStrings = [2]string{
+	"JSON",
+	"Text",
+}
+
+var consoleEncoding ConsoleEncoding
+
+// String is return ConsoleEncoding type string.
+func (c ConsoleEncoding) String() string {
+	return consoleEncodingStrings[c]
+}
+
+// SetConsoleEncoding set the encoding of the console log of ConsoleAndFileOutput and ConsoleOutput.
+// option can use (ConsoleEncodingJSON, ConsoleEncodingText).
+func SetConsoleEncoding(option ConsoleEncoding) {
+	consoleEncoding = option
+}
+
+// consoleWriteSyncer is the console output that is not synced. See: consoleSyncer
+type consoleWriteSyncer struct {
+	io.Writer
+}
+
+func (consoleWriteSyncer) Sync() error {
+	return nil
+}
+
+// splitConsoleSyncers returns the console syncers separately from the others
+// if they are encoded by ConsoleEncodingText.
+func splitConsoleSyncers(syncers []zapcore.WriteSyncer) (console, others []zapcore.WriteSyncer) {
+	if consoleEncoding != ConsoleEncodingText {
+		return nil, syncers
+	}
+	for _, ws := range syncers {
+		if _, ok := ws.(consoleWriteSyncer); ok {
+			console = append(console, ws)
+		} else {
+			others = append(others, ws)
+		}
+	}
+	return console, others
+}
+
+// newConsoleEncoder returns the console encoder of ConsoleEncodingText.
+func newConsoleEncoder(enc *zapcore.EncoderConfig) zapcore.Encoder {
+	cfg := *enc
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	if colorEnabled(getConsoleOutput()) {
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(cfg)
+}
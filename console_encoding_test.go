@@ -0,0 +1,45 @@
+package zl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetConsoleEncoding(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	SetConsoleEncoding(ConsoleEncodingText)
+	SetColor(ColorNever)
+
+	var console, file bytes.Buffer
+	logger := newLoggerWithSyncers(newEncoderConfig(), []zapcore.WriteSyncer{
+		consoleWriteSyncer{&console}, zapcore.AddSync(&file),
+	})
+	logger.Info("USER_INFO", zap.String("user_name", "Alice"))
+
+	assert.Equal(t, "INFO\tUSER_INFO\t{\"user_name\": \"Alice\"}\n", console.String())
+	assert.Equal(t, `{"severity":"INFO","message":"USER_INFO","user_name":"Alice"}`+"\n", file.String())
+}
+
+func TestSetConsoleEncoding_json(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+
+	var console, file bytes.Buffer
+	logger := newLoggerWithSyncers(newEncoderConfig(), []zapcore.WriteSyncer{
+		consoleWriteSyncer{&console}, zapcore.AddSync(&file),
+	})
+	logger.Info("USER_INFO")
+	assert.Equal(t, file.String(), console.String())
+	assert.True(t, strings.HasPrefix(console.String(), "{"))
+}
+
+func TestConsoleEncoding_String(t *testing.T) {
+	assert.Equal(t, "JSON", ConsoleEncodingJSON.String())
+	assert.Equal(t, "Text", ConsoleEncodingText.String())
+}
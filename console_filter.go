@@ -0,0 +1,188 @@
+package zl
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ConsoleFilterEnv is the environment variable of the filter of the pretty console.
+// It is read by Init. See: SetConsoleFilter
+const ConsoleFilterEnv = "ZL_CONSOLE_FILTER"
+
+var (
+	consoleFilter []consoleFilterTerm
+
+	// consoleFilterOps is ordered so that the longer operators are matched first.
+	consoleFilterOps = []string{">=", "<=", "!=", "!~", "=", "~", ">", "<"}
+)
+
+type consoleFilterTerm struct {
+	key   string
+	op    string
+	value string
+	re    *regexp.Regexp
+	level zapcore.Level
+}
+
+// SetConsoleFilter is set the filter of the logs displayed in the console when PrettyOutput is used.
+// The filtered logs are still written to the log file.
+// The filter is the space separated terms and the logs matching all the terms are displayed. e.g.
+//
+//	zl.SetConsoleFilter("logger=db level>=warn msg~timeout")
+//
+// The term is `KEY OPERATOR VALUE`.
+// KEY can use `level`, `logger`, `msg` and the field keys.
+// OPERATOR can use `=`, `!=`, `~` (regular expression match) and `!~`.
+// `level` can also use `>=`, `>`, `<=` and `<`.
+//
+// It can also be set by the ZL_CONSOLE_FILTER environment variable without recompiling.
+// The environment variable takes precedence over SetConsoleFilter when PrettyOutput is used.
+// The invalid environment variable is ignored with an internal WARNING so that it does not stop the program.
+func SetConsoleFilter(filter string) {
+	terms, err := parseConsoleFilter(filter)
+	if err != nil {
+		log.Fatalf("%s is invalid console filter: %v", filter, err)
+	}
+	consoleFilter = terms
+}
+
+// setConsoleFilterFromEnv sets the filter of ConsoleFilterEnv if PrettyOutput is used.
+// It returns the error of the invalid filter to log it after the internal logger is created.
+func setConsoleFilterFromEnv() error {
+	filter := os.Getenv(ConsoleFilterEnv)
+	if filter == "" || outputType != PrettyOutput {
+		return nil
+	}
+	terms, err := parseConsoleFilter(filter)
+	if err != nil {
+		return fmt.Errorf("%s=%q is invalid console filter: %w", ConsoleFilterEnv, filter, err)
+	}
+	consoleFilter = terms
+	return nil
+}
+
+func parseConsoleFilter(filter string) ([]consoleFilterTerm, error) {
+	var terms []consoleFilterTerm
+	for _, str := range strings.Fields(filter) {
+		term, err := parseConsoleFilterTerm(str)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func parseConsoleFilterTerm(str string) (consoleFilterTerm, error) {
+	i := strings.IndexAny(str, "=!~<>")
+	if i <= 0 {
+		return consoleFilterTerm{}, fmt.Errorf("%s has no key or operator", str)
+	}
+	term := consoleFilterTerm{key: str[:i]}
+	for _, op := range consoleFilterOps {
+		if strings.HasPrefix(str[i:], op) {
+			term.op = op
+			break
+		}
+	}
+	if term.op == "" {
+		return consoleFilterTerm{}, fmt.Errorf("%s has invalid operator", str)
+	}
+	term.value = str[i+len(term.op):]
+
+	if term.key == "level" {
+		if term.op == "~" || term.op == "!~" {
+			return consoleFilterTerm{}, fmt.Errorf("%s: level can not use %s", str, term.op)
+		}
+		if err := term.level.UnmarshalText([]byte(term.value)); err != nil {
+			return consoleFilterTerm{}, fmt.Errorf("%s: %w", str, err)
+		}
+		return term, nil
+	}
+
+	switch term.op {
+	case "~", "!~":
+		re, err := regexp.Compile(term.value)
+		if err != nil {
+			return consoleFilterTerm{}, fmt.Errorf("%s: %w", str, err)
+		}
+		term.re = re
+	case "=", "!=":
+	default:
+		return consoleFilterTerm{}, fmt.Errorf("%s: only level can use %s", str, term.op)
+	}
+	return term, nil
+}
+
+// matchConsoleFilter reports whether the log is displayed in the console.
+func matchConsoleFilter(level zapcore.Level, name, msg string, fields []zap.Field) bool {
+	for i := range consoleFilter {
+		if !consoleFilter[i].match(level, name, msg, fields) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *consoleFilterTerm) match(level zapcore.Level, name, msg string, fields []zap.Field) bool {
+	switch t.key {
+	case "level":
+		return t.matchLevel(level)
+	case "logger":
+		return t.matchString(name, true)
+	case "msg":
+		return t.matchString(msg, true)
+	}
+	val, ok := fieldString(t.key, fields)
+	return t.matchString(val, ok)
+}
+
+func (t *consoleFilterTerm) matchLevel(level zapcore.Level) bool {
+	switch t.op {
+	case ">=":
+		return level >= t.level
+	case ">":
+		return level > t.level
+	case "<=":
+		return level <= t.level
+	case "<":
+		return level < t.level
+	case "!=":
+		return level != t.level
+	}
+	return level == t.level
+}
+
+// matchString matches val. ok is false if the field of key does not exist.
+func (t *consoleFilterTerm) matchString(val string, ok bool) bool {
+	switch t.op {
+	case "=":
+		return ok && val == t.value
+	case "!=":
+		return !ok || val != t.value
+	case "~":
+		return ok && t.re.MatchString(val)
+	case "!~":
+		return !ok || !t.re.MatchString(val)
+	}
+	return false
+}
+
+// fieldString returns the value of the field of key as a string.
+func fieldString(key string, fields []zap.Field) (string, bool) {
+	for i := range fields {
+		if fields[i].Key != key {
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(enc)
+		return fmt.Sprint(enc.Fields[key]), true
+	}
+	return "", false
+}
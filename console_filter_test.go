@@ -0,0 +1,103 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetConsoleFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{"", "DEBUG DB_QUERY\nWARN DB_TIMEOUT\nERROR HTTP_TIMEOUT\n"},
+		{"level>=warn", "WARN DB_TIMEOUT\nERROR HTTP_TIMEOUT\n"},
+		{"level<WARN", "DEBUG DB_QUERY\n"},
+		{"level=error", "ERROR HTTP_TIMEOUT\n"},
+		{"level!=error level>debug", "WARN DB_TIMEOUT\n"},
+		{"msg~TIMEOUT$", "WARN DB_TIMEOUT\nERROR HTTP_TIMEOUT\n"},
+		{"msg!~^DB_", "ERROR HTTP_TIMEOUT\n"},
+		{"logger=db level>=warn msg~TIMEOUT", "WARN DB_TIMEOUT\n"},
+		{"logger!=db", "ERROR HTTP_TIMEOUT\n"},
+		{"table=users", "DEBUG DB_QUERY\n"},
+		{"status=500", "ERROR HTTP_TIMEOUT\n"},
+		{"status!=500", "DEBUG DB_QUERY\nWARN DB_TIMEOUT\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			SetColor(ColorNever)
+			SetConsoleFilter(tt.filter)
+			severityLevel = DebugLevel
+
+			var buf bytes.Buffer
+			db := newPrettyLogger(&buf, os.Stderr)
+			db.name = "db"
+			db.Logger.SetFlags(0)
+			db.log("DB_QUERY", DebugLevel, []zap.Field{zap.String("table", "users")})
+			db.log("DB_TIMEOUT", WarnLevel, nil)
+			http := newPrettyLogger(&buf, os.Stderr)
+			http.Logger.SetFlags(0)
+			http.log("HTTP_TIMEOUT", ErrorLevel, []zap.Field{zap.Int("status", 500)})
+
+			assert.Equal(t, tt.expected, buf.String())
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
+
+func Test_parseConsoleFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{"level", "level has no key or operator"},
+		{"=info", "=info has no key or operator"},
+		{"level!info", "level!info has invalid operator"},
+		{"level~info", "level~info: level can not use ~"},
+		{"level>=verbose", `level>=verbose: unrecognized level: "verbose"`},
+		{"msg~(", "msg~(: error parsing regexp: missing closing ): `(`"},
+		{"msg>=a", "msg>=a: only level can use >="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := parseConsoleFilter(tt.filter)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func Test_setConsoleFilterFromEnv(t *testing.T) {
+	t.Setenv(ConsoleFilterEnv, "logger=db level>=warn")
+	SetConsoleFilter("msg~timeout")
+	setConsoleFilterFromEnv()
+	assert.Len(t, consoleFilter, 2)
+	assert.Equal(t, "logger", consoleFilter[0].key)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_setConsoleFilterFromEnv_invalid(t *testing.T) {
+	t.Setenv(ConsoleFilterEnv, "level~warn")
+	t.Run("file output", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		obs := NewTestObserver(t)
+		SetOutput(FileOutput)
+		SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+		Init()
+		assert.Nil(t, consoleFilter)
+		assert.Equal(t, 0, obs.FilterMessage("INVALID_CONSOLE_FILTER").Len(), "the filter is not read")
+	})
+	t.Run("pretty output", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		obs := NewTestObserver(t)
+		SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+		SetConsoleFilter("logger=db")
+		Init()
+		assert.Equal(t, "logger", consoleFilter[0].key, "the invalid filter is ignored")
+		assert.Equal(t, 1, obs.FilterMessage("INVALID_CONSOLE_FILTER").FilterLevel(WarnLevel).Len())
+	})
+}
@@ -0,0 +1,101 @@
+package zl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	au "github.com/logrusorgru/aurora/v4"
+)
+
+// cursorUp moves the cursor to the previous line.
+const cursorUp = "\x1b[1A"
+
+var (
+	// consoleMu serializes the writes of all the pretty loggers,
+	// so that the lines of the concurrent logs are not interleaved.
+	consoleMu sync.Mutex
+
+	prettyCollapse bool
+
+	// lastLineKey and lastLineCount are the last line written by writeConsoleCollapsed and the number of the repeats.
+	// They are protected by consoleMu.
+	lastLineKey   string
+	lastLineCount int
+)
+
+// SetPrettyCollapse set whether the same log repeated consecutively is collapsed into a single line
+// with the live-updating `x12` counter when PrettyOutput is used.
+// The logs are the same if the displayed lines are the same except for the time,
+// and the line shows the time of the last one. The file still has all the logs.
+// It is only applied when the console is colored because it rewrites the previous line. See: SetColor
+// The logs of multiple lines, such as the stacktraces, are not collapsed.
+func SetPrettyCollapse(val bool) {
+	prettyCollapse = val
+}
+
+// writeLine writes the line of a log. The repeats of key are collapsed if SetPrettyCollapse is enabled.
+func (l *prettyLogger) writeLine(str, key string) error {
+	if !prettyCollapse || l.noColor || strings.Count(str, "\n") > 1 {
+		return l.write(str)
+	}
+	return writeConsoleCollapsed(l.Logger.Writer(), str, key)
+}
+
+// writeConsoleCollapsed writes str in the same way as writeConsole,
+// or rewrites the previous line with the counter if key is the same as the previous one.
+func writeConsoleCollapsed(w io.Writer, str, key string) error {
+	b := bufferPool.Get()
+	defer b.Free()
+
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if progressLine != "" {
+		b.AppendString(clearLine)
+	}
+	if key == lastLineKey {
+		lastLineCount++
+		b.AppendString(cursorUp + clearLine)
+		b.AppendString(strings.TrimSuffix(str, "\n"))
+		b.AppendString(au.Colorize(fmt.Sprintf(" x%d", lastLineCount), theme.Fields).String())
+		b.AppendByte('\n')
+	} else {
+		lastLineKey, lastLineCount = key, 1
+		b.AppendString(str)
+	}
+	b.AppendString(progressLine)
+	return writeAll(w, b.Bytes())
+}
+
+// writeConsole writes str above the progress line and displays the progress line again.
+// The line is built in a pooled buffer and written by a single Write while consoleMu is locked.
+func writeConsole(w io.Writer, str string) error {
+	b := bufferPool.Get()
+	defer b.Free()
+
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	lastLineKey = ""
+	if progressLine != "" {
+		b.AppendString(clearLine)
+	}
+	b.AppendString(str)
+	b.AppendString(progressLine)
+	return writeAll(w, b.Bytes())
+}
+
+// writeAll writes p to w until all of it is written. It is needed for the writers that write partially.
+func writeAll(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		p = p[n:]
+	}
+	return nil
+}
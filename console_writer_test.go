@@ -0,0 +1,97 @@
+package zl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// shortWriter writes at most 7 bytes per Write. It is not safe for concurrent use.
+type shortWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > 7 {
+		p = p[:7]
+	}
+	return w.buf.Write(p)
+}
+
+func Test_prettyLogger_concurrent(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	w := &shortWriter{}
+	l := newPrettyLogger(w, io.Discard)
+	l.Logger.SetFlags(0)
+	l.noColor = true
+
+	const goroutines, logs = 100, 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < logs; j++ {
+				l.log(fmt.Sprintf("MESSAGE_%d_%d", i, j), InfoLevel, []zap.Field{zap.Int("i", i)})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\n"), "\n")
+	assert.Len(t, lines, goroutines*logs)
+	for _, line := range lines {
+		assert.Regexp(t, `^INFO MESSAGE_\d+_\d+$`, line)
+	}
+	ResetGlobalLoggerSettings()
+}
+
+func Test_writeAll(t *testing.T) {
+	w := &shortWriter{}
+	assert.NoError(t, writeAll(w, []byte("longer than 7 bytes")))
+	assert.Equal(t, "longer than 7 bytes", w.buf.String())
+}
+
+func BenchmarkPrettyLogger_parallel(b *testing.B) {
+	ResetGlobalLoggerSettings()
+	l := newPrettyLogger(io.Discard, io.Discard)
+	b.Cleanup(ResetGlobalLoggerSettings)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.log("BENCHMARK", InfoLevel, []zap.Field{zap.Int("i", 1)})
+		}
+	})
+}
+
+func TestSetPrettyCollapse(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetColor(ColorAlways)
+	SetPrettyCollapse(true)
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, io.Discard)
+	l.Logger.SetFlags(0)
+
+	l.log("RETRY", WarnLevel, nil)
+	l.log("RETRY", WarnLevel, nil)
+	l.log("RETRY", WarnLevel, nil)
+	l.log("RETRY", ErrorLevel, nil)
+	l.log("DONE", InfoLevel, nil)
+
+	up := cursorUp + clearLine
+	assert.Equal(t, "WARN RETRY\n"+up+"WARN RETRY x2\n"+up+"WARN RETRY x3\nERROR RETRY\nINFO DONE\n", stripColor(buf.String()))
+
+	buf.Reset()
+	l.noColor = true
+	l.log("DONE", InfoLevel, nil)
+	l.log("DONE", InfoLevel, nil)
+	assert.Equal(t, "INFO DONE\nINFO DONE\n", buf.String())
+}
@@ -0,0 +1,73 @@
+package zl
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+)
+
+var (
+	// cgroupFile and mountinfoFile are variables so that tests can replace them.
+	cgroupFile     = "/proc/self/cgroup"
+	mountinfoFile  = "/proc/self/mountinfo"
+	dockerEnvFile  = "/.dockerenv"
+	containerImage string
+
+	// containerIDRegexp matches the 64 hex characters ID used by Docker, containerd and CRI-O.
+	// e.g. `/docker/<id>`, `/system.slice/docker-<id>.scope`, `/kubepods/.../cri-containerd-<id>.scope`
+	containerIDRegexp = regexp.MustCompile(`(?:^|[/\-:])([0-9a-f]{64})(?:\.scope)?(?:/|$)`)
+	// mountinfoIDRegexp matches the container ID in the hostname or resolv.conf mount of Docker (cgroup v2).
+	mountinfoIDRegexp = regexp.MustCompile(`/(?:containers|sandboxes)/([0-9a-f]{64})/`)
+	// shortIDRegexp matches the short container ID that Docker sets as the default hostname.
+	shortIDRegexp = regexp.MustCompile(`^[0-9a-f]{12}$`)
+)
+
+// SetContainerImage set the image name of the container the application is running in.
+// It is used in the log output ContainerImageKey field.
+// If it is not set, the CONTAINER_IMAGE environment variable is used.
+func SetContainerImage(image string) {
+	containerImage = image
+}
+
+// getContainerID returns the ID of the container the process is running in.
+// It returns an empty string when the process is not running in a container.
+func getContainerID() string {
+	if id := os.Getenv("CONTAINER_ID"); id != "" {
+		return id
+	}
+	if id := scanContainerID(cgroupFile, containerIDRegexp); id != "" {
+		return id
+	}
+	if id := scanContainerID(mountinfoFile, mountinfoIDRegexp); id != "" {
+		return id
+	}
+	if _, err := os.Stat(dockerEnvFile); err == nil {
+		if host := getHost(); host != nil && shortIDRegexp.MatchString(*host) {
+			return *host
+		}
+	}
+	return ""
+}
+
+func getContainerImage() string {
+	if containerImage != "" {
+		return containerImage
+	}
+	return os.Getenv("CONTAINER_IMAGE")
+}
+
+func scanContainerID(path string, re *regexp.Regexp) string {
+	fp, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer fp.Close()
+
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		if m := re.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
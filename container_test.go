@@ -0,0 +1,90 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getContainerID(t *testing.T) {
+	defer func(c, m, d string) {
+		cgroupFile, mountinfoFile, dockerEnvFile = c, m, d
+	}(cgroupFile, mountinfoFile, dockerEnvFile)
+
+	id := "3f4b1c0f7d9a2e5b8c6d1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d"
+	tests := []struct {
+		name      string
+		cgroup    string
+		mountinfo string
+		expected  string
+	}{
+		{
+			name:     "docker cgroup v1",
+			cgroup:   "12:memory:/docker/" + id + "\n1:name=systemd:/docker/" + id,
+			expected: id,
+		},
+		{
+			name:     "docker cgroup v2 systemd",
+			cgroup:   "0::/system.slice/docker-" + id + ".scope",
+			expected: id,
+		},
+		{
+			name:     "containerd on kubernetes",
+			cgroup:   "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope",
+			expected: id,
+		},
+		{
+			name:      "docker cgroup v2 namespace",
+			cgroup:    "0::/",
+			mountinfo: "632 615 254:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw",
+			expected:  id,
+		},
+		{
+			name:     "not in container",
+			cgroup:   "0::/user.slice/user-1000.slice/session-1.scope",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cgroupFile = filepath.Join(dir, "cgroup")
+			mountinfoFile = filepath.Join(dir, "mountinfo")
+			dockerEnvFile = filepath.Join(dir, ".dockerenv")
+			assert.NoError(t, os.WriteFile(cgroupFile, []byte(tt.cgroup), 0o600))
+			assert.NoError(t, os.WriteFile(mountinfoFile, []byte(tt.mountinfo), 0o600))
+
+			assert.Equal(t, tt.expected, getContainerID())
+		})
+	}
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("CONTAINER_ID", "abc")
+		assert.Equal(t, "abc", getContainerID())
+	})
+}
+
+func Test_getContainerImage(t *testing.T) {
+	t.Setenv("CONTAINER_IMAGE", "example/app:v1")
+	assert.Equal(t, "example/app:v1", getContainerImage())
+
+	SetContainerImage("example/app:v2")
+	assert.Equal(t, "example/app:v2", getContainerImage())
+	ResetGlobalLoggerSettings()
+}
+
+func Test_getAdditionalFields_container(t *testing.T) {
+	t.Setenv("CONTAINER_ID", "abc")
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	SetEnableKeys(ContainerIDKey, ContainerImageKey)
+	SetContainerImage("example/app:v1")
+
+	fields := getAdditionalFields()
+	assert.Len(t, fields, 2)
+	assert.Equal(t, string(ContainerIDKey), fields[0].Key)
+	assert.Equal(t, "abc", fields[0].String)
+	assert.Equal(t, string(ContainerImageKey), fields[1].Key)
+	assert.Equal(t, "example/app:v1", fields[1].String)
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,159 @@
+package zl
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RepeatCountKey is the field key of the number of the suppressed duplicate logs. See: SetDedup
+const RepeatCountKey = "repeat_count"
+
+var (
+	dedupWindow time.Duration
+	dedupKeys   []string
+
+	// dedup is shared by all the loggers because New creates a new core for each logger.
+	dedup = &dedupState{groups: make(map[string]*dedupGroup)}
+)
+
+// SetDedup set the window to suppress the duplicate logs.
+// The logs of the same level, logger name, message and values of fingerprintKeys fields are duplicates.
+// The first log is written immediately, and the duplicates within window after it are suppressed.
+// When window has passed, the last duplicate is written once with the RepeatCountKey field of the number of them.
+// The suppressed logs are also written by Sync.
+// It applies to the json logs of the console and the file. e.g. SetDedup(time.Minute, "user_id")
+// 0 (default) means the duplicate logs are not suppressed.
+func SetDedup(window time.Duration, fingerprintKeys ...string) {
+	dedupWindow = window
+	dedupKeys = fingerprintKeys
+}
+
+type dedupState struct {
+	mu     sync.Mutex
+	groups map[string]*dedupGroup
+}
+
+// dedupGroup is the duplicate logs of the same fingerprint in a window.
+type dedupGroup struct {
+	timer  *time.Timer
+	count  int
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zap.Field
+}
+
+// suppress reports whether the log is a duplicate.
+// It copies fields of the duplicate because the caller may reuse them.
+func (d *dedupState) suppress(core zapcore.Core, ent zapcore.Entry, fields []zap.Field) bool {
+	key := dedupFingerprint(ent, fields)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if g, ok := d.groups[key]; ok {
+		g.count++
+		g.core = core
+		g.entry = ent
+		g.fields = append(g.fields[:0], fields...)
+		return true
+	}
+	d.groups[key] = &dedupGroup{
+		timer: time.AfterFunc(dedupWindow, func() { d.expire(key) }),
+	}
+	return false
+}
+
+func (d *dedupState) expire(key string) {
+	d.mu.Lock()
+	g, ok := d.groups[key]
+	delete(d.groups, key)
+	d.mu.Unlock()
+	if ok {
+		g.write()
+	}
+}
+
+// flush writes the suppressed logs of all the groups and starts new windows.
+func (d *dedupState) flush() {
+	d.mu.Lock()
+	groups := d.groups
+	d.groups = make(map[string]*dedupGroup)
+	d.mu.Unlock()
+	for _, g := range groups {
+		g.timer.Stop()
+		g.write()
+	}
+}
+
+func (d *dedupState) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, g := range d.groups {
+		g.timer.Stop()
+	}
+	d.groups = make(map[string]*dedupGroup)
+}
+
+func (g *dedupGroup) write() {
+	if g.count == 0 {
+		return
+	}
+	fields := append(g.fields, zap.Int(RepeatCountKey, g.count))
+	if err := g.core.Write(g.entry, fields); err != nil {
+		log.Println(err)
+	}
+}
+
+func dedupFingerprint(ent zapcore.Entry, fields []zap.Field) string {
+	var b strings.Builder
+	b.WriteString(ent.Level.String())
+	b.WriteByte(0)
+	b.WriteString(ent.LoggerName)
+	b.WriteByte(0)
+	b.WriteString(ent.Message)
+	for _, key := range dedupKeys {
+		val, _ := fieldString(key, fields)
+		b.WriteByte(0)
+		b.WriteString(val)
+	}
+	return b.String()
+}
+
+// dedupCore is a zapcore.Core that suppresses the duplicate logs.
+type dedupCore struct {
+	zapcore.Core
+}
+
+func newDedupCore(core zapcore.Core) zapcore.Core {
+	if dedupWindow <= 0 {
+		return core
+	}
+	return &dedupCore{Core: core}
+}
+
+func (c *dedupCore) With(fields []zap.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields)}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if dedup.suppress(c.Core, ent, fields) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync writes the suppressed logs before syncing.
+func (c *dedupCore) Sync() error {
+	dedup.flush()
+	return c.Core.Sync()
+}
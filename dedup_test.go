@@ -0,0 +1,66 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetDedup(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetDedup(time.Hour, "user_id")
+	Init()
+
+	for i := 0; i < 3; i++ {
+		Warn("STORM", zap.Int("user_id", 1), zap.Int("i", i))
+	}
+	Warn("STORM", zap.Int("user_id", 2))
+	Error("STORM", zap.Int("user_id", 1))
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"WARN","message":"STORM","user_id":1,"i":0}`+"\n"+
+			`{"severity":"WARN","message":"STORM","user_id":2}`+"\n"+
+			`{"severity":"ERROR","message":"STORM","user_id":1}`+"\n"+
+			`{"severity":"WARN","message":"STORM","user_id":1,"i":2,"repeat_count":2}`+"\n",
+		string(b),
+	)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_dedupCore_window(t *testing.T) {
+	SetDedup(10 * time.Millisecond)
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newDedupCore(obs)
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "REPEAT"}
+	fields := []zap.Field{zap.String("key", "a")}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, core.Write(ent, fields))
+	}
+	fields[0] = zap.String("key", "b") // The suppressed fields are copied.
+	assert.Equal(t, 1, logs.Len())
+
+	assert.Eventually(t, func() bool { return logs.Len() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, map[string]interface{}{"key": "a", RepeatCountKey: int64(2)}, logs.All()[1].ContextMap())
+
+	assert.NoError(t, core.Write(ent, fields))
+	assert.Equal(t, 3, logs.Len(), "a new window is started after the window has passed")
+	ResetGlobalLoggerSettings()
+}
+
+func Test_newDedupCore_disabled(t *testing.T) {
+	core := zapcore.NewNopCore()
+	assert.Equal(t, core, newDedupCore(core))
+}
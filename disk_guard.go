@@ -0,0 +1,133 @@
+package zl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DiskGuardAction is the action of the disk guard when the free space of the log volume is low.
+type DiskGuardAction int
+
+const (
+	// DiskGuardConsoleOnly stops writing to the log file until the free space recovers.
+	// With FileOutput, the logs are written to the console instead.
+	// It is Default setting.
+	DiskGuardConsoleOnly DiskGuardAction = iota
+
+	// DiskGuardRemoveBackups removes the oldest rotated files until the free space recovers.
+	// If it is still low, it falls back to DiskGuardConsoleOnly.
+	DiskGuardRemoveBackups
+)
+
+var diskGuardActionStrings = [2]string{
+	"ConsoleOnly",
+	"RemoveBackups",
+}
+
+// diskGuardInterval is the interval to check the free space.
+const diskGuardInterval = 10 * time.Second
+
+var (
+	diskGuardMinFree uint64
+	diskGuardAction  DiskGuardAction
+	diskGuard        = &diskGuardState{}
+
+	freeDiskSpaceFunc = freeDiskSpace
+)
+
+// diskGuardState is shared by the rotators because they write to the same volume.
+type diskGuardState struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	low       bool
+}
+
+// String is return DiskGuardAction type string.
+func (a DiskGuardAction) String() string {
+	return diskGuardActionStrings[a]
+}
+
+// SetDiskGuard set the disk guard that monitors the free space of the log volume.
+// When the free space is below minFreeMB megabytes, the action is taken and an internal WARNING is logged.
+// action can use (DiskGuardConsoleOnly, DiskGuardRemoveBackups).
+// 0 (default) means the disk guard is disabled.
+func SetDiskGuard(minFreeMB int, action DiskGuardAction) {
+	diskGuardMinFree = uint64(minFreeMB) * megabyte
+	diskGuardAction = action
+}
+
+// lowDiskSpace reports whether the free space of the log volume is low.
+// The free space is checked every diskGuardInterval.
+// warn is the function to log the change of the state. It must be called after r.mu is unlocked
+// because the internal logger writes to the log file.
+func (r *rotator) lowDiskSpace(now time.Time) (low bool, warn func()) {
+	diskGuard.mu.Lock()
+	defer diskGuard.mu.Unlock()
+	if !diskGuard.lastCheck.IsZero() && now.Sub(diskGuard.lastCheck) < diskGuardInterval {
+		return diskGuard.low, nil
+	}
+	diskGuard.lastCheck = now
+
+	dir := filepath.Dir(r.Filename)
+	free, ok := freeDiskSpaceFunc(dir)
+	if !ok {
+		return false, nil
+	}
+	var removed []string
+	if free < diskGuardMinFree && diskGuardAction == DiskGuardRemoveBackups {
+		free, removed = r.removeBackupsForSpace(dir, free)
+	}
+
+	low = free < diskGuardMinFree
+	changed := low != diskGuard.low
+	diskGuard.low = low
+	if !changed && removed == nil {
+		return low, nil
+	}
+	c := fmt.Sprintf("Free: %d MB, Dir: %s", free/megabyte, dir)
+	return low, func() {
+		if internalLogger == nil {
+			return
+		}
+		if removed != nil {
+			iWarn("LOW_DISK_SPACE_BACKUPS_REMOVED", Console(c), zap.Strings("files", removed))
+		}
+		if changed && low {
+			iWarn("LOW_DISK_SPACE", Console(c+", Action: ConsoleOnly"))
+		} else if changed {
+			iWarn("DISK_SPACE_RECOVERED", Console(c))
+		}
+	}
+}
+
+// removeBackupsForSpace removes the oldest backups until the free space exceeds diskGuardMinFree.
+// Only the files rotated by lumberjack are removed, so that the current file and the files of the other loggers
+// such as SetNamedFile are never removed. The backups of all the expansions of the file name template are removed.
+func (r *rotator) removeBackupsForSpace(dir string, free uint64) (uint64, []string) {
+	files, err := r.listBackups()
+	if err != nil {
+		return free, nil
+	}
+	var removed []string
+	for i := len(files) - 1; i >= 0; i-- { // The older files come last.
+		if free >= diskGuardMinFree {
+			break
+		}
+		if !files[i].backup {
+			continue
+		}
+		if err := os.Remove(files[i].path); err != nil {
+			continue
+		}
+		removed = append(removed, files[i].path)
+		if f, ok := freeDiskSpaceFunc(dir); ok {
+			free = f
+		}
+	}
+	return free, removed
+}
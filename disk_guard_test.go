@@ -0,0 +1,122 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stubFreeDiskSpace(t *testing.T, free *uint64) {
+	freeDiskSpaceFunc = func(string) (uint64, bool) { return *free, true }
+	t.Cleanup(func() { freeDiskSpaceFunc = freeDiskSpace })
+}
+
+func Test_freeDiskSpace(t *testing.T) {
+	free, ok := freeDiskSpace(t.TempDir())
+	assert.True(t, ok)
+	assert.Greater(t, free, uint64(0))
+}
+
+func TestSetDiskGuard(t *testing.T) {
+	t.Run("console only", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		now := time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC)
+		rotateNow = func() time.Time { return now }
+		free := uint64(50 * megabyte)
+		stubFreeDiskSpace(t, &free)
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		SetDiskGuard(100, DiskGuardConsoleOnly)
+
+		r := newRotator()
+		low, warn := r.lowDiskSpace(now)
+		assert.True(t, low)
+		assert.NotNil(t, warn)
+		_, err := r.Write([]byte("skipped\n"))
+		assert.NoError(t, err)
+
+		free = 200 * megabyte
+		now = now.Add(diskGuardInterval)
+		_, err = r.Write([]byte("written\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+
+		b, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+		assert.NoError(t, err)
+		assert.Equal(t, "written\n", string(b))
+		rotateNow = time.Now
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("remove backups", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		backups := []string{
+			"app-2023-09-07T00-00-00.000.jsonl.gz",
+			"app-2023-09-08T00-00-00.000.jsonl.gz",
+			"app-2023-09-09T00-00-00.000.jsonl",
+		}
+		for _, name := range backups {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+		}
+		free := uint64(50 * megabyte)
+		freeDiskSpaceFunc = func(string) (uint64, bool) {
+			free += 30 * megabyte // Each removal frees 30MB.
+			return free - 30*megabyte, true
+		}
+		t.Cleanup(func() { freeDiskSpaceFunc = freeDiskSpace })
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		SetDiskGuard(100, DiskGuardRemoveBackups)
+
+		r := newRotator()
+		low, warn := r.lowDiskSpace(time.Now())
+		assert.False(t, low)
+		assert.NotNil(t, warn)
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, backups[2])}, files)
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("remove only backups", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		kept := []string{"app.jsonl", "app-access.jsonl", "app-old.jsonl", "other-2023-09-07T00-00-00.000.jsonl"}
+		removed := []string{"app-2023-09-08T00-00-00.000.jsonl.zst"}
+		for _, name := range append(kept, removed...) {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+		}
+		free := uint64(50 * megabyte)
+		stubFreeDiskSpace(t, &free)
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		SetDiskGuard(100, DiskGuardRemoveBackups)
+
+		r := newRotator()
+		low, _ := r.lowDiskSpace(time.Now())
+		assert.True(t, low)
+		for _, name := range kept {
+			assert.FileExists(t, filepath.Join(dir, name))
+		}
+		for _, name := range removed {
+			assert.NoFileExists(t, filepath.Join(dir, name))
+		}
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		free := uint64(0)
+		stubFreeDiskSpace(t, &free)
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		r := newRotator()
+		_, err := r.Write([]byte("written\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.FileExists(t, filepath.Join(dir, "app.jsonl"))
+		ResetGlobalLoggerSettings()
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package zl
+
+// freeDiskSpace is not supported on this platform, so the disk guard does nothing.
+func freeDiskSpace(_ string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package zl
+
+import "syscall"
+
+// freeDiskSpace returns the available bytes of the file system of dir for the unprivileged user.
+func freeDiskSpace(dir string) (uint64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true
+}
@@ -0,0 +1,155 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+	au "github.com/logrusorgru/aurora/v4"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	dumpMessage  = "DUMP"
+	dumpFieldKey = "dump"
+)
+
+var (
+	dumpConfig = newDumpConfig()
+	dumpToFile bool
+)
+
+func newDumpConfig() *spew.ConfigState {
+	return &spew.ConfigState{Indent: " "}
+}
+
+// SetDumpMaxDepth is set the maximum depth of the nested data structures displayed by Dump.
+// 0 (default) means unlimited.
+func SetDumpMaxDepth(depth int) {
+	dumpConfig.MaxDepth = depth
+}
+
+// SetDumpIndent is set the indent string used by Dump and DumpJSON.
+// Default is a single space.
+func SetDumpIndent(indent string) {
+	dumpConfig.Indent = indent
+}
+
+// SetDumpToFile is set whether the dumps are also written to the log as the DEBUG `dump` field.
+// It is useful for keeping the deep debugging output when PrettyOutput is not used.
+func SetDumpToFile(val bool) {
+	dumpToFile = val
+}
+
+// DumpJSON is displayed the values as an indented JSON to aid in debugging.
+// It is only works with PrettyOutput settings or SetDumpToFile.
+func DumpJSON(a ...interface{}) {
+	checkInit()
+	s := dumpJSON(maskDumpValues(a)...)
+	pretty.dumpString(3, s)
+	writeDump(s)
+}
+
+// DumpYAML is displayed the values as an YAML to aid in debugging.
+// It is only works with PrettyOutput settings or SetDumpToFile.
+func DumpYAML(a ...interface{}) {
+	checkInit()
+	s := dumpYAML(maskDumpValues(a)...)
+	pretty.dumpString(3, s)
+	writeDump(s)
+}
+
+// DumpDiff is displayed the colored diff of the go-spew dumps of a and b to aid in debugging.
+// It is useful for finding out why a struct has changed.
+// It is only works with PrettyOutput settings or SetDumpToFile.
+func DumpDiff(a, b interface{}) {
+	checkInit()
+	s := dumpDiff(maskDumpValue(a), maskDumpValue(b))
+	pretty.dumpString(3, colorDiff(s))
+	writeDump(s)
+}
+
+func dumpJSON(a ...interface{}) string {
+	var b strings.Builder
+	for i := range a {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", dumpConfig.Indent)
+		if err := enc.Encode(a[i]); err != nil {
+			b.WriteString(err.Error() + "\n")
+			continue
+		}
+		b.Write(buf.Bytes())
+	}
+	return b.String()
+}
+
+func dumpYAML(a ...interface{}) string {
+	var b strings.Builder
+	for i := range a {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		if err := enc.Encode(a[i]); err != nil {
+			b.WriteString(err.Error() + "\n")
+			continue
+		}
+		_ = enc.Close()
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		b.Write(buf.Bytes())
+	}
+	return b.String()
+}
+
+// dumpDiff returns the unified diff of the dumps of a and b.
+// The pointer addresses are not dumped so that only the changes of the values are displayed.
+func dumpDiff(a, b interface{}) string {
+	conf := *dumpConfig
+	conf.DisablePointerAddresses = true
+	conf.DisableCapacities = true
+	conf.SortKeys = true
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(conf.Sdump(a)),
+		B:        difflib.SplitLines(conf.Sdump(b)),
+		FromFile: "a",
+		ToFile:   "b",
+		Context:  3,
+	})
+	if err != nil {
+		return err.Error() + "\n"
+	}
+	if diff == "" {
+		return "no differences\n"
+	}
+	return "\n" + diff
+}
+
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			lines[i] = au.Bold(line).String()
+		case strings.HasPrefix(line, "-"):
+			lines[i] = au.Red(line).String()
+		case strings.HasPrefix(line, "+"):
+			lines[i] = au.Green(line).String()
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = au.Cyan(line).String()
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// writeDump writes s to the log as the `dump` field if SetDumpToFile is enabled.
+// The caller is the caller of the Dump functions.
+func writeDump(s string) {
+	if !dumpToFile {
+		return
+	}
+	zapLogger.WithOptions(zap.AddCallerSkip(1)).Debug(dumpMessage, zap.String(dumpFieldKey, s))
+}
@@ -0,0 +1,77 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type dumpTestUser struct {
+	Name    string          `json:"name" yaml:"name"`
+	Friends []*dumpTestUser `json:"friends,omitempty" yaml:"friends,omitempty"`
+}
+
+func Test_dumpJSON(t *testing.T) {
+	user := dumpTestUser{Name: "Alice", Friends: []*dumpTestUser{{Name: "Bob"}}}
+	assert.Equal(t, "{\n \"name\": \"Alice\",\n \"friends\": [\n  {\n   \"name\": \"Bob\"\n  }\n ]\n}\n", dumpJSON(user))
+
+	SetDumpIndent("\t")
+	assert.Equal(t, "{\n\t\"name\": \"Bob\"\n}\n\"test\"\n", dumpJSON(user.Friends[0], "test"))
+	assert.Equal(t, "json: unsupported type: func()\n", dumpJSON(func() {}))
+	ResetGlobalLoggerSettings()
+}
+
+func Test_dumpYAML(t *testing.T) {
+	user := dumpTestUser{Name: "Alice", Friends: []*dumpTestUser{{Name: "Bob"}}}
+	assert.Equal(t, "name: Alice\nfriends:\n    - name: Bob\n---\ntest\n", dumpYAML(user, "test"))
+}
+
+func TestSetDumpMaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	SetColor(ColorNever)
+	SetDumpMaxDepth(1)
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	l.dump(dumpTestUser{Name: "Alice", Friends: []*dumpTestUser{{Name: "Bob"}}})
+	assert.Equal(t, "DUMP (zl.dumpTestUser) {\n Name: (string) (len=5) \"Alice\",\n Friends: ([]*zl.dumpTestUser) (len=1 cap=1) {\n  <max depth reached>\n }\n}\n", buf.String())
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetDumpToFile(t *testing.T) {
+	core, logs := observer.New(DebugLevel)
+	zapLogger = zap.New(core)
+	outputType = FileOutput
+
+	DumpJSON("test")
+	assert.Equal(t, 0, logs.Len())
+
+	SetDumpToFile(true)
+	DumpJSON("test")
+	DumpYAML("test")
+	Dump("test")
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, dumpMessage, entries[0].Message)
+	assert.Equal(t, map[string]interface{}{dumpFieldKey: "\"test\"\n"}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{dumpFieldKey: "test\n"}, entries[1].ContextMap())
+	assert.Equal(t, map[string]interface{}{dumpFieldKey: "(string) (len=4) \"test\"\n"}, entries[2].ContextMap())
+	ResetGlobalLoggerSettings()
+}
+
+func Test_dumpDiff(t *testing.T) {
+	a := &dumpTestUser{Name: "Alice"}
+	b := &dumpTestUser{Name: "Bob"}
+	assert.Equal(t, "\n--- a\n+++ b\n@@ -1,5 +1,5 @@\n (*zl.dumpTestUser)({\n- Name: (string) (len=5) \"Alice\",\n+ Name: (string) (len=3) \"Bob\",\n  Friends: ([]*zl.dumpTestUser) <nil>\n })\n \n", dumpDiff(a, b))
+	assert.Equal(t, "no differences\n", dumpDiff(a, &dumpTestUser{Name: "Alice"}))
+}
+
+func Test_colorDiff(t *testing.T) {
+	assert.Equal(t,
+		"\x1b[1m--- a\n\x1b[0m\x1b[1m+++ b\n\x1b[0m\x1b[36m@@ -1 +1 @@\n\x1b[0m\x1b[31m-1\n\x1b[0m\x1b[32m+2\n\x1b[0m",
+		colorDiff("--- a\n+++ b\n@@ -1 +1 @@\n-1\n+2\n"),
+	)
+}
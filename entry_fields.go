@@ -0,0 +1,132 @@
+package zl
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// crockford is the Base32 alphabet of ULID.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	// entrySeq is the sequence number of the entries written in the process.
+	entrySeq atomic.Uint64
+
+	ulidMu     sync.Mutex
+	lastULID   [16]byte
+	lastULIDMs uint64
+)
+
+// entryFieldsCore is a zapcore.Core that adds the optional fields whose values are different for each entry.
+// It wraps the core that encodes the entries so that the fields are the same in all the sinks.
+type entryFieldsCore struct {
+	zapcore.Core
+	seq, entryID, uptime, goroutine, pprofLabels bool
+}
+
+func newEntryFieldsCore(core zapcore.Core) zapcore.Core {
+	c := &entryFieldsCore{
+		Core:        core,
+		seq:         lo.Contains(enableKeys, SeqKey),
+		entryID:     lo.Contains(enableKeys, EntryIDKey),
+		uptime:      lo.Contains(enableKeys, UptimeKey),
+		goroutine:   lo.Contains(enableKeys, GoroutineKey),
+		pprofLabels: lo.Contains(enableKeys, PprofLabelsKey),
+	}
+	if !c.seq && !c.entryID && !c.uptime && !c.goroutine && !c.pprofLabels {
+		return core
+	}
+	return c
+}
+
+func (c *entryFieldsCore) With(fields []zap.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	return &clone
+}
+
+func (c *entryFieldsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entryFieldsCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	fields = fields[:len(fields):len(fields)]
+	if c.seq {
+		fields = append(fields, zap.Uint64(string(SeqKey), entrySeq.Add(1)))
+	}
+	if c.entryID {
+		fields = append(fields, zap.String(string(EntryIDKey), newULID(ent.Time)))
+	}
+	if c.uptime {
+		fields = append(fields, zap.Duration(string(UptimeKey), ent.Time.Sub(startTime)))
+	}
+	if c.goroutine || c.pprofLabels {
+		// Write is called by the goroutine of the log because the entries are encoded before SetAsync queues them.
+		id := goroutineID()
+		if c.goroutine {
+			fields = append(fields, zap.Uint64(string(GoroutineKey), id))
+			if label, ok := goroutineLabel(id); ok {
+				fields = append(fields, zap.String(GoroutineLabelKey, label))
+			}
+		}
+		if labels, ok := goroutinePprofLabel(id); c.pprofLabels && ok {
+			fields = append(fields, zap.Object(string(PprofLabelsKey), labels))
+		}
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// newULID returns a ULID of t. See: https://github.com/ulid/spec
+// The ULIDs in the same millisecond are monotonically increased so that they are sorted in the order of the entries.
+func newULID(t time.Time) string {
+	ulidMu.Lock()
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	if ms == lastULIDMs {
+		id = lastULID
+		for i := 15; i >= 6; i-- { // Increment the random part.
+			id[i]++
+			if id[i] != 0 {
+				break
+			}
+		}
+	} else {
+		binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+		binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+		_, _ = rand.Read(id[6:])
+	}
+	lastULID, lastULIDMs = id, ms
+	ulidMu.Unlock()
+	return encodeULID(id)
+}
+
+// encodeULID encodes id to the 26 characters of Crockford's Base32.
+func encodeULID(id [16]byte) string {
+	var b [26]byte
+	// 128 bits are encoded as 130 bits with 2 leading zero bits.
+	high := binary.BigEndian.Uint64(id[0:8])
+	low := binary.BigEndian.Uint64(id[8:16])
+	for i := 25; i >= 0; i-- {
+		b[i] = crockford[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(b[:])
+}
+
+func resetEntryFields() {
+	entrySeq.Store(0)
+	ulidMu.Lock()
+	lastULID, lastULIDMs = [16]byte{}, 0
+	ulidMu.Unlock()
+}
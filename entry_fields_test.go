@@ -0,0 +1,77 @@
+package zl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetEnableKeys_entryFields(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetEnableKeys(SeqKey, EntryIDKey)
+	Init()
+	for i := 0; i < 5; i++ {
+		Info("ENTRY")
+	}
+	New().Info("LOGGER_ENTRY")
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var ids []string
+	for i, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, float64(i+1), entry[string(SeqKey)])
+		ids = append(ids, entry[string(EntryIDKey)].(string))
+	}
+	assert.Len(t, ids, 6)
+	assert.True(t, sort.StringsAreSorted(ids), ids)
+	assert.Len(t, ids[0], 26)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_newULID(t *testing.T) {
+	resetEntryFields()
+	tm := time.UnixMilli(1469918176385)
+	id := newULID(tm)
+	assert.Equal(t, "01ARYZ6S41", id[:10], "the time part of the spec example")
+	next := newULID(tm)
+	assert.Greater(t, next, id)
+	assert.Equal(t, id[:10], next[:10])
+	assert.Greater(t, newULID(tm.Add(time.Millisecond)), next)
+	assert.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID([16]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}))
+	resetEntryFields()
+}
+
+func TestSetEnableKeys_uptime(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	c := &fixedClock{time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)}
+	SetClock(c)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetEnableKeys(UptimeKey)
+	Init()
+	c.t = c.t.Add(90 * time.Second)
+	Info("AFTER_STARTUP")
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"AFTER_STARTUP","uptime":"1m30s"}`+"\n", string(b))
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,146 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// HTTPStatusKey is the field key of the HTTP status code of the error. See: ErrorFielder
+	HTTPStatusKey = "http_status"
+
+	// GRPCCodeKey is the field key of the gRPC status code of the error. See: ErrorFielder
+	GRPCCodeKey = "grpc_code"
+
+	// ErrorsKey is the field key of the constituents of the joined error. See: errors.Join
+	ErrorsKey = "errors"
+)
+
+// ErrorFielder is implemented by the errors that add the structured fields to the logs.
+// The fields are added when the error or the error wrapped by it is passed to the Err functions such as ErrorErr.
+// In addition, the HTTPStatusKey field is added for the errors that have `StatusCode() int` or `HTTPStatus() int`,
+// and the GRPCCodeKey field is added for the gRPC status errors that have `GRPCStatus()`.
+// The ErrorsKey field that has the index, the type and the message of each error is added for the joined errors.
+// e.g.
+//
+//	func (e *NotFoundError) LogFields() []zap.Field {
+//		return []zap.Field{zap.String("error_code", "NOT_FOUND"), zap.String("resource", e.Resource)}
+//	}
+type ErrorFielder interface {
+	LogFields() []zap.Field
+}
+
+type statusCoder interface {
+	StatusCode() int
+}
+
+type httpStatuser interface {
+	HTTPStatus() int
+}
+
+// errorFields returns the error field of err and the fields contributed by err.
+func errorFields(err error) []zap.Field {
+	fields := []zap.Field{zap.Error(err)}
+	if err == nil {
+		return fields
+	}
+	if errs := joinedErrors(err); errs != nil {
+		fields = append(fields, zap.Array(ErrorsKey, errs))
+	}
+	var fielder ErrorFielder
+	if errors.As(err, &fielder) {
+		fields = append(fields, fielder.LogFields()...)
+	}
+	var sc statusCoder
+	var hs httpStatuser
+	if errors.As(err, &sc) {
+		fields = append(fields, zap.Int(HTTPStatusKey, sc.StatusCode()))
+	} else if errors.As(err, &hs) {
+		fields = append(fields, zap.Int(HTTPStatusKey, hs.HTTPStatus()))
+	}
+	if code, ok := grpcCode(err); ok {
+		fields = append(fields, zap.String(GRPCCodeKey, code))
+	}
+	return fields
+}
+
+// errorArray is the constituents of a joined error.
+type errorArray []error
+
+// MarshalLogArray writes each error as an object of the index, the type and the message.
+func (errs errorArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for i := range errs {
+		i := i
+		err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddInt("index", i)
+			enc.AddString("type", fmt.Sprintf("%T", errs[i]))
+			msg := errs[i].Error()
+			if scrubbed, ok := scrubString(msg); ok {
+				msg = scrubbed
+			}
+			enc.AddString("message", msg)
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinedErrors returns the constituents of the joined error such as errors.Join and fmt.Errorf with multiple %w
+// in the chain of err. e.g. fmt.Errorf("context: %w", errors.Join(err1, err2)) and Wrap(joined, "message")
+// The errors of go.uber.org/multierr and github.com/hashicorp/go-multierror are also supported.
+func joinedErrors(err error) errorArray {
+	var errs []error
+	var joined interface{ Unwrap() []error }
+	var multi interface{ Errors() []error }
+	var wrapped interface{ WrappedErrors() []error }
+	switch {
+	case errors.As(err, &joined):
+		errs = joined.Unwrap()
+	case errors.As(err, &multi):
+		errs = multi.Errors()
+	case errors.As(err, &wrapped):
+		errs = wrapped.WrappedErrors()
+	}
+	ret := make(errorArray, 0, len(errs))
+	for i := range errs {
+		if errs[i] != nil {
+			ret = append(ret, errs[i])
+		}
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// grpcCode returns the code of the gRPC status error in the chain of err.
+// It uses reflection so that zl does not depend on gRPC.
+// The error must have `GRPCStatus() *status.Status` whose Code() returns codes.Code.
+func grpcCode(err error) (string, bool) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		m := reflect.ValueOf(e).MethodByName("GRPCStatus")
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			continue
+		}
+		status := m.Call(nil)[0]
+		if status.Kind() == reflect.Ptr && status.IsNil() {
+			continue
+		}
+		codeMethod := status.MethodByName("Code")
+		if !codeMethod.IsValid() || codeMethod.Type().NumIn() != 0 || codeMethod.Type().NumOut() != 1 {
+			continue
+		}
+		code := codeMethod.Call(nil)[0]
+		if s, ok := code.Interface().(interface{ String() string }); ok {
+			return s.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,120 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type notFoundError struct {
+	resource string
+}
+
+func (e *notFoundError) Error() string {
+	return e.resource + " not found"
+}
+
+func (e *notFoundError) LogFields() []zap.Field {
+	return []zap.Field{zap.String("error_code", "NOT_FOUND"), zap.String("resource", e.resource)}
+}
+
+func (e *notFoundError) StatusCode() int {
+	return 404
+}
+
+type httpError struct{}
+
+func (httpError) Error() string   { return "bad request" }
+func (httpError) HTTPStatus() int { return 400 }
+
+// grpcTestCode and grpcTestStatus mimic codes.Code and status.Status of gRPC.
+type grpcTestCode uint32
+
+func (c grpcTestCode) String() string { return "Unavailable" }
+
+type grpcTestStatus struct{}
+
+func (*grpcTestStatus) Code() grpcTestCode { return 14 }
+
+type grpcError struct{}
+
+func (grpcError) Error() string               { return "rpc error" }
+func (grpcError) GRPCStatus() *grpcTestStatus { return &grpcTestStatus{} }
+
+func TestErrorFielder(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	ErrorErr("GET_USER", fmt.Errorf("get user: %w", &notFoundError{resource: "user"}))
+	New().WarnErr("BAD_REQUEST", httpError{})
+	Err("CALL", fmt.Errorf("call: %w", grpcError{}))
+	InfoErr("PLAIN", errors.New("plain"))
+
+	entries := obs.All()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, map[string]interface{}{
+		"error": "get user: user not found", "error_code": "NOT_FOUND", "resource": "user", HTTPStatusKey: int64(404),
+	}, entries[0].ContextMap())
+	assert.Equal(t, int64(400), entries[1].ContextMap()[HTTPStatusKey])
+	assert.Equal(t, "Unavailable", entries[2].ContextMap()[GRPCCodeKey])
+	assert.Equal(t, map[string]interface{}{"error": "plain"}, entries[3].ContextMap())
+}
+
+func Test_errorFields(t *testing.T) {
+	assert.Equal(t, []zap.Field{zap.Error(nil)}, errorFields(nil))
+	_, ok := grpcCode(errors.New("plain"))
+	assert.False(t, ok)
+}
+
+func TestErrorFields_joined(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	ErrorErr("BATCH_FAILED", errors.Join(errors.New("first"), nil, &notFoundError{resource: "user"}))
+	ErrorErr("MULTI_WRAP", fmt.Errorf("copy: %w, %w", errors.New("read"), errors.New("write")))
+	entries := obs.All()
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"index": 0, "type": "*errors.errorString", "message": "first"},
+		map[string]interface{}{"index": 1, "type": "*zl.notFoundError", "message": "user not found"},
+	}, entries[0].ContextMap()[ErrorsKey])
+	assert.Equal(t, "first\nuser not found", entries[0].ContextMap()["error"])
+	assert.Equal(t, "NOT_FOUND", entries[0].ContextMap()["error_code"])
+	assert.Len(t, entries[1].ContextMap()[ErrorsKey], 2)
+
+	assert.Nil(t, joinedErrors(errors.New("plain")))
+	assert.Nil(t, joinedErrors(errors.Join(nil)))
+	joined := errors.Join(errors.New("read"), errors.New("write"))
+	assert.Len(t, joinedErrors(fmt.Errorf("copy: %w", joined)), 2)
+	assert.Len(t, joinedErrors(Wrap(joined, "COPY_FAILED")), 2)
+}
+
+func TestErrorFields_joinedScrubbers(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetScrubbers(EmailScrubber)
+	Init()
+
+	ErrorErr("FAIL", errors.Join(errors.New("user bob@example.com not found"), errors.New("x")))
+	entries := obs.All()
+	if !assert.Len(t, entries, 1) {
+		return
+	}
+	assert.Equal(t, "user [EMAIL] not found\nx", entries[0].ContextMap()["error"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"index": 0, "type": "*errors.errorString", "message": "user [EMAIL] not found"},
+		map[string]interface{}{"index": 1, "type": "*errors.errorString", "message": "x"},
+	}, entries[0].ContextMap()[ErrorsKey])
+}
@@ -0,0 +1,206 @@
+package zl
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// InvalidEventNameMessage is the message of the internal WARN log of the event names that are not UPPER_SNAKE_CASE.
+// See: Event
+const InvalidEventNameMessage = "ZL_INVALID_EVENT_NAME"
+
+var (
+	eventNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+
+	// invalidEventNames has the reported event names so that each of them is reported once.
+	invalidEventNames sync.Map
+)
+
+// EventBuilder builds a log of an event. See: Event
+type EventBuilder struct {
+	logger *Logger // logger is nil if it is built by the global Event.
+	name   string
+	err    error
+	fields []zap.Field
+}
+
+// Event returns an EventBuilder of the log whose message is name.
+// name must be UPPER_SNAKE_CASE such as "USER_SIGNED_UP", otherwise the internal WARN log of
+// InvalidEventNameMessage is written once for each name. The field keys are converted to snake_case
+// such as "user_id" so that the same fields have the same names across the codebase.
+// e.g. zl.Event("USER_SIGNED_UP").Str("plan", plan).Int("seats", seats).Info()
+func Event(name string) *EventBuilder {
+	return &EventBuilder{name: name}
+}
+
+// Event returns an EventBuilder of the log of the Logger. See: Event
+func (l *Logger) Event(name string) *EventBuilder {
+	return &EventBuilder{logger: l, name: name}
+}
+
+// Str adds a string field.
+func (e *EventBuilder) Str(key, val string) *EventBuilder {
+	return e.Field(zap.String(key, val))
+}
+
+// Strs adds a string array field.
+func (e *EventBuilder) Strs(key string, val []string) *EventBuilder {
+	return e.Field(zap.Strings(key, val))
+}
+
+// Int adds an int field.
+func (e *EventBuilder) Int(key string, val int) *EventBuilder {
+	return e.Field(zap.Int(key, val))
+}
+
+// Int64 adds an int64 field.
+func (e *EventBuilder) Int64(key string, val int64) *EventBuilder {
+	return e.Field(zap.Int64(key, val))
+}
+
+// Uint64 adds an uint64 field.
+func (e *EventBuilder) Uint64(key string, val uint64) *EventBuilder {
+	return e.Field(zap.Uint64(key, val))
+}
+
+// Float adds a float64 field.
+func (e *EventBuilder) Float(key string, val float64) *EventBuilder {
+	return e.Field(zap.Float64(key, val))
+}
+
+// Bool adds a bool field.
+func (e *EventBuilder) Bool(key string, val bool) *EventBuilder {
+	return e.Field(zap.Bool(key, val))
+}
+
+// Dur adds a time.Duration field.
+func (e *EventBuilder) Dur(key string, val time.Duration) *EventBuilder {
+	return e.Field(zap.Duration(key, val))
+}
+
+// Time adds a time.Time field.
+func (e *EventBuilder) Time(key string, val time.Time) *EventBuilder {
+	return e.Field(zap.Time(key, val))
+}
+
+// Any adds a field of any value. See: zap.Any
+func (e *EventBuilder) Any(key string, val interface{}) *EventBuilder {
+	return e.Field(zap.Any(key, val))
+}
+
+// Err adds the error field. The fields of the error are also added. See: ErrorFielder
+func (e *EventBuilder) Err(err error) *EventBuilder {
+	e.err = err
+	e.fields = append(e.fields, errorFields(err)...)
+	return e
+}
+
+// Field adds the fields with the keys converted to snake_case. fields is not modified.
+func (e *EventBuilder) Field(fields ...zap.Field) *EventBuilder {
+	for i := range fields {
+		f := fields[i]
+		f.Key = toSnakeCase(f.Key)
+		e.fields = append(e.fields, f)
+	}
+	return e
+}
+
+// Debug writes the event as a DEBUG log.
+func (e *EventBuilder) Debug() {
+	if fields, ok := e.enabled(DebugLevel); ok {
+		e.zapLogger(DebugLevel, fields).Debug(e.name, fields...)
+	}
+}
+
+// Info writes the event as an INFO log.
+func (e *EventBuilder) Info() {
+	if fields, ok := e.enabled(InfoLevel); ok {
+		e.zapLogger(InfoLevel, fields).Info(e.name, fields...)
+	}
+}
+
+// Warn writes the event as a WARN log.
+func (e *EventBuilder) Warn() {
+	if fields, ok := e.enabled(WarnLevel); ok {
+		e.zapLogger(WarnLevel, fields).Warn(e.name, fields...)
+	}
+}
+
+// Error writes the event as an ERROR log.
+func (e *EventBuilder) Error() {
+	if fields, ok := e.enabled(ErrorLevel); ok {
+		fields = appendFingerprint(fields, e.name, e.err)
+		e.zapLogger(ErrorLevel, fields).Error(e.name, fields...)
+	}
+}
+
+// enabled returns the fields of the log with the fields of the Logger if the level is enabled.
+// The fields are appended to a copy so that the builder can be reused.
+func (e *EventBuilder) enabled(level zapcore.Level) ([]zap.Field, bool) {
+	checkEventName(e.name)
+	fields := e.fields[:len(e.fields):len(e.fields)]
+	if e.logger == nil {
+		return fields, true
+	}
+	if !e.logger.enabled(level) {
+		return nil, false
+	}
+	return append(fields, e.logger.fields...), true
+}
+
+// zapLogger writes the pretty log of fields and returns the zap logger.
+func (e *EventBuilder) zapLogger(level zapcore.Level, fields []zap.Field) *zap.Logger {
+	p, z := pretty, zapLogger
+	if e.logger == nil {
+		checkInit()
+	} else {
+		p, z = e.logger.pretty, e.logger.zapLogger
+	}
+	if p != nil {
+		if e.err != nil {
+			p.logWithError(e.name, level, e.err, fields)
+		} else {
+			p.log(e.name, level, fields)
+		}
+	}
+	return z
+}
+
+// checkEventName reports the event name that is not UPPER_SNAKE_CASE.
+func checkEventName(name string) {
+	if eventNamePattern.MatchString(name) || internalLogger == nil {
+		return
+	}
+	if _, loaded := invalidEventNames.LoadOrStore(name, struct{}{}); loaded {
+		return
+	}
+	iWarn(InvalidEventNameMessage, zap.String("event", name))
+}
+
+// toSnakeCase converts the key such as "userID", "UserName" and "user-name" to snake_case.
+func toSnakeCase(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ':
+			b.WriteRune('_')
+		case unicode.IsUpper(r):
+			// A new word starts at an upper case after a lower case or before a lower case such as "HTTPStatus".
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
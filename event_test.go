@@ -0,0 +1,105 @@
+package zl
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestEvent(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	Event("USER_SIGNED_UP").Str("plan", "pro").Int("seats", 3).Bool("trial", true).Info()
+	New().Named("user").Event("USER_DELETED").Str("userID", "u1").Dur("elapsed", time.Second).Warn()
+	Event("SYNC_FAILED").Err(errors.New("timeout")).Int64("retry", 2).Error()
+	Event("CACHE_STATS").Float("hit_rate", 0.5).Uint64("size", 10).Strs("keys", []string{"a"}).
+		Time("at", time.Unix(0, 0).UTC()).Any("meta", map[string]int{"a": 1}).Field(zap.Int("HTTPStatus", 200)).Debug()
+
+	entries := obs.All()[1:] // Skip INIT_LOGGER.
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+	assert.Equal(t, InfoLevel, entries[0].Level)
+	assert.Equal(t, map[string]interface{}{"plan": "pro", "seats": int64(3), "trial": true}, entries[0].ContextMap())
+	assert.Equal(t, WarnLevel, entries[1].Level)
+	assert.Equal(t, "user", entries[1].LoggerName)
+	assert.Equal(t, "u1", entries[1].ContextMap()["user_id"])
+	assert.Equal(t, ErrorLevel, entries[2].Level)
+	assert.Equal(t, "timeout", entries[2].ContextMap()["error"])
+	assert.Equal(t, DebugLevel, entries[3].Level)
+	assert.Equal(t, int64(200), entries[3].ContextMap()["http_status"])
+	for _, e := range entries {
+		assert.True(t, strings.HasSuffix(e.Caller.File, "event_test.go"), e.Caller.File)
+	}
+	assert.Empty(t, obs.FilterMessage(InvalidEventNameMessage).All())
+}
+
+func TestEvent_reuse(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	Init()
+
+	fields := []zap.Field{zap.String("userID", "u1")}
+	e := New(zap.String("service", "api")).Event("USER_UPDATED").Field(fields...)
+	e.Info()
+	e.Error()
+	e.Info()
+	assert.Equal(t, "userID", fields[0].Key, "the fields of the caller are not modified")
+
+	entries := obs.FilterMessage("USER_UPDATED").All()
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+	for _, entry := range entries {
+		keys := map[string]int{}
+		for _, f := range entry.Context {
+			keys[f.Key]++
+		}
+		assert.Equal(t, 1, keys["service"], "the fields of the Logger are not duplicated")
+		assert.Equal(t, 1, keys["user_id"])
+	}
+}
+
+func TestEvent_invalidName(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	Event("userSignedUp").Info()
+	Event("userSignedUp").Info()
+	New().Event("Bad Name").Info()
+
+	assert.Equal(t, 2, obs.FilterMessage("userSignedUp").Len())
+	invalid := obs.FilterMessage(InvalidEventNameMessage).All()
+	if assert.Len(t, invalid, 2) {
+		assert.Equal(t, "userSignedUp", invalid[0].ContextMap()["event"])
+		assert.Equal(t, "Bad Name", invalid[1].ContextMap()["event"])
+	}
+}
+
+func Test_toSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"user_id":    "user_id",
+		"userID":     "user_id",
+		"UserName":   "user_name",
+		"HTTPStatus": "http_status",
+		"user-name":  "user_name",
+		"user_ID":    "user_id",
+		"ip4Address": "ip4_address",
+		"http.path":  "http.path",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, toSnakeCase(in), in)
+	}
+}
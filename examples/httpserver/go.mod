@@ -2,15 +2,22 @@ module github.com/nkmr-jp/zl/example/httpserver
 
 go 1.21
 
-require github.com/nkmr-jp/zl v1.3.1
+require (
+	github.com/nkmr-jp/zl v1.3.1
+	github.com/rs/xid v1.5.0
+	go.uber.org/zap v1.27.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
 	github.com/logrusorgru/aurora/v4 v4.0.0 // indirect
-	github.com/rs/xid v1.5.0 // indirect
-	github.com/samber/lo v1.38.1 // indirect
-	go.uber.org/multierr v1.10.0 // indirect
-	go.uber.org/zap v1.25.0 // indirect
-	golang.org/x/exp v0.0.0-20220303212507-bbda1eaf7a17 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/samber/lo v1.47.0 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nkmr-jp/zl => ../../
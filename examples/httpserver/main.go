@@ -79,7 +79,7 @@ func initGlobalLogger() {
 		log.Fatal("ENV is not set")
 	}
 	zl.SetOmitKeys(zl.HostnameKey)
-	zl.SetConsoleFields(TraceIDFieldKey, DurationFieldKey) // Set fields to be output to console
+	zl.AddConsoleFields(TraceIDFieldKey, DurationFieldKey) // Add fields to be output to console
 	zl.Init()                                              // Initialize global logger
 }
 
@@ -1,7 +1,9 @@
 package zl
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -24,3 +26,32 @@ func Consolep(val *string) zap.Field {
 func Consolef(format string, a ...interface{}) zap.Field {
 	return Console(fmt.Sprintf(format, a...))
 }
+
+// Namespace is wrapper of Zap's Namespace.
+// The fields after it are nested in the key in the json log, and they are displayed as `key.field=value`
+// by PrettyFieldsKeyValue. The fields added by the Err functions are also nested if it is passed to them.
+// e.g. zl.Info("REQUEST", zl.Namespace("http"), zap.String("method", "GET"))
+func Namespace(key string) zap.Field {
+	return zap.Namespace(key)
+}
+
+// hasNamespace reports whether fields has a Namespace.
+func hasNamespace(fields []zap.Field) bool {
+	for i := range fields {
+		if fields[i].Type == zapcore.NamespaceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Consolej display the compact json of v to console when output type is pretty.
+// The struct fields tagged with `log:"mask"` or `log:"omit"` are masked in the same way as Object.
+// v is formatted by fmt.Sprintf("%+v") if it can not be encoded to json.
+func Consolej(v interface{}) zap.Field {
+	b, err := json.Marshal(maskValue(reflect.ValueOf(v), 0))
+	if err != nil {
+		return Consolef("%+v", v)
+	}
+	return Console(string(b))
+}
@@ -31,3 +31,27 @@ func TestConsolef(t *testing.T) {
 	expected := zap.Field{Key: consoleFieldDefault, Type: zapcore.StringType, String: "Hello World"}
 	assert.Equal(t, expected, field)
 }
+
+func TestConsolej(t *testing.T) {
+	expected := zap.Field{Key: consoleFieldDefault, Type: zapcore.StringType, String: `{"id":1,"tags":["a"]}`}
+	assert.Equal(t, expected, Consolej(struct {
+		ID   int      `json:"id"`
+		Tags []string `json:"tags"`
+	}{ID: 1, Tags: []string{"a"}}))
+
+	card := maskTestCard{Number: "4111", CVC: 123}
+	assert.Equal(t, `{"number":"***"}`, Consolej(card).String)
+	assert.Equal(t, "null", Consolej(nil).String)
+	assert.Equal(t, "(1+2i)", Consolej(complex(1, 2)).String)
+}
+
+func TestNamespace(t *testing.T) {
+	assert.Equal(t, zap.Namespace("http"), Namespace("http"))
+	assert.True(t, hasNamespace([]zap.Field{zap.Int("a", 1), Namespace("http")}))
+	assert.False(t, hasNamespace([]zap.Field{zap.Int("a", 1)}))
+
+	l := &Logger{fields: []zap.Field{zap.String("trace_id", "t1")}}
+	fs := l.appendFields([]zap.Field{Namespace("http"), zap.String("method", "GET")})
+	assert.Equal(t, []zap.Field{zap.String("trace_id", "t1"), Namespace("http"), zap.String("method", "GET")}, fs.fields)
+	fs.free()
+}
@@ -0,0 +1,214 @@
+package zl
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldTypeConflictMessage is the message of the internal WARN log of the field logged with a different type.
+// See: SetFieldTypeCheck
+const FieldTypeConflictMessage = "ZL_FIELD_TYPE_CONFLICT"
+
+var (
+	fieldTypeCheck       bool
+	fieldTypeCheckStrict bool
+
+	// fieldTypes is the first fieldTypeSeen of each key.
+	fieldTypes sync.Map
+	// fieldTypeConflicts is the reported conflicts of the key and the type.
+	fieldTypeConflicts sync.Map
+)
+
+// fieldTypeSeen is the json type of a field key and the caller that logged it first.
+type fieldTypeSeen struct {
+	typ    string
+	caller string
+}
+
+// SetFieldTypeCheck set whether the fields logged with the different json types by the keys are reported,
+// such as `user_id` logged as a string and then as an integer, which breaks the mappings of Elasticsearch.
+// The internal WARN log of FieldTypeConflictMessage is written once for each key and type.
+// The keys in the namespaces are checked by the dotted keys. e.g. `http.status`
+// It is useful during development. See SetFieldTypeCheckStrict to fail by them.
+func SetFieldTypeCheck(val bool) {
+	fieldTypeCheck = val
+}
+
+// SetFieldTypeCheckStrict set whether the conflicts of SetFieldTypeCheck fail instead of the WARN log.
+// It panics, or fails the test if it is initialized by InitForTest. It enables SetFieldTypeCheck.
+func SetFieldTypeCheckStrict(val bool) {
+	fieldTypeCheckStrict = val
+	if val {
+		fieldTypeCheck = true
+	}
+}
+
+// fieldJSONType returns the json type of the field. It is empty if the field is not checked.
+func fieldJSONType(f zap.Field) string {
+	switch f.Type {
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return "integer"
+	case zapcore.Float64Type, zapcore.Float32Type:
+		return "number"
+	case zapcore.BoolType:
+		return "boolean"
+	case zapcore.StringType, zapcore.ByteStringType, zapcore.BinaryType, zapcore.StringerType, zapcore.ErrorType,
+		zapcore.TimeType, zapcore.TimeFullType, zapcore.DurationType, zapcore.Complex128Type, zapcore.Complex64Type:
+		return "string"
+	case zapcore.ArrayMarshalerType:
+		return "array"
+	case zapcore.ObjectMarshalerType:
+		return "object"
+	case zapcore.ReflectType:
+		return reflectJSONType(f.Interface)
+	}
+	return ""
+}
+
+func reflectJSONType(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return ""
+}
+
+// checkFieldTypes checks the types of fields in the namespace prefix, and returns the namespace prefix after them.
+func checkFieldTypes(prefix string, fields []zap.Field, caller string) string {
+	for i := range fields {
+		f := fields[i]
+		if f.Type == zapcore.NamespaceType {
+			prefix += f.Key + "."
+			continue
+		}
+		typ := fieldJSONType(f)
+		if typ == "" {
+			continue
+		}
+		key := prefix + f.Key
+		v, loaded := fieldTypes.LoadOrStore(key, fieldTypeSeen{typ: typ, caller: caller})
+		if first := v.(fieldTypeSeen); loaded && first.typ != typ {
+			reportFieldTypeConflict(key, typ, caller, first)
+		}
+	}
+	return prefix
+}
+
+func reportFieldTypeConflict(key, typ, caller string, first fieldTypeSeen) {
+	if _, loaded := fieldTypeConflicts.LoadOrStore(key+"\x00"+typ, struct{}{}); loaded {
+		return
+	}
+	if fieldTypeCheckStrict {
+		msg := fmt.Sprintf("zl: field %q is logged as %s at %s, but as %s at %s", key, typ, caller, first.typ, first.caller)
+		if testTB != nil {
+			testTB.Errorf("%s", msg)
+			return
+		}
+		panic(msg)
+	}
+	if internalLogger == nil {
+		return
+	}
+	iWarn(FieldTypeConflictMessage,
+		zap.String("key", key),
+		zap.String("type", typ),
+		zap.String("log_caller", caller),
+		zap.String("first_type", first.typ),
+		zap.String("first_caller", first.caller),
+	)
+}
+
+// fieldTypeCore is a zapcore.Core that checks the types of the fields of the written logs.
+// It is not used by the internal logger because the internal fields are not the fields of the application.
+type fieldTypeCore struct {
+	zapcore.Core
+	prefix string // prefix is the namespace added by With.
+}
+
+func newFieldTypeCore(core zapcore.Core) zapcore.Core {
+	if !fieldTypeCheck {
+		return core
+	}
+	return &fieldTypeCore{Core: core}
+}
+
+func (c *fieldTypeCore) With(fields []zap.Field) zapcore.Core {
+	return &fieldTypeCore{Core: c.Core.With(fields), prefix: checkFieldTypes(c.prefix, fields, "")}
+}
+
+// Check delegates to the wrapped core so that the levels of the wrapped cores are checked,
+// and observes the fields only if the log is written.
+func (c *fieldTypeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ret := c.Core.Check(ent, ce)
+	if ret != nil && ret != ce {
+		return ret.AddCore(ent, fieldTypeObserver{prefix: c.prefix})
+	}
+	return ret
+}
+
+// fieldTypeObserver is a zapcore.Core that checks the fields of the logs and writes nothing.
+type fieldTypeObserver struct {
+	prefix string
+}
+
+func (o fieldTypeObserver) With([]zap.Field) zapcore.Core {
+	return o
+}
+
+func (o fieldTypeObserver) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+func (o fieldTypeObserver) Write(ent zapcore.Entry, fields []zap.Field) error {
+	checkFieldTypes(o.prefix, fields, ent.Caller.TrimmedPath())
+	return nil
+}
+
+func (o fieldTypeObserver) Sync() error {
+	return nil
+}
+
+func (o fieldTypeObserver) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// withoutFieldTypeCheck removes the fieldTypeCore from the core of the internal logger.
+func withoutFieldTypeCheck(core zapcore.Core) zapcore.Core {
+	if c, ok := core.(*fieldTypeCore); ok {
+		return c.Core
+	}
+	return core
+}
+
+func resetFieldTypeCheck() {
+	fieldTypeCheck = false
+	fieldTypeCheckStrict = false
+	fieldTypes = sync.Map{}
+	fieldTypeConflicts = sync.Map{}
+}
@@ -0,0 +1,54 @@
+package zl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetFieldTypeCheck(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetFieldTypeCheck(true)
+	obs := NewTestObserver(t)
+	Init()
+
+	Info("FIRST", zap.String("user_id", "42"), zap.Namespace("http"), zap.Int("status", 200))
+	Info("SECOND", zap.Int("user_id", 42), zap.Any("tags", []string{"a"}))
+	Info("THIRD", zap.Int64("user_id", 43), zap.Strings("tags", []string{"b"}))
+	New().Info("FOURTH", zap.Namespace("http"), zap.String("status", "OK"))
+	Debug("DEBUG", zap.Bool("user_id", true))
+
+	conflicts := obs.FilterMessage(FieldTypeConflictMessage).All()
+	assert.Len(t, conflicts, 2, "the conflicts are reported once for each type, and the disabled logs are not checked")
+	assert.Equal(t, "user_id", conflicts[0].ContextMap()["key"])
+	assert.Equal(t, "integer", conflicts[0].ContextMap()["type"])
+	assert.Equal(t, "string", conflicts[0].ContextMap()["first_type"])
+	assert.Contains(t, conflicts[0].ContextMap()["first_caller"], "field_type_test.go")
+	assert.Equal(t, "http.status", conflicts[1].ContextMap()["key"])
+}
+
+func TestSetFieldTypeCheckStrict(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetOutput(ConsoleOutput)
+	SetFieldTypeCheckStrict(true)
+	Init()
+
+	Info("FIRST", zap.String("user_id", "42"))
+	var msg interface{}
+	func() {
+		defer func() { msg = recover() }()
+		Info("SECOND", zap.Int("user_id", 42))
+	}()
+	assert.Regexp(t, `^zl: field "user_id" is logged as integer at \S+/field_type_test.go:\d+, but as string at \S+/field_type_test.go:\d+$`, msg)
+}
+
+func Test_fieldJSONType(t *testing.T) {
+	assert.Equal(t, "object", fieldJSONType(zap.Any("m", map[string]int{})))
+	assert.Equal(t, "number", fieldJSONType(zap.Any("f", 1.5)))
+	assert.Equal(t, "string", fieldJSONType(zap.Duration("d", 0)))
+	assert.Equal(t, "", fieldJSONType(zap.Any("nil", nil)))
+	assert.Equal(t, "", fieldJSONType(zap.Skip()))
+}
@@ -0,0 +1,61 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"runtime"
+
+	"go.uber.org/zap"
+)
+
+// FingerprintKey is the field key of the fingerprint of the error logs. See: SetErrorFingerprint
+const FingerprintKey = "fingerprint"
+
+var (
+	errorFingerprint bool
+
+	// variablePattern matches the variable parts of the error messages such as ids, sizes and quoted values.
+	variablePattern = regexp.MustCompile(`"[^"]*"|'[^']*'|0x[0-9a-fA-F]+|[0-9]+`)
+)
+
+// SetErrorFingerprint set whether the FingerprintKey field is added to the logs of ErrorErr, Err and ErrRet.
+// The fingerprint is a stable hash of the type of the error, the message template and the caller function,
+// so the same errors have the same fingerprint even if the ids or the values in the error messages are different.
+// The error report of PrettyOutput groups the errors by it with the counts and the first and last seen times.
+func SetErrorFingerprint(val bool) {
+	errorFingerprint = val
+}
+
+// appendFingerprint appends the fingerprint field to fields if it is enabled.
+// It must be called directly by the exported functions to get the caller.
+func appendFingerprint(fields []zap.Field, message string, err error) []zap.Field {
+	if !errorFingerprint || err == nil {
+		return fields
+	}
+	var function string
+	if pc, _, _, ok := runtime.Caller(2); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			function = fn.Name()
+		}
+	}
+	return append(fields, zap.String(FingerprintKey, fingerprint(message, err, function)))
+}
+
+// fingerprint returns the hash of the type of the root cause of err, the message template and the function.
+func fingerprint(message string, err error, function string) string {
+	root := err
+	for {
+		next := errors.Unwrap(root)
+		if next == nil {
+			break
+		}
+		root = next
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%T\n%s\n%s\n%s",
+		root, message, variablePattern.ReplaceAllString(err.Error(), "?"), function,
+	)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,82 @@
+package zl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetErrorFingerprint(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetErrorFingerprint(true)
+	Init()
+
+	for _, id := range []int{1, 2} {
+		ErrorErr("USER_NOT_FOUND", fmt.Errorf("find user: %w", &os.PathError{Op: "open", Path: fmt.Sprintf("user%d", id), Err: os.ErrNotExist}))
+	}
+	ErrorErr("USER_NOT_FOUND", errors.New("find user: open user3"))
+	func() {
+		New().Err("USER_NOT_FOUND", errors.New("find user: open user4"))
+	}()
+	ErrorErr("NO_FINGERPRINT", nil)
+
+	var fingerprints []string
+	for _, e := range obs.FilterMessage("USER_NOT_FOUND").All() {
+		fp, ok := e.ContextMap()[FingerprintKey].(string)
+		assert.True(t, ok)
+		assert.Len(t, fp, 16)
+		fingerprints = append(fingerprints, fp)
+	}
+	assert.Len(t, fingerprints, 4)
+	assert.Equal(t, fingerprints[0], fingerprints[1], "the values in the error message are ignored")
+	assert.NotEqual(t, fingerprints[0], fingerprints[2], "the type of the error is different")
+	assert.NotEqual(t, fingerprints[2], fingerprints[3], "the caller function is different")
+	assert.Equal(t, 0, obs.FilterMessage("NO_FINGERPRINT").FilterFieldKey(FingerprintKey).Len())
+}
+
+func TestSetErrorFingerprint_disabled(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	ErrorErr("SOME_ERROR", errors.New("some error"))
+	assert.Equal(t, 0, obs.FilterFieldKey(FingerprintKey).Len())
+}
+
+func Test_prettyLogger_showErrorReport_fingerprint(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	line := `{"severity":"ERROR","timestamp":"%s","caller":"zl/main.go:%d","message":"READ_ERROR",` +
+		`"error":"read id %d","fingerprint":"%s","stacktrace":"main.main","pid":123}` + "\n"
+	logs := fmt.Sprintf(line, "2023-09-09T15:00:00+09:00", 10, 1, "0123456789abcdef") +
+		fmt.Sprintf(line, "2023-09-09T15:00:05+09:00", 10, 2, "0123456789abcdef") +
+		fmt.Sprintf(line, "2023-09-09T15:00:10+09:00", 20, 3, "fedcba9876543210")
+	assert.NoError(t, os.WriteFile(file, []byte(logs), 0o600))
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.noColor = true
+	l.showErrorReport(file, 123)
+	str := buf.String()
+
+	assert.Contains(t, str, "ErrorCount: 2\n")
+	groups := strings.Split(str, "StackTrace")
+	assert.Len(t, groups, 3)
+	assert.Contains(t, groups[0], "1. main.go:10: ERROR READ_ERROR read id 2 (2 times)\n")
+	assert.Contains(t, groups[0], "Fingerprint:\t0123456789abcdef\n")
+	assert.Contains(t, groups[0], "FirstSeen:\t2023-09-09T15:00:00+09:00\n")
+	assert.Contains(t, groups[0], "LastSeen:\t2023-09-09T15:00:05+09:00\n")
+	assert.Contains(t, groups[1], "2. main.go:20: ERROR READ_ERROR read id 3\n")
+	assert.Contains(t, groups[1], "Timestamp:\t2023-09-09T15:00:10+09:00\n")
+	assert.NotContains(t, groups[1], "FirstSeen")
+	ResetGlobalLoggerSettings()
+}
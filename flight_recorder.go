@@ -0,0 +1,151 @@
+package zl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// CrashReportFileName is the default file name of the crash report. See: SetFlightRecorder
+const CrashReportFileName = "crash.log"
+
+var (
+	flightRecorderSize int
+	crashReportFile    string
+
+	// recorder is shared by all the loggers so that the report has the logs of all of them.
+	recorder   *flightRecorder
+	recorderMu sync.Mutex
+)
+
+// SetFlightRecorder set the flight recorder that keeps the last size logs in memory.
+// The logs of all the levels are kept even if they are lower than the level set by SetLevel.
+// They are written to reportFile with the stacktrace on Fatal, FatalErr, or a panic recovered by DumpOnPanic.
+// If reportFile is empty, CrashReportFileName in the directory of the log file is used.
+// 0 size (default) means the flight recorder is disabled.
+func SetFlightRecorder(size int, reportFile string) {
+	flightRecorderSize = size
+	crashReportFile = reportFile
+}
+
+// DumpOnPanic writes the crash report if the goroutine is panicking, and then panics again.
+// It must be called by defer. e.g. defer zl.DumpOnPanic()
+func DumpOnPanic() {
+	if r := recover(); r != nil {
+		writeCrashReport(ErrFromPanic(r).Error(), string(debug.Stack()))
+		panic(r)
+	}
+}
+
+// flightRecorder is a ring buffer of the encoded logs.
+type flightRecorder struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+func getFlightRecorder() *flightRecorder {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	if recorder == nil {
+		recorder = &flightRecorder{lines: make([][]byte, flightRecorderSize)}
+	}
+	return recorder
+}
+
+// Write keeps a copy of p because zap reuses the buffer.
+func (r *flightRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines[r.next] = append(r.lines[r.next][:0], p...)
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *flightRecorder) Sync() error {
+	return nil
+}
+
+// recent returns the kept logs from the oldest.
+func (r *flightRecorder) recent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []string
+	if r.full {
+		for _, line := range r.lines[r.next:] {
+			ret = append(ret, string(line))
+		}
+	}
+	for _, line := range r.lines[:r.next] {
+		ret = append(ret, string(line))
+	}
+	return ret
+}
+
+// newFlightRecorderCore returns core teed with the core that writes all the levels to the flight recorder.
+func newFlightRecorderCore(core zapcore.Core, enc *zapcore.EncoderConfig) zapcore.Core {
+	if flightRecorderSize <= 0 {
+		return core
+	}
+	return zapcore.NewTee(
+		core,
+		newRedactCore(zapcore.NewCore(zapcore.NewJSONEncoder(*enc), getFlightRecorder(), zapcore.DebugLevel)),
+	)
+}
+
+func getCrashReportFile() string {
+	if crashReportFile != "" {
+		return crashReportFile
+	}
+	return filepath.Join(filepath.Dir(currentFileName()), CrashReportFileName)
+}
+
+// writeCrashReport writes the logs kept by the flight recorder and the stacktrace.
+// It does nothing if the flight recorder is disabled.
+func writeCrashReport(reason, stack string) {
+	recorderMu.Lock()
+	r := recorder
+	recorderMu.Unlock()
+	if r == nil {
+		return
+	}
+	lines := r.recent()
+	var b strings.Builder
+	b.WriteString("CRASH REPORT\n")
+	b.WriteString(fmt.Sprintf("Time: %s\n", clock.Now().Format(time.RFC3339Nano)))
+	b.WriteString(fmt.Sprintf("PID: %d\n", os.Getpid()))
+	b.WriteString(fmt.Sprintf("Reason: %s\n", reason))
+	b.WriteString("\nStacktrace:\n")
+	b.WriteString(strings.TrimSuffix(stack, "\n") + "\n")
+	b.WriteString(fmt.Sprintf("\nRecent Logs (%d):\n", len(lines)))
+	b.WriteString(strings.Join(lines, ""))
+
+	file := getCrashReportFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if err := os.WriteFile(file, []byte(b.String()), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// writeFatalCrashReport writes the crash report of the fatal log.
+func writeFatalCrashReport(ce *zapcore.CheckedEntry) {
+	stack := ce.Stack
+	if stack == "" {
+		stack = string(debug.Stack())
+	}
+	writeCrashReport(fmt.Sprintf("%s %s", ce.Level.CapitalString(), ce.Message), stack)
+}
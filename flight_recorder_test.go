@@ -0,0 +1,76 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetFlightRecorder(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	SetIsTest()
+	t.Cleanup(func() { isTest = false })
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetFlightRecorder(2, "")
+	Init()
+
+	Debug("DEBUG1")
+	Debug("DEBUG2", zap.Int("i", 2))
+	FatalErr("FATAL", errors.New("error"))
+
+	b, err := os.ReadFile(filepath.Join(dir, CrashReportFileName))
+	assert.NoError(t, err)
+	report := string(b)
+	assert.True(t, strings.HasPrefix(report, "CRASH REPORT\n"))
+	assert.Contains(t, report, "Reason: FATAL FATAL\n")
+	assert.Contains(t, report, "\nStacktrace:\ngithub.com/nkmr-jp/zl.TestSetFlightRecorder")
+	assert.Contains(t, report, "\nRecent Logs (2):\n"+
+		`{"severity":"DEBUG","message":"DEBUG2","i":2}`+"\n"+
+		`{"severity":"FATAL","message":"FATAL","error":"error","stacktrace":`,
+	)
+
+	b, err = os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "DEBUG", "the level of the log file is not changed")
+	ResetGlobalLoggerSettings()
+}
+
+func TestDumpOnPanic(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "crash.log")
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetFlightRecorder(10, file)
+	Init()
+	Info("BEFORE_PANIC")
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer DumpOnPanic()
+		panic("boom")
+	})
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Reason: panic: boom\n")
+	assert.Contains(t, string(b), `"message":"BEFORE_PANIC"`)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_flightRecorder_recent(t *testing.T) {
+	r := &flightRecorder{lines: make([][]byte, 3)}
+	for _, s := range []string{"1", "2"} {
+		_, _ = r.Write([]byte(s))
+	}
+	assert.Equal(t, []string{"1", "2"}, r.recent())
+	for _, s := range []string{"3", "4"} {
+		_, _ = r.Write([]byte(s))
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, r.recent())
+}
@@ -0,0 +1,93 @@
+package zl
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FunctionNameFormat is the format of the function names of the FunctionKey field and the console stacktrace.
+type FunctionNameFormat int
+
+const (
+	// FunctionNameFull is the full function name. It is the default. ex. github.com/nkmr-jp/zl.(*Logger).Info
+	FunctionNameFull FunctionNameFormat = iota
+
+	// FunctionNamePackage strips the import path of the package. ex. zl.(*Logger).Info
+	FunctionNamePackage
+
+	// FunctionNameShort strips the import path of the package and the pointer of the receiver. ex. zl.Logger.Info
+	FunctionNameShort
+)
+
+var functionNameFormatStrings = [3]string{
+	"FunctionNameFull",
+	"FunctionNamePackage",
+	"FunctionNameShort",
+}
+
+var (
+	functionNameFormat    FunctionNameFormat
+	pointerReceiverRegexp = regexp.MustCompile(`\(\*([^()]+)\)`)
+)
+
+// String is return FunctionNameFormat type string.
+func (f FunctionNameFormat) String() string {
+	return functionNameFormatStrings[f]
+}
+
+// SetFunctionNameFormat set the format of the function names of the FunctionKey field and the console stacktrace.
+// The default is FunctionNameFull.
+func SetFunctionNameFormat(format FunctionNameFormat) {
+	functionNameFormat = format
+}
+
+// formatFunctionName returns the function name in the format of SetFunctionNameFormat.
+func formatFunctionName(function string) string {
+	if functionNameFormat == FunctionNameFull || function == "" {
+		return function
+	}
+	// The type parameters of the generic functions are not expanded (pkg.F[...]), but the prefix is searched just in case.
+	prefix := function
+	if i := strings.IndexByte(prefix, '['); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if i := strings.LastIndexByte(prefix, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	if functionNameFormat == FunctionNameShort {
+		function = pointerReceiverRegexp.ReplaceAllString(function, "$1")
+	}
+	return function
+}
+
+// functionNameCore is a zapcore.Core that formats the function name of the entries by SetFunctionNameFormat.
+// It wraps the core that encodes the entries so that the other cores get the full function name.
+type functionNameCore struct {
+	zapcore.Core
+}
+
+func newFunctionNameCore(core zapcore.Core) zapcore.Core {
+	if functionNameFormat == FunctionNameFull {
+		return core
+	}
+	return &functionNameCore{Core: core}
+}
+
+func (c *functionNameCore) With(fields []zap.Field) zapcore.Core {
+	return &functionNameCore{Core: c.Core.With(fields)}
+}
+
+func (c *functionNameCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *functionNameCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	ent.Caller.Function = formatFunctionName(ent.Caller.Function)
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,62 @@
+package zl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatFunctionName(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	tests := []struct {
+		format   FunctionNameFormat
+		function string
+		want     string
+	}{
+		{FunctionNameFull, "github.com/nkmr-jp/zl.(*Logger).Info", "github.com/nkmr-jp/zl.(*Logger).Info"},
+		{FunctionNamePackage, "github.com/nkmr-jp/zl.(*Logger).Info", "zl.(*Logger).Info"},
+		{FunctionNameShort, "github.com/nkmr-jp/zl.(*Logger).Info", "zl.Logger.Info"},
+		{FunctionNameShort, "github.com/nkmr-jp/zl.Logger.Info", "zl.Logger.Info"},
+		{FunctionNameShort, "main.main.func1", "main.main.func1"},
+		{FunctionNameShort, "gopkg.in/yaml.v3.(*decoder).unmarshal", "yaml.v3.decoder.unmarshal"},
+		{FunctionNameShort, "github.com/acme/app.(*Cache[...]).Get", "app.Cache[...].Get"},
+		{FunctionNamePackage, "github.com/acme/app.Map[github.com/acme/app/model.User]", "app.Map[github.com/acme/app/model.User]"},
+		{FunctionNameShort, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String()+"/"+tt.function, func(t *testing.T) {
+			SetFunctionNameFormat(tt.format)
+			assert.Equal(t, tt.want, formatFunctionName(tt.function))
+		})
+	}
+}
+
+type functionNameReceiver struct{}
+
+func (r *functionNameReceiver) log() {
+	Info("FUNCTION_NAME")
+}
+
+func TestSetFunctionNameFormat(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetFunctionNameFormat(FunctionNameShort)
+	obs := NewTestObserver(t)
+	Init()
+	(&functionNameReceiver{}).log()
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &entry))
+	assert.Equal(t, "zl.functionNameReceiver.log", entry[string(FunctionKey)])
+	assert.Equal(t, "github.com/nkmr-jp/zl.(*functionNameReceiver).log", obs.All()[0].Entry.Caller.Function,
+		"the other cores get the full function name")
+}
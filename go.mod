@@ -4,16 +4,17 @@ go 1.21
 
 require (
 	github.com/davecgh/go-spew v1.1.1
+	github.com/klauspost/compress v1.17.11
 	github.com/logrusorgru/aurora/v4 v4.0.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/samber/lo v1.47.0
 	github.com/stretchr/testify v1.10.0
 	go.uber.org/zap v1.27.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,60 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+)
+
+// GoroutineLabelKey is the field key of the label set by LabelGoroutine.
+const GoroutineLabelKey = "goroutine_label"
+
+// goroutineLabels has the labels of the goroutine ids.
+var goroutineLabels sync.Map
+
+// LabelGoroutine set the label of the current goroutine such as "worker-3", and returns the function to remove it.
+// The label is added to the GoroutineLabelKey field of the logs of the goroutine if GoroutineKey is enabled by SetEnableKeys.
+// It is also set to the pprof label "goroutine" so that the profiles can be separated by it,
+// and it is added to the PprofLabelsKey field if PprofLabelsKey is enabled.
+// e.g.
+//
+//	go func() {
+//		defer zl.LabelGoroutine("worker-3")()
+//		...
+//	}()
+func LabelGoroutine(label string) func() {
+	id := goroutineID()
+	goroutineLabels.Store(id, label)
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("goroutine", label))
+	pprof.SetGoroutineLabels(ctx)
+	restore := setGoroutinePprofLabels(id, contextPprofLabels(ctx))
+	return func() {
+		goroutineLabels.Delete(id)
+		restore()
+		pprof.SetGoroutineLabels(context.Background())
+	}
+}
+
+// goroutineLabel returns the label of the goroutine set by LabelGoroutine.
+func goroutineLabel(id uint64) (string, bool) {
+	v, ok := goroutineLabels.Load(id)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// goroutineID returns the id of the current goroutine parsed from the header of the stack such as "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
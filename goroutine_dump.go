@@ -0,0 +1,132 @@
+package zl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// GoroutinesKey is the field key of the stacks of all the goroutines. See: SetGoroutineDump
+	GoroutinesKey = "goroutines"
+
+	// GoroutinesFileKey is the field key of the file of the stacks of all the goroutines. See: SetGoroutineDump
+	GoroutinesFileKey = "goroutines_file"
+
+	// GoroutineDumpFileName is the default file name of the goroutine dump. See: SetGoroutineDump
+	GoroutineDumpFileName = "goroutines.log"
+
+	// maxGoroutineDumpSize limits the size of the goroutine dump.
+	maxGoroutineDumpSize = 64 << 20
+)
+
+// GoroutineDump determines where the stacks of all the goroutines are written on Fatal and FatalErr.
+type GoroutineDump int
+
+const (
+	// GoroutineDumpNone does not dump the goroutines.
+	// It is Default setting.
+	GoroutineDumpNone GoroutineDump = iota
+
+	// GoroutineDumpField adds the dump to the GoroutinesKey field of the fatal log.
+	GoroutineDumpField
+
+	// GoroutineDumpFile writes the dump to the file and adds the file to the GoroutinesFileKey field of the fatal log.
+	GoroutineDumpFile
+)
+
+var goroutineDumpStrings = [3]string{
+	"None",
+	"Field",
+	"File",
+}
+
+var (
+	goroutineDump     GoroutineDump
+	goroutineDumpFile string
+)
+
+// String is return GoroutineDump type string.
+func (g GoroutineDump) String() string {
+	return goroutineDumpStrings[g]
+}
+
+// SetGoroutineDump set whether the stacks of all the goroutines are dumped on Fatal and FatalErr.
+// It helps to diagnose the fatal errors caused by deadlocks or the other goroutines.
+// option can use (GoroutineDumpNone, GoroutineDumpField, GoroutineDumpFile).
+// fileName is used by GoroutineDumpFile. If it is empty, GoroutineDumpFileName in the directory of the log file is used.
+func SetGoroutineDump(option GoroutineDump, fileName string) {
+	goroutineDump = option
+	goroutineDumpFile = fileName
+}
+
+// goroutineStacks returns the stacks of all the goroutines.
+func goroutineStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDumpSize {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func getGoroutineDumpFile() string {
+	if goroutineDumpFile != "" {
+		return goroutineDumpFile
+	}
+	return filepath.Join(filepath.Dir(currentFileName()), GoroutineDumpFileName)
+}
+
+// goroutineDumpFields returns the fields of the goroutine dump.
+func goroutineDumpFields() []zap.Field {
+	stacks := goroutineStacks()
+	if goroutineDump == GoroutineDumpField {
+		return []zap.Field{zap.ByteString(GoroutinesKey, stacks)}
+	}
+	file := getGoroutineDumpFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+	if err := os.WriteFile(file, stacks, 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+	return []zap.Field{zap.String(GoroutinesFileKey, file)}
+}
+
+// goroutineDumpCore is a zapcore.Core that adds the goroutine dump to the fatal logs.
+type goroutineDumpCore struct {
+	zapcore.Core
+}
+
+func newGoroutineDumpCore(core zapcore.Core) zapcore.Core {
+	if goroutineDump == GoroutineDumpNone {
+		return core
+	}
+	return &goroutineDumpCore{Core: core}
+}
+
+func (c *goroutineDumpCore) With(fields []zap.Field) zapcore.Core {
+	return &goroutineDumpCore{Core: c.Core.With(fields)}
+}
+
+func (c *goroutineDumpCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *goroutineDumpCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if ent.Level >= FatalLevel {
+		fields = append(fields[:len(fields):len(fields)], goroutineDumpFields()...)
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,56 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetGoroutineDump(t *testing.T) {
+	tests := []struct {
+		option GoroutineDump
+		key    string
+	}{
+		{GoroutineDumpField, GoroutinesKey},
+		{GoroutineDumpFile, GoroutinesFileKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.option.String(), func(t *testing.T) {
+			ResetGlobalLoggerSettings()
+			dir := t.TempDir()
+			SetIsTest()
+			t.Cleanup(func() { isTest = false })
+			SetOutput(FileOutput)
+			SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+			SetGoroutineDump(tt.option, "")
+			Init()
+
+			blocked := make(chan struct{})
+			defer close(blocked)
+			go func() { <-blocked }()
+			Error("NOT_FATAL")
+			FatalErr("FATAL", errors.New("error"))
+
+			b, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+			assert.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			assert.NotContains(t, lines[len(lines)-2], tt.key)
+			assert.Contains(t, lines[len(lines)-1], `"`+tt.key+`":`)
+
+			dump := lines[len(lines)-1]
+			if tt.option == GoroutineDumpFile {
+				assert.Contains(t, dump, filepath.Join(dir, GoroutineDumpFileName))
+				b, err = os.ReadFile(filepath.Join(dir, GoroutineDumpFileName))
+				assert.NoError(t, err)
+				dump = string(b)
+			}
+			assert.Contains(t, dump, "TestSetGoroutineDump.func")
+			assert.GreaterOrEqual(t, strings.Count(dump, "goroutine "), 2, "the other goroutines are dumped")
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package zl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelGoroutine(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetEnableKeys(GoroutineKey)
+	Init()
+
+	var wg sync.WaitGroup
+	ids := make(chan uint64, 2)
+	for _, label := range []string{"worker-1", "worker-2"} {
+		wg.Add(1)
+		go func(label string) {
+			defer wg.Done()
+			defer LabelGoroutine(label)()
+			ids <- goroutineID()
+			Info("WORK", Console(label))
+		}(label)
+	}
+	wg.Wait()
+	close(ids)
+	Info("MAIN")
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	labels := map[string]float64{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.NotZero(t, entry[string(GoroutineKey)])
+		if entry["message"] == "WORK" {
+			assert.Equal(t, entry["console"], entry[GoroutineLabelKey])
+			labels[entry[GoroutineLabelKey].(string)] = entry[string(GoroutineKey)].(float64)
+		} else {
+			assert.NotContains(t, entry, GoroutineLabelKey)
+		}
+	}
+	assert.Len(t, labels, 2)
+	for id := range ids {
+		assert.Contains(t, []float64{labels["worker-1"], labels["worker-2"]}, float64(id))
+	}
+	_, ok := goroutineLabel(uint64(labels["worker-1"]))
+	assert.False(t, ok, "the label is removed")
+	ResetGlobalLoggerSettings()
+}
+
+func Test_goroutineID(t *testing.T) {
+	id := goroutineID()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, goroutineID())
+	ch := make(chan uint64)
+	go func() { ch <- goroutineID() }()
+	assert.NotEqual(t, id, <-ch)
+}
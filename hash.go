@@ -0,0 +1,71 @@
+package zl
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+var (
+	// hashKeys is the lower case keys set by SetHashKeys.
+	hashKeys   map[string]struct{}
+	hashSecret []byte
+	hashMu     sync.Mutex
+)
+
+// SetHashKeys set the field keys whose values are replaced with the hex encoded HMAC-SHA256 of them.
+// The logs remain correlatable by the values without containing the raw identifiers.
+// The keys are matched case-insensitively at any nesting level in the same way as SetRedactKeys,
+// and SetRedactKeys takes precedence over it. The secret key of HMAC is set by SetHashSecret.
+// e.g. SetHashKeys("user_id", "email")
+func SetHashKeys(keys ...string) {
+	hashKeys = make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		hashKeys[strings.ToLower(k)] = struct{}{}
+	}
+}
+
+// SetHashSecret set the secret key of HMAC used by SetHashKeys.
+// If it is not set, a random key is generated for each process,
+// so the values are correlatable only in the same process.
+func SetHashSecret(secret []byte) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	hashSecret = secret
+}
+
+func isHashKey(key string) bool {
+	_, ok := hashKeys[strings.ToLower(key)]
+	return ok
+}
+
+func getHashSecret() []byte {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	if hashSecret == nil {
+		hashSecret = make([]byte, sha256.Size)
+		if _, err := rand.Read(hashSecret); err != nil {
+			panic(err)
+		}
+	}
+	return hashSecret
+}
+
+// hashValue returns the HMAC of v. The strings are hashed as they are, and the others are hashed as json.
+func hashValue(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return RedactedValue
+		}
+		s = string(b)
+	}
+	mac := hmac.New(sha256.New, getHashSecret())
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package zl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func testHMAC(secret, value string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSetHashKeys(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetHashKeys("user_id", "Email", "password")
+	SetRedactKeys("password")
+	SetHashSecret([]byte("secret"))
+	Init()
+
+	Info("TOP", zap.Int("user_id", 42), zap.String("email", "alice@example.com"), zap.String("password", "pass"))
+	Info("NESTED", zap.Any("user", map[string]interface{}{"user_id": 42, "name": "alice"}))
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	userID := testHMAC("secret", "42")
+	assert.Equal(t, `{"severity":"INFO","message":"TOP","user_id":"`+userID+`","email":"`+
+		testHMAC("secret", "alice@example.com")+`","password":"[REDACTED]"}
+{"severity":"INFO","message":"NESTED","user":{"name":"alice","user_id":"`+userID+`"}}
+`, string(b))
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetHashSecret(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	a := hashValue("alice")
+	assert.Equal(t, a, hashValue("alice"), "the random key is kept in the process")
+	assert.Len(t, a, 64)
+
+	SetHashSecret([]byte("secret"))
+	assert.Equal(t, testHMAC("secret", "alice"), hashValue("alice"))
+	ResetGlobalLoggerSettings()
+}
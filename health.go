@@ -0,0 +1,83 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var healthWindow time.Duration
+
+// HealthChecker is implemented by the sinks that can report their health. See: Healthy
+type HealthChecker interface {
+	Healthy() error
+}
+
+// SetHealthWindow set how long the sends of the sinks can keep failing until Healthy reports an error,
+// so that the readiness probes tolerate the transient network errors. 0 (default) reports an error from the first failure.
+func SetHealthWindow(window time.Duration) {
+	healthWindow = window
+}
+
+// Healthy verifies that the logs can be written, so that the broken logging infrastructure is detected early.
+// It is suitable for the readiness probes.
+//   - The log file can be opened to write if the logs are written to the file.
+//   - The free space of the log volume is not low if SetDiskGuard is set.
+//   - The sinks that implement HealthChecker are healthy. e.g. BatchWriter and SQLiteStore
+//
+// It returns the errors of all the failed checks joined.
+func Healthy() error {
+	var errs []error
+	if testTB == nil && (outputType == PrettyOutput || outputType == FileOutput || outputType == ConsoleAndFileOutput) {
+		if err := checkFileWritable(bundleFileName()); err != nil {
+			errs = append(errs, fmt.Errorf("zl: log file is not writable: %w", err))
+		}
+	}
+	if diskGuardMinFree > 0 {
+		diskGuard.mu.Lock()
+		low := diskGuard.low
+		diskGuard.mu.Unlock()
+		if low {
+			errs = append(errs, errors.New("zl: free space of the log volume is low"))
+		}
+	}
+	for _, sink := range sinks {
+		if c, ok := sink.(HealthChecker); ok {
+			if err := c.Healthy(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkFileWritable opens name to append if it exists.
+// Otherwise, it creates a temporary file in the nearest existing parent directory because the file is created by the first write.
+func checkFileWritable(name string) error {
+	if _, err := os.Stat(name); err == nil {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	dir := filepath.Dir(name)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	f, err := os.CreateTemp(dir, ".zl-health-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
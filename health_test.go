@@ -0,0 +1,62 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthy_file(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	dir := t.TempDir()
+	SetOutput(FileOutput)
+
+	SetRotateFileName(filepath.Join(dir, "log", "app.jsonl"))
+	assert.NoError(t, Healthy(), "the directory is created by the first write")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.jsonl"), nil, 0o600))
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, Healthy())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o600))
+	SetRotateFileName(filepath.Join(dir, "file", "app.jsonl"))
+	assert.ErrorContains(t, Healthy(), "zl: log file is not writable")
+
+	SetOutput(ConsoleOutput)
+	assert.NoError(t, Healthy())
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2, "the temporary file is removed")
+}
+
+func TestHealthy_sink(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	c := &fixedClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	SetClock(c)
+	SetOutput(ConsoleOutput)
+	SetHealthWindow(time.Minute)
+	var sendErr error
+	w := NewBatchWriter(func(batch [][]byte) error { return sendErr }, 0, 0, 0)
+	defer w.Close()
+	AddSink(w)
+
+	sendErr = errors.New("connection refused")
+	_, _ = w.Write([]byte("{}\n"))
+	assert.Error(t, w.Sync())
+	assert.NoError(t, Healthy(), "the failures within the window are tolerated")
+
+	c.t = c.t.Add(2 * time.Minute)
+	_, _ = w.Write([]byte("{}\n"))
+	_ = w.Sync()
+	assert.EqualError(t, Healthy(), "zl: sink has failed since 2024-01-02T03:04:05Z: connection refused")
+
+	sendErr = nil
+	_, _ = w.Write([]byte("{}\n"))
+	assert.NoError(t, w.Sync())
+	assert.NoError(t, Healthy())
+}
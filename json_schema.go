@@ -0,0 +1,144 @@
+package zl
+
+import (
+	"encoding/json"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap/zapcore"
+)
+
+// jsonSchemaDraft is the JSON Schema version of EntryJSONSchema.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaProperty is a property of EntryJSONSchema.
+type jsonSchemaProperty = map[string]interface{}
+
+// EntryJSONSchema returns the JSON Schema of the json logs written with the current settings,
+// so that the ingestion pipelines and the contract tests can validate the log format.
+// It reflects the keys of SetFieldKey, SetOmitKeys and SetEnableKeys, the fields of SetSchema,
+// and the service and the environment if they are set. The other fields of the logs are allowed as additionalProperties.
+func EntryJSONSchema() []byte {
+	s := &entrySchema{properties: map[string]interface{}{}}
+	var levels []string
+	for l := zapcore.DebugLevel; l <= zapcore.FatalLevel; l++ {
+		levels = append(levels, l.CapitalString())
+	}
+
+	s.add(MessageKey, fieldKey(MessageKey), stringSchema(), true)
+	s.add(LevelKey, fieldKey(LevelKey), jsonSchemaProperty{"type": "string", "enum": levels}, true)
+	s.add(TimeKey, fieldKey(TimeKey), dateTimeSchema(), true)
+	s.add(LoggerKey, fieldKey(LoggerKey), stringSchema(), false)
+	s.add(CallerKey, fieldKey(CallerKey), stringSchema(), true)
+	s.add(FunctionKey, fieldKey(FunctionKey), stringSchema(), true)
+	s.add(StacktraceKey, fieldKey(StacktraceKey), stringSchema(), false)
+	s.properties["error"] = stringSchema()
+
+	s.addServiceFields()
+	s.add(HostnameKey, string(HostnameKey), stringSchema(), true)
+	s.add(PIDKey, string(PIDKey), integerSchema(), true)
+
+	optional := []struct {
+		key      Key
+		schema   jsonSchemaProperty
+		required bool
+	}{
+		{ContainerIDKey, stringSchema(), false},
+		{ContainerImageKey, stringSchema(), false},
+		{VCSTimeKey, dateTimeSchema(), false},
+		{GoVersionKey, stringSchema(), true},
+		{SeqKey, integerSchema(), true},
+		{EntryIDKey, jsonSchemaProperty{"type": "string", "pattern": "^[0-9A-HJKMNP-TV-Z]{26}$"}, true},
+		{UptimeKey, stringSchema(), true},
+		{GoroutineKey, integerSchema(), true},
+		{UserKey, stringSchema(), false},
+		{ExecutableKey, stringSchema(), false},
+		{ArgsKey, jsonSchemaProperty{"type": "array", "items": stringSchema()}, true},
+		{PPIDKey, integerSchema(), true},
+		{PprofLabelsKey, jsonSchemaProperty{"type": "object", "additionalProperties": stringSchema()}, false},
+	}
+	for _, o := range optional {
+		if lo.Contains(enableKeys, o.key) {
+			s.add(o.key, string(o.key), o.schema, o.required)
+		}
+	}
+
+	b, _ := json.MarshalIndent(map[string]interface{}{
+		"$schema":              jsonSchemaDraft,
+		"title":                "zl log entry",
+		"type":                 "object",
+		"properties":           s.properties,
+		"required":             s.required,
+		"additionalProperties": true,
+	}, "", "  ")
+	return b
+}
+
+type entrySchema struct {
+	properties map[string]interface{}
+	required   []string
+}
+
+// add adds the property of name unless key is omitted by SetOmitKeys.
+func (s *entrySchema) add(key Key, name string, schema jsonSchemaProperty, required bool) {
+	if lo.Contains(omitKeys, key) {
+		return
+	}
+	s.properties[name] = schema
+	if required {
+		s.required = append(s.required, name)
+	}
+}
+
+// addServiceFields adds the version, the service and the environment fields of the schema. See: getServiceFields
+func (s *entrySchema) addServiceFields() {
+	withVersion := !lo.Contains(omitKeys, VersionKey)
+	switch schema {
+	case ECSSchema:
+		s.addService("service.version", withVersion)
+		s.addService("service.name", serviceName != "")
+		s.addService("service.environment", environment != "")
+	case GCPSchema:
+		ctx := &entrySchema{properties: map[string]interface{}{}}
+		ctx.addService("service", serviceName != "")
+		ctx.addService("version", withVersion)
+		if len(ctx.properties) > 0 {
+			s.properties[gcpServiceContextKey] = jsonSchemaProperty{
+				"type": "object", "properties": ctx.properties, "required": ctx.required,
+			}
+			s.required = append(s.required, gcpServiceContextKey)
+		}
+		if environment != "" {
+			labels := &entrySchema{properties: map[string]interface{}{}}
+			labels.addService("env", true)
+			s.properties[gcpLabelsKey] = jsonSchemaProperty{
+				"type": "object", "properties": labels.properties, "required": labels.required,
+			}
+			s.required = append(s.required, gcpLabelsKey)
+		}
+	default:
+		s.addService(string(VersionKey), withVersion)
+		s.addService(string(ServiceKey), serviceName != "")
+		s.addService(string(EnvironmentKey), environment != "")
+	}
+}
+
+// addService adds the required string property of name if ok.
+func (s *entrySchema) addService(name string, ok bool) {
+	if !ok {
+		return
+	}
+	s.properties[name] = stringSchema()
+	s.required = append(s.required, name)
+}
+
+func stringSchema() jsonSchemaProperty {
+	return jsonSchemaProperty{"type": "string"}
+}
+
+func integerSchema() jsonSchemaProperty {
+	return jsonSchemaProperty{"type": "integer"}
+}
+
+func dateTimeSchema() jsonSchemaProperty {
+	return jsonSchemaProperty{"type": "string", "format": "date-time"}
+}
@@ -0,0 +1,88 @@
+package zl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntrySchema struct {
+	Properties map[string]map[string]interface{} `json:"properties"`
+	Required   []string                          `json:"required"`
+}
+
+func parseEntryJSONSchema(t *testing.T) testEntrySchema {
+	t.Helper()
+	var s testEntrySchema
+	assert.NoError(t, json.Unmarshal(EntryJSONSchema(), &s))
+	return s
+}
+
+func TestEntryJSONSchema(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetFieldKey(MessageKey, "msg")
+	SetOmitKeys(FunctionKey, PIDKey)
+	SetEnableKeys(SeqKey, ContainerIDKey)
+	SetService("api")
+
+	s := parseEntryJSONSchema(t)
+	assert.Equal(t, []string{"msg", "severity", "timestamp", "caller", "version", "service", "hostname", "seq"}, s.Required)
+	assert.Contains(t, s.Properties, "logger")
+	assert.Contains(t, s.Properties, "container.id")
+	assert.NotContains(t, s.Properties, "function")
+	assert.NotContains(t, s.Properties, "pid")
+	assert.NotContains(t, s.Properties, "env")
+	assert.Equal(t, "integer", s.Properties["seq"]["type"])
+	assert.Len(t, s.Properties["severity"]["enum"], 7)
+}
+
+func TestEntryJSONSchema_schema(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	ResetGlobalLoggerSettings()
+	SetSchema(ECSSchema)
+	SetEnvironment("prod")
+	s := parseEntryJSONSchema(t)
+	assert.Contains(t, s.Required, "service.version")
+	assert.Contains(t, s.Required, "service.environment")
+	assert.NotContains(t, s.Properties, "service.name")
+
+	ResetGlobalLoggerSettings()
+	SetSchema(GCPSchema)
+	SetEnvironment("prod")
+	s = parseEntryJSONSchema(t)
+	assert.Contains(t, s.Required, gcpServiceContextKey)
+	assert.Contains(t, s.Required, gcpLabelsKey)
+	assert.Equal(t, []interface{}{"version"}, s.Properties[gcpServiceContextKey]["required"])
+}
+
+// TestEntryJSONSchema_logs checks that the keys of the written logs are in the schema.
+func TestEntryJSONSchema_logs(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetService("api")
+	SetEnvironment("prod")
+	SetEnableKeys(SeqKey, EntryIDKey, UptimeKey, GoVersionKey, ArgsKey, PPIDKey)
+	Init()
+	New().Named("named").Info("INFO")
+	Sync()
+
+	s := parseEntryJSONSchema(t)
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(b))), &entry))
+	for k := range entry {
+		assert.Contains(t, s.Properties, k)
+	}
+	for _, k := range s.Required {
+		assert.Contains(t, entry, k)
+	}
+}
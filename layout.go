@@ -0,0 +1,105 @@
+package zl
+
+import (
+	"log"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// PrettyEntry is the data passed to the layout template set by SetPrettyLayout.
+// Each value is already colored by the theme and is empty if it is not available.
+type PrettyEntry struct {
+	Time    string // Time is the timestamp. It is empty when TimeKey is omitted.
+	Level   string // Level is the severity level. ex. INFO
+	Logger  string // Logger is the name of the logger set by Logger.Named.
+	Message string // Message is the log message.
+	Error   string // Error is the error message of the *Err functions.
+	Console string // Console is the console fields joined by the separator.
+	Fields  string // Fields is the fields displayed by SetPrettyFields.
+	Caller  string // Caller is the `file:line` of the caller.
+
+	stacktrace string // stacktrace is displayed under the line. See: SetPrettyStacktrace
+}
+
+var prettyLayout *template.Template
+
+var layoutFuncs = template.FuncMap{
+	"pad":      pad,
+	"padLeft":  padLeft,
+	"truncate": func(width int, s string) string { return truncateVisible(s, width) },
+}
+
+// SetPrettyLayout is set the layout of the console log line when PrettyOutput is used.
+// layout is a text/template with the fields of PrettyEntry. e.g.
+//
+//	zl.SetPrettyLayout("{{.Time}} {{pad 5 .Level}} {{.Logger}} {{truncate 80 .Message}} {{.Console}} {{.Caller}}")
+//
+// The following functions can be used for column alignment. The width does not include color codes.
+//   - pad WIDTH VALUE: pads VALUE with spaces on the right to WIDTH.
+//   - padLeft WIDTH VALUE: pads VALUE with spaces on the left to WIDTH.
+//   - truncate WIDTH VALUE: cuts VALUE to WIDTH characters.
+func SetPrettyLayout(layout string) {
+	tmpl, err := template.New("layout").Funcs(layoutFuncs).Parse(layout)
+	if err != nil {
+		log.Fatalf("%s is invalid layout: %v", layout, err)
+	}
+	prettyLayout = tmpl
+}
+
+func (l *prettyLogger) formatLayout(entry *PrettyEntry) string {
+	var b strings.Builder
+	if err := prettyLayout.Execute(&b, entry); err != nil {
+		l.internalLog.Println(err)
+	}
+	return strings.TrimRight(b.String(), " ") + entry.stacktrace + "\n"
+}
+
+// visibleWidth returns the number of characters of s excluding color codes.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(stripColor(s))
+}
+
+func pad(width int, s string) string {
+	if n := width - visibleWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+func padLeft(width int, s string) string {
+	if n := width - visibleWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// truncateVisible cuts s to width characters excluding color codes.
+// The color codes of s are kept so that the colors are reset correctly.
+func truncateVisible(s string, width int) string {
+	if width <= 0 || visibleWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	count := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			loc := ansiRegexp.FindStringIndex(s[i:])
+			if loc == nil || loc[0] != 0 {
+				loc = []int{0, 1}
+			}
+			b.WriteString(s[i : i+loc[1]])
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if count < width {
+			b.WriteRune(r)
+		} else if count == width {
+			b.WriteString(truncatedMark)
+		}
+		count++
+		i += size
+	}
+	return b.String()
+}
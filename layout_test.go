@@ -0,0 +1,73 @@
+package zl
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetPrettyLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		expected string
+	}{
+		{
+			name:     "reorder",
+			layout:   "{{.Level}} {{.Message}} {{.Error}} {{.Console}} ({{.Caller}})",
+			expected: `^ERROR READ_FILE_ERROR file does not exist console \(layout_test.go:\d+\)\n$`,
+		},
+		{
+			name:     "pad",
+			layout:   "[{{pad 7 .Level}}] [{{padLeft 16 .Message}}]",
+			expected: `^\[ERROR  \] \[ READ_FILE_ERROR\]\n$`,
+		},
+		{
+			name:     "truncate",
+			layout:   "{{.Level}} {{truncate 4 .Message}}",
+			expected: `^ERROR READ…\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetColor(ColorNever)
+			SetPrettyLayout(tt.layout)
+
+			var buf bytes.Buffer
+			l := newPrettyLogger(&buf, os.Stderr)
+			l.Logger.SetFlags(log.Lshortfile)
+			errForTest(l, "READ_FILE_ERROR", os.ErrNotExist, Console("console"))
+			assert.Regexp(t, tt.expected, buf.String())
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
+
+func Test_truncateVisible(t *testing.T) {
+	assert.Equal(t, "\x1b[31mERR…\x1b[0m", truncateVisible("\x1b[31mERROR\x1b[0m", 3))
+	assert.Equal(t, "\x1b[31mERROR\x1b[0m", truncateVisible("\x1b[31mERROR\x1b[0m", 5))
+	assert.Equal(t, "\x1b[31mERROR\x1b[0m  ", pad(7, "\x1b[31mERROR\x1b[0m"))
+	assert.Equal(t, "  \x1b[31mERROR\x1b[0m", padLeft(7, "\x1b[31mERROR\x1b[0m"))
+}
+
+func Test_prettyLogger_formatLayout(t *testing.T) {
+	SetPrettyLayout("{{.Logger}} {{.Time}} {{.Message}}")
+	l := newPrettyLogger(os.Stderr, os.Stderr)
+	assert.Equal(t, "db 10:00 QUERY\n", l.formatLayout(&PrettyEntry{Logger: "db", Time: "10:00", Message: "QUERY"}))
+
+	var errBuf bytes.Buffer
+	SetPrettyLayout("{{.Unknown}}")
+	l = newPrettyLogger(os.Stderr, &errBuf)
+	l.formatLayout(&PrettyEntry{})
+	assert.Contains(t, errBuf.String(), "can't evaluate field Unknown")
+	ResetGlobalLoggerSettings()
+}
+
+// errForTest calls logWithError with the same calldepth as the global logging functions.
+func errForTest(l *prettyLogger, msg string, err error, fields ...zap.Field) {
+	func() { l.logWithError(msg, ErrorLevel, err, fields) }()
+}
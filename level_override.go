@@ -0,0 +1,95 @@
+package zl
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelOverride is the level of the messages that start with prefix.
+type levelOverride struct {
+	prefix string
+	level  zapcore.Level
+}
+
+var levelOverrides []levelOverride
+
+// SetLevelOverride set the level of the logs whose message starts with messagePrefix.
+// It is used to demote the known noisy error messages to WARN, or to promote the specific messages, without code changes.
+// The longest prefix is used if the message matches multiple prefixes.
+// The logs of DPANIC level or higher are not overridden and can not be overridden to,
+// because the behaviors of them such as the exit of Fatal are decided by the level at the call.
+// e.g. zl.SetLevelOverride("CACHE_MISS", zl.DebugLevel)
+func SetLevelOverride(messagePrefix string, level zapcore.Level) {
+	if level >= zapcore.DPanicLevel {
+		return
+	}
+	for i := range levelOverrides {
+		if levelOverrides[i].prefix == messagePrefix {
+			levelOverrides[i].level = level
+			return
+		}
+	}
+	levelOverrides = append(levelOverrides, levelOverride{prefix: messagePrefix, level: level})
+}
+
+// overrideLevel returns the level of message set by SetLevelOverride, or level if it is not overridden.
+func overrideLevel(message string, level zapcore.Level) zapcore.Level {
+	if level >= zapcore.DPanicLevel {
+		return level
+	}
+	matched := -1
+	for i := range levelOverrides {
+		if strings.HasPrefix(message, levelOverrides[i].prefix) &&
+			(matched < 0 || len(levelOverrides[i].prefix) > len(levelOverrides[matched].prefix)) {
+			matched = i
+		}
+	}
+	if matched < 0 {
+		return level
+	}
+	return levelOverrides[matched].level
+}
+
+// levelOverrideCore is a zapcore.Core that checks the entries with the overridden levels.
+type levelOverrideCore struct {
+	zapcore.Core
+	promote bool // promote is true if any overridden level is enabled, so that the lower levels can be promoted.
+}
+
+func newLevelOverrideCore(core zapcore.Core) zapcore.Core {
+	if len(levelOverrides) == 0 {
+		return core
+	}
+	c := &levelOverrideCore{Core: core}
+	for i := range levelOverrides {
+		if core.Enabled(levelOverrides[i].level) {
+			c.promote = true
+		}
+	}
+	return c
+}
+
+func (c *levelOverrideCore) Enabled(level zapcore.Level) bool {
+	return c.Core.Enabled(level) || c.promote && level < zapcore.DPanicLevel
+}
+
+func (c *levelOverrideCore) With(fields []zap.Field) zapcore.Core {
+	return &levelOverrideCore{Core: c.Core.With(fields), promote: c.promote}
+}
+
+// withoutLevelOverride returns the core wrapped by levelOverrideCore
+// to report whether the level is enabled regardless of the promotions.
+func withoutLevelOverride(core zapcore.Core) zapcore.Core {
+	if c, ok := core.(*levelOverrideCore); ok {
+		return c.Core
+	}
+	return core
+}
+
+// Check delegates to the wrapped core with the overridden level so that a Tee checks the level of each core.
+func (c *levelOverrideCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ent.Level = overrideLevel(ent.Message, ent.Level)
+	return c.Core.Check(ent, ce)
+}
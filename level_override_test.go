@@ -0,0 +1,63 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetLevelOverride(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(WarnLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetLevelOverride("CACHE_", WarnLevel)
+	SetLevelOverride("CACHE_MISS", DebugLevel)
+	SetLevelOverride("UPSTREAM_TIMEOUT", WarnLevel)
+	SetLevelOverride("IGNORED", FatalLevel)
+	Init()
+
+	Error("CACHE_MISS")
+	New().Error("CACHE_MISS_USER")
+	Info("CACHE_EVICTED")
+	New().Info("CACHE_EVICTED")
+	Error("UPSTREAM_TIMEOUT_USER")
+	Info("NOT_OVERRIDDEN")
+	if ce := Check(InfoLevel, "CACHE_FULL"); assert.NotNil(t, ce) {
+		ce.Write()
+	}
+	assert.Nil(t, New().Check(ErrorLevel, "CACHE_MISS"))
+	Info("IGNORED")
+
+	var levels []string
+	for _, e := range obs.All() {
+		levels = append(levels, e.Level.CapitalString()+" "+e.Message)
+	}
+	assert.Equal(t, []string{
+		"WARN CACHE_EVICTED", "WARN CACHE_EVICTED", "WARN UPSTREAM_TIMEOUT_USER", "WARN CACHE_FULL",
+	}, levels)
+}
+
+func TestSetLevelOverride_pretty(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetLevelOverride("NOISY_", DebugLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetColor(ColorNever)
+	var buf bytes.Buffer
+	pretty = newPrettyLogger(&buf, os.Stderr)
+	pretty.Logger.SetFlags(0)
+
+	SetLevel(InfoLevel)
+	pretty.log("NOISY_ERROR", ErrorLevel, nil)
+	pretty.log("OTHER_ERROR", ErrorLevel, nil)
+	assert.False(t, strings.Contains(buf.String(), "NOISY_ERROR"))
+	assert.Contains(t, buf.String(), "OTHER_ERROR")
+
+	assert.Equal(t, zapcore.FatalLevel, overrideLevel("NOISY_FATAL", FatalLevel))
+	ResetGlobalLoggerSettings()
+}
@@ -14,20 +14,18 @@ type Logger struct {
 	pretty    *prettyLogger
 	zapLogger *zap.Logger
 	fields    []zap.Field
+	tail      *tailBuffer // tail holds the logs of the request. See: Logger.Buffered
+	release   func()      // release returns the Logger to the pool. See: Request
 }
 
 // New can add additional default fields.
 // e.g. Use this when you want to add a common value in the scope of a context, such as an API request.
 func New(fields ...zap.Field) *Logger {
-	ret := &Logger{
+	return &Logger{
 		pretty:    pretty,
 		zapLogger: newLogger(encoderConfig),
 		fields:    fields,
 	}
-	if outputType == PrettyOutput {
-		ret.zapLogger = ret.zapLogger.WithOptions(zap.WithFatalHook(fatalHook{}))
-	}
-	return ret
 }
 
 // clone creates and returns a shallow copy of the calling Logger instance.
@@ -42,77 +40,139 @@ func (l *Logger) clone() *Logger {
 // Named adds a new path segment to the logger's name. Segments are joined by
 // periods. By default, Loggers are unnamed.
 // e.g. logger.Named("foo").Named("bar") returns a logger named "foo.bar".
+// The logs are written to the file set by SetNamedFile if it is set for the name.
 func (l *Logger) Named(loggerName string) *Logger {
 	if loggerName == "" {
 		return l
 	}
 	clone := l.clone()
 	clone.zapLogger = clone.zapLogger.Named(loggerName)
+	if named := newNamedLogger(clone.zapLogger.Name()); named != nil {
+		clone.zapLogger = named
+		if clone.tail != nil {
+			clone.zapLogger = clone.tail.wrap(named)
+		}
+	}
 	if outputType == PrettyOutput {
 		clone.pretty = newPrettyLogger(getConsoleOutput(), os.Stderr)
 		clone.pretty.Logger.SetPrefix(fmt.Sprintf("%s | ", clone.zapLogger.Name()))
+		clone.pretty.name = clone.zapLogger.Name()
+	}
+	return clone
+}
+
+// With returns a new Logger with the fields added to the default fields of New.
+// Returns a new Logger without overwriting the existing logger.
+// e.g. Use this to add the fields of a scope to a logger that has been named by Named.
+func (l *Logger) With(fields ...zap.Field) *Logger {
+	if len(fields) == 0 {
+		return l
 	}
+	clone := l.clone()
+	clone.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
 	return clone
 }
 
 // Debug is wrapper of Zap's Debug.
 func (l *Logger) Debug(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, DebugLevel, fields).Debug(message, fields...)
+	if !l.enabled(DebugLevel) {
+		return
+	}
+	fs := l.appendFields(fields)
+	l.logger(message, DebugLevel, fs.fields).Debug(message, fs.fields...)
+	fs.free()
 }
 
 // Info is wrapper of Zap's Info.
 func (l *Logger) Info(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, InfoLevel, fields).Info(message, fields...)
+	if !l.enabled(InfoLevel) {
+		return
+	}
+	fs := l.appendFields(fields)
+	l.logger(message, InfoLevel, fs.fields).Info(message, fs.fields...)
+	fs.free()
 }
 
 // Warn is wrapper of Zap's Warn.
 func (l *Logger) Warn(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, WarnLevel, fields).Warn(message, fields...)
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	fs := l.appendFields(fields)
+	l.logger(message, WarnLevel, fs.fields).Warn(message, fs.fields...)
+	fs.free()
 }
 
 // Error is wrapper of Zap's Error.
 func (l *Logger) Error(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, ErrorLevel, fields).Error(message, fields...)
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	fs := l.appendFields(fields)
+	l.logger(message, ErrorLevel, fs.fields).Error(message, fs.fields...)
+	fs.free()
 }
 
 // Fatal is wrapper of Zap's Fatal.
 func (l *Logger) Fatal(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, FatalLevel, fields).Fatal(message, fields...)
+	if !l.enabled(FatalLevel) {
+		return
+	}
+	fs := l.appendFields(fields)
+	l.logger(message, FatalLevel, fs.fields).Fatal(message, fs.fields...)
+	fs.free()
 }
 
 // DebugErr is Outputs a DEBUG log with error field.
 func (l *Logger) DebugErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, DebugLevel, err, fields).Debug(message, fields...)
+	if !l.enabled(DebugLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, DebugLevel, err, fs.fields).Debug(message, fs.fields...)
+	fs.free()
 }
 
 // InfoErr is Outputs INFO log with error field.
 func (l *Logger) InfoErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, InfoLevel, err, fields).Info(message, fields...)
+	if !l.enabled(InfoLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, InfoLevel, err, fs.fields).Info(message, fs.fields...)
+	fs.free()
 }
 
 // WarnErr is Outputs WARN log with error field.
 func (l *Logger) WarnErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, WarnLevel, err, fields).Warn(message, fields...)
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, WarnLevel, err, fs.fields).Warn(message, fs.fields...)
+	fs.free()
 }
 
 // ErrorErr is Outputs ERROR log with error field.
 func (l *Logger) ErrorErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	fs.fields = appendFingerprint(fs.fields, message, err)
+	l.loggerErr(message, ErrorLevel, err, fs.fields).Error(message, fs.fields...)
+	fs.free()
 }
 
 // Err is alias of ErrorErr.
 func (l *Logger) Err(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	fs.fields = appendFingerprint(fs.fields, message, err)
+	l.loggerErr(message, ErrorLevel, err, fs.fields).Error(message, fs.fields...)
+	fs.free()
 }
 
 // ErrRet write error log and return error.
@@ -122,15 +182,82 @@ func (l *Logger) Err(message string, err error, fields ...zap.Field) {
 //	  return zl.ErrRet("SOME_ERROR", fmt.Error("some message err: %w",err))
 //	}
 func (l *Logger) ErrRet(message string, err error, fields ...zap.Field) error {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if !l.enabled(ErrorLevel) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	fs.fields = appendFingerprint(fs.fields, message, err)
+	l.loggerErr(message, ErrorLevel, err, fs.fields).Error(message, fs.fields...)
+	fs.free()
+	return err
+}
+
+// DebugRet write debug log and return error. See: ErrRet
+func (l *Logger) DebugRet(message string, err error, fields ...zap.Field) error {
+	if !l.enabled(DebugLevel) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, DebugLevel, err, fs.fields).Debug(message, fs.fields...)
+	fs.free()
+	return err
+}
+
+// InfoRet write info log and return error. See: ErrRet
+func (l *Logger) InfoRet(message string, err error, fields ...zap.Field) error {
+	if !l.enabled(InfoLevel) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, InfoLevel, err, fs.fields).Info(message, fs.fields...)
+	fs.free()
+	return err
+}
+
+// WarnRet write warn log and return error. See: ErrRet
+func (l *Logger) WarnRet(message string, err error, fields ...zap.Field) error {
+	if !l.enabled(WarnLevel) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, WarnLevel, err, fs.fields).Warn(message, fs.fields...)
+	fs.free()
+	return err
+}
+
+// FatalRet write fatal log and return error. See: ErrRet
+func (l *Logger) FatalRet(message string, err error, fields ...zap.Field) error {
+	if !l.enabled(FatalLevel) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, FatalLevel, err, fs.fields).Fatal(message, fs.fields...)
+	fs.free()
+	return err
+}
+
+// Ret write log of level and return error. See: ErrRet
+func (l *Logger) Ret(level zapcore.Level, message string, err error, fields ...zap.Field) error {
+	if !l.enabled(level) {
+		return err
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	if level == ErrorLevel {
+		fs.fields = appendFingerprint(fs.fields, message, err)
+	}
+	l.loggerErr(message, level, err, fs.fields).Log(level, message, fs.fields...)
+	fs.free()
 	return err
 }
 
 // FatalErr is Outputs ERROR log with error field.
 func (l *Logger) FatalErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, FatalLevel, err, fields).Fatal(message, fields...)
+	if !l.enabled(FatalLevel) {
+		return
+	}
+	fs := l.appendFields(fields, errorFields(err)...)
+	l.loggerErr(message, FatalLevel, err, fs.fields).Fatal(message, fs.fields...)
+	fs.free()
 }
 
 func (l *Logger) logger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
@@ -174,27 +301,27 @@ func Fatal(message string, fields ...zap.Field) {
 
 // DebugErr is Outputs a DEBUG log with error field.
 func DebugErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, DebugLevel, err, fields).Debug(message, append(fields, zap.Error(err))...)
+	loggerErr(message, DebugLevel, err, fields).Debug(message, append(fields, errorFields(err)...)...)
 }
 
 // InfoErr is Outputs INFO log with error field.
 func InfoErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, InfoLevel, err, fields).Info(message, append(fields, zap.Error(err))...)
+	loggerErr(message, InfoLevel, err, fields).Info(message, append(fields, errorFields(err)...)...)
 }
 
 // WarnErr is Outputs WARN log with error field.
 func WarnErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, WarnLevel, err, fields).Warn(message, append(fields, zap.Error(err))...)
+	loggerErr(message, WarnLevel, err, fields).Warn(message, append(fields, errorFields(err)...)...)
 }
 
 // ErrorErr is Outputs ERROR log with error field.
 func ErrorErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	loggerErr(message, ErrorLevel, err, fields).Error(message, appendFingerprint(append(fields, errorFields(err)...), message, err)...)
 }
 
 // Err is alias of ErrorErr.
 func Err(message string, err error, fields ...zap.Field) {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	loggerErr(message, ErrorLevel, err, fields).Error(message, appendFingerprint(append(fields, errorFields(err)...), message, err)...)
 }
 
 // ErrRet write error log and return error.
@@ -204,23 +331,65 @@ func Err(message string, err error, fields ...zap.Field) {
 //	  return zl.ErrRet("SOME_ERROR", fmt.Error("some message err: %w",err))
 //	}
 func ErrRet(message string, err error, fields ...zap.Field) error {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	loggerErr(message, ErrorLevel, err, fields).Error(message, appendFingerprint(append(fields, errorFields(err)...), message, err)...)
+	return err
+}
+
+// DebugRet write debug log and return error. See: ErrRet
+func DebugRet(message string, err error, fields ...zap.Field) error {
+	loggerErr(message, DebugLevel, err, fields).Debug(message, append(fields, errorFields(err)...)...)
+	return err
+}
+
+// InfoRet write info log and return error. See: ErrRet
+func InfoRet(message string, err error, fields ...zap.Field) error {
+	loggerErr(message, InfoLevel, err, fields).Info(message, append(fields, errorFields(err)...)...)
+	return err
+}
+
+// WarnRet write warn log and return error. See: ErrRet
+func WarnRet(message string, err error, fields ...zap.Field) error {
+	loggerErr(message, WarnLevel, err, fields).Warn(message, append(fields, errorFields(err)...)...)
+	return err
+}
+
+// FatalRet write fatal log and return error. See: ErrRet
+func FatalRet(message string, err error, fields ...zap.Field) error {
+	loggerErr(message, FatalLevel, err, fields).Fatal(message, append(fields, errorFields(err)...)...)
+	return err
+}
+
+// Ret write log of level and return error. See: ErrRet
+// e.g.
+//
+//	if err != nil {
+//	  return zl.Ret(zl.WarnLevel, "RETRYABLE_ERROR", err)
+//	}
+func Ret(level zapcore.Level, message string, err error, fields ...zap.Field) error {
+	fs := append(fields, errorFields(err)...)
+	if level == ErrorLevel {
+		fs = appendFingerprint(fs, message, err)
+	}
+	loggerErr(message, level, err, fields).Log(level, message, fs...)
 	return err
 }
 
 // FatalErr is Outputs ERROR log with error field.
 func FatalErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, FatalLevel, err, fields).Fatal(message, append(fields, zap.Error(err))...)
+	loggerErr(message, FatalLevel, err, fields).Fatal(message, append(fields, errorFields(err)...)...)
 }
 
 // Dump is a deep pretty printer for Go data structures to aid in debugging.
-// It is only works with PrettyOutput settings.
+// It is only works with PrettyOutput settings or SetDumpToFile.
+// The depth and indent can be changed by SetDumpMaxDepth and SetDumpIndent.
 //
 // It is wrapper of go-spew.
 // See: https://github.com/davecgh/go-spew
 func Dump(a ...interface{}) {
 	checkInit()
-	pretty.dump(a...)
+	s := dumpConfig.Sdump(maskDumpValues(a)...)
+	pretty.dumpString(3, s)
+	writeDump(s)
 }
 
 func logger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
@@ -233,6 +402,10 @@ func iDebug(message string, fields ...zap.Field) {
 	iLogger(message, DebugLevel, fields).Debug(message, fields...)
 }
 
+func iWarn(message string, fields ...zap.Field) {
+	iLogger(message, WarnLevel, fields).Warn(message, fields...)
+}
+
 func iLogger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
 	checkInit()
 	pretty.log(message, level, fields)
@@ -0,0 +1,81 @@
+package zl
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRet(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetErrorFingerprint(true)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	err := errors.New("retryable")
+	assert.Equal(t, err, DebugRet("DEBUG_RET", err))
+	assert.Equal(t, err, InfoRet("INFO_RET", err))
+	assert.Equal(t, err, WarnRet("WARN_RET", err, zap.Int("attempt", 1)))
+	assert.Equal(t, err, Ret(WarnLevel, "RET", err))
+	assert.Equal(t, err, Ret(ErrorLevel, "RET_ERROR", err))
+	l := New()
+	assert.Equal(t, err, l.DebugRet("LOGGER_DEBUG_RET", err))
+	assert.Equal(t, err, l.InfoRet("LOGGER_INFO_RET", err))
+	assert.Equal(t, err, l.WarnRet("LOGGER_WARN_RET", err))
+	assert.Equal(t, err, l.Ret(InfoLevel, "LOGGER_RET", err))
+
+	entries := obs.FilterFieldKey("error").All()
+	if !assert.Len(t, entries, 9) {
+		return
+	}
+	levels := []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, WarnLevel, ErrorLevel, DebugLevel, InfoLevel, WarnLevel, InfoLevel}
+	for i, e := range entries {
+		assert.Equal(t, levels[i], e.Level, e.Message)
+		assert.Equal(t, "retryable", e.ContextMap()["error"], e.Message)
+		assert.True(t, strings.HasSuffix(e.Caller.File, "logger_test.go"), e.Caller.File)
+	}
+	assert.Equal(t, int64(1), entries[2].ContextMap()["attempt"])
+	assert.Contains(t, entries[4].ContextMap(), FingerprintKey)
+	assert.NotContains(t, entries[3].ContextMap(), FingerprintKey)
+}
+
+func TestLogger_Ret_disabled(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(WarnLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	err := errors.New("ignored")
+	assert.Equal(t, err, New().Ret(InfoLevel, "IGNORED", err))
+	assert.Equal(t, err, New().DebugRet("IGNORED", err))
+	assert.Empty(t, obs.All())
+}
+
+func TestLogger_With(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	base := New(zap.String("service", "api")).Named("worker")
+	l := base.With(zap.String("job_id", "1"))
+	assert.Same(t, base, base.With())
+	l.Info("WITH")
+	base.Info("BASE")
+
+	entries := obs.FilterMessage("WITH").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "worker", entries[0].LoggerName)
+		assert.Equal(t, "api", entries[0].ContextMap()["service"])
+		assert.Equal(t, "1", entries[0].ContextMap()["job_id"])
+	}
+	assert.Equal(t, 0, obs.FilterMessage("BASE").FilterFieldKey("job_id").Len(), "the base logger is not changed")
+}
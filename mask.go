@@ -0,0 +1,349 @@
+package zl
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// MaskedValue is the value of the struct fields tagged with `log:"mask"`. See: Object
+	MaskedValue = "***"
+
+	logTagKey  = "log"
+	logTagMask = "mask"
+	logTagOmit = "omit"
+
+	// maskMaxDepth limits the nesting of the masked values to stop at the circular references.
+	maskMaxDepth = 32
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// maskTypes caches whether the types have the log tags.
+	maskTypes sync.Map
+)
+
+// Object returns a field of v with the struct fields tagged with `log:"mask"` replaced by MaskedValue
+// and the struct fields tagged with `log:"omit"` removed.
+// The tags are honored at any nesting level in the structs, the pointers, the slices, the maps and the interfaces,
+// and the values that may have the tags are replaced by MaskedValue if they are nested deeper than 32 levels.
+// The names of the fields are the same as encoding/json. The Dump functions also honor the tags.
+// e.g.
+//
+//	type User struct {
+//		Name     string `json:"name"`
+//		Password string `json:"password" log:"omit"`
+//		Email    string `json:"email" log:"mask"`
+//	}
+//	zl.Info("USER_CREATED", zl.Object("user", user)) // {"user":{"name":"alice","email":"***"}}
+func Object(key string, v interface{}) zap.Field {
+	masked := maskValue(reflect.ValueOf(v), 0)
+	if obj, ok := masked.(*maskedObject); ok {
+		return zap.Object(key, obj)
+	}
+	return zap.Any(key, masked)
+}
+
+// maskedObject is a struct with the log tags applied. It keeps the order of the fields.
+type maskedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *maskedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for i := range o.keys {
+		if obj, ok := o.values[i].(*maskedObject); ok {
+			if err := enc.AddObject(o.keys[i], obj); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := enc.AddReflected(o.keys[i], o.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON is used when the object is in a slice or a map.
+func (o *maskedObject) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(o.keys[i])
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// maskValue returns v with the log tags applied.
+// The values of the types that do not have the log tags are returned as they are.
+func maskValue(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !hasMaskTags(v.Type()) {
+		return v.Interface()
+	}
+	if depth > maskMaxDepth {
+		// The deeper levels may have the values to be masked.
+		return MaskedValue
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return maskValue(v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		ret := make([]interface{}, v.Len())
+		for i := range ret {
+			ret[i] = maskValue(v.Index(i), depth+1)
+		}
+		return ret
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		ret := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ret[mapKeyString(iter.Key())] = maskValue(iter.Value(), depth+1)
+		}
+		return ret
+	case reflect.Struct:
+		obj := &maskedObject{}
+		maskStruct(obj, v, depth)
+		return obj
+	}
+	return v.Interface()
+}
+
+// maskStruct adds the exported fields of v to obj in the same way as encoding/json.
+func maskStruct(obj *maskedObject, v reflect.Value, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(logTagKey)
+		if tag == logTagOmit {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+		if !sf.IsExported() {
+			// The exported fields of the unexported embedded struct are encoded by encoding/json.
+			if !v.CanAddr() {
+				cp := reflect.New(t).Elem()
+				cp.Set(v)
+				v, fv = cp, cp.Field(i)
+			}
+			fv = reflect.NewAt(sf.Type, unsafe.Pointer(fv.UnsafeAddr())).Elem()
+		}
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				maskStruct(obj, fv, depth+1)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && fv.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		obj.keys = append(obj.keys, name)
+		if tag == logTagMask {
+			obj.values = append(obj.values, MaskedValue)
+			continue
+		}
+		obj.values = append(obj.values, maskValue(fv, depth+1))
+	}
+}
+
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if m, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	b, _ := json.Marshal(k.Interface())
+	return strings.Trim(string(b), `"`)
+}
+
+// hasMaskTags reports whether t has the log tags at any nesting level.
+// The interface types are always true because their dynamic values must be inspected.
+// The types that implement json.Marshaler or encoding.TextMarshaler are not inspected.
+func hasMaskTags(t reflect.Type) bool {
+	if v, ok := maskTypes.Load(t); ok {
+		return v.(bool)
+	}
+	ret := inspectMaskTags(t, make(map[reflect.Type]bool))
+	maskTypes.Store(t, ret)
+	return ret
+}
+
+// inspectMaskTags inspects t. visited has the types being inspected so that the recursive types terminate.
+func inspectMaskTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return inspectMaskTags(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if tag := sf.Tag.Get(logTagKey); tag == logTagMask || tag == logTagOmit {
+				return true
+			}
+			if inspectMaskTags(sf.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maskDumpValues returns the copies of a with the log tags applied for the Dump functions.
+// The masked string fields are set to MaskedValue, and the other masked or omitted fields are set to the zero values.
+func maskDumpValues(a []interface{}) []interface{} {
+	ret := make([]interface{}, len(a))
+	for i := range a {
+		ret[i] = a[i]
+		if a[i] == nil {
+			continue
+		}
+		v := reflect.ValueOf(a[i])
+		if hasMaskTags(v.Type()) {
+			ret[i] = maskCopy(v, 0).Interface()
+		}
+	}
+	return ret
+}
+
+// maskDumpValue returns a copy of a with the log tags applied for the Dump functions.
+func maskDumpValue(a interface{}) interface{} {
+	return maskDumpValues([]interface{}{a})[0]
+}
+
+// maskCopy returns a copy of v with the log tags applied. v is not modified.
+func maskCopy(v reflect.Value, depth int) reflect.Value {
+	if !hasMaskTags(v.Type()) {
+		return v
+	}
+	if depth > maskMaxDepth {
+		// The deeper levels may have the values to be masked.
+		return reflect.Zero(v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(maskCopy(v.Elem(), depth+1))
+		return ret
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		ret := reflect.New(v.Type().Elem())
+		ret.Elem().Set(maskCopy(v.Elem(), depth+1))
+		return ret
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		ret := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ret.Index(i).Set(maskCopy(v.Index(i), depth+1))
+		}
+		return ret
+	case reflect.Array:
+		ret := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			ret.Index(i).Set(maskCopy(v.Index(i), depth+1))
+		}
+		return ret
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		ret := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ret.SetMapIndex(iter.Key(), maskCopy(iter.Value(), depth+1))
+		}
+		return ret
+	case reflect.Struct:
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			// The unexported fields are also set because the dumps of go-spew have them.
+			f := reflect.NewAt(sf.Type, unsafe.Pointer(ret.Field(i).UnsafeAddr())).Elem()
+			switch sf.Tag.Get(logTagKey) {
+			case logTagMask:
+				if sf.Type.Kind() == reflect.String {
+					f.SetString(MaskedValue)
+				} else {
+					f.Set(reflect.Zero(sf.Type))
+				}
+			case logTagOmit:
+				f.Set(reflect.Zero(sf.Type))
+			default:
+				f.Set(maskCopy(f, depth+1))
+			}
+		}
+		return ret
+	}
+	return v
+}
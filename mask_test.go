@@ -0,0 +1,133 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type maskTestCard struct {
+	Number string `json:"number" log:"mask"`
+	CVC    int    `json:"cvc" log:"omit"`
+}
+
+type maskTestAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type maskTestUser struct {
+	maskTestAudit
+	Name     string                  `json:"name"`
+	Email    string                  `json:"email" log:"mask"`
+	Password string                  `json:"password" log:"omit"`
+	Note     string                  `json:"note,omitempty"`
+	Card     *maskTestCard           `json:"card"`
+	Cards    []maskTestCard          `json:"cards"`
+	ByName   map[string]maskTestCard `json:"by_name"`
+	Extra    interface{}             `json:"extra"`
+	Friend   *maskTestUser           `json:"friend,omitempty"`
+	secret   string                  `log:"mask"`
+}
+
+func newMaskTestUser() maskTestUser {
+	return maskTestUser{
+		maskTestAudit: maskTestAudit{CreatedAt: time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)},
+		Name:          "alice",
+		Email:         "alice@example.com",
+		Password:      "pass",
+		Card:          &maskTestCard{Number: "4111", CVC: 123},
+		Cards:         []maskTestCard{{Number: "5555", CVC: 456}},
+		ByName:        map[string]maskTestCard{"visa": {Number: "4242", CVC: 789}},
+		Extra:         maskTestCard{Number: "3782", CVC: 1},
+		Friend:        &maskTestUser{Name: "bob", Email: "bob@example.com", Password: "pass"},
+		secret:        "secret",
+	}
+}
+
+func TestObject(t *testing.T) {
+	user := newMaskTestUser()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []zap.Field{Object("user", user)})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"user":{"created_at":"2023-09-09T15:00:00Z","name":"alice","email":"***",`+
+		`"card":{"number":"***"},"cards":[{"number":"***"}],"by_name":{"visa":{"number":"***"}},`+
+		`"extra":{"number":"***"},`+
+		`"friend":{"created_at":"0001-01-01T00:00:00Z","name":"bob","email":"***","card":null,"cards":null,"by_name":null,"extra":null}}}`+"\n",
+		buf.String())
+
+	// The original value is not modified.
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "4111", user.Card.Number)
+
+	buf, err = enc.EncodeEntry(zapcore.Entry{}, []zap.Field{
+		Object("card", &user.Cards[0]), Object("id", 1), Object("nil", nil),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"card":{"number":"***"},"id":1,"nil":null}`+"\n", buf.String())
+}
+
+func TestObject_interface(t *testing.T) {
+	card := maskTestCard{Number: "4111", CVC: 123}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []zap.Field{
+		Object("map", map[string]interface{}{"card": card, "id": 1}),
+		Object("slice", []interface{}{&card, "x"}),
+		Object("struct", struct {
+			Value interface{} `json:"value"`
+		}{Value: card}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"map":{"card":{"number":"***"},"id":1},"slice":[{"number":"***"},"x"],`+
+		`"struct":{"value":{"number":"***"}}}`+"\n", buf.String())
+
+	// The values deeper than maskMaxDepth are masked instead of being logged as they are.
+	var deep interface{} = card
+	for i := 0; i < maskMaxDepth; i++ {
+		deep = []interface{}{deep}
+	}
+	buf, err = enc.EncodeEntry(zapcore.Entry{}, []zap.Field{Object("deep", deep)})
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "4111")
+	assert.Contains(t, buf.String(), MaskedValue)
+}
+
+func TestDump_mask(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	card := &maskTestCard{Number: "4111", CVC: 123}
+	assert.Equal(t, "{\n \"number\": \"***\",\n \"cvc\": 0\n}\n", dumpJSON(maskDumpValues([]interface{}{card})...))
+	assert.Equal(t, "number: '***'\ncvc: 0\n", dumpYAML(maskDumpValue(*card)))
+	assert.Equal(t, "4111", card.Number)
+	assert.Equal(t, "{\n \"card\": {\n  \"number\": \"***\",\n  \"cvc\": 0\n }\n}\n",
+		dumpJSON(maskDumpValue(map[string]interface{}{"card": card})))
+
+	var buf bytes.Buffer
+	SetColor(ColorNever)
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	user := newMaskTestUser()
+	l.dump(user)
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.NotContains(t, buf.String(), "pass")
+	assert.NotContains(t, buf.String(), "\"secret\"")
+	assert.Contains(t, buf.String(), "Email: (string) (len=3) \"***\"")
+	assert.Equal(t, "secret", user.secret)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_hasMaskTags(t *testing.T) {
+	type node struct {
+		Next *node
+		Card maskTestCard
+	}
+	assert.True(t, hasMaskTags(reflect.TypeOf(node{})))
+	assert.True(t, hasMaskTags(reflect.TypeOf(&node{})))
+	assert.False(t, hasMaskTags(reflect.TypeOf(dumpTestUser{})))
+	assert.False(t, hasMaskTags(reflect.TypeOf(time.Time{})))
+	assert.True(t, hasMaskTags(reflect.TypeOf(map[string]interface{}{})))
+}
@@ -0,0 +1,108 @@
+package zl
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// ElapsedKey is the field key of the elapsed time of Measure.
+	ElapsedKey = "elapsed"
+
+	// ThresholdKey is the field key of the threshold exceeded by the elapsed time of Measure. See: SetMeasureThreshold
+	ThresholdKey = "threshold"
+)
+
+var measureThreshold time.Duration
+
+// SetMeasureThreshold set the elapsed time over which the logs of Measure are written as WARN instead of INFO.
+// 0 (default) means the logs are always INFO.
+func SetMeasureThreshold(threshold time.Duration) {
+	measureThreshold = threshold
+}
+
+// Measure returns the function that writes the log of message with the ElapsedKey field of the time since Measure is called.
+// The console displays the elapsed time such as "1.234s". It is WARN if the time is over SetMeasureThreshold.
+// e.g. defer zl.Measure("LOAD_CONFIG")()
+func Measure(message string, fields ...zap.Field) func() {
+	start := clock.Now()
+	return func() {
+		level, fs := measureFields(nil, start, fields)
+		logger(message, level, fs).Log(level, message, fs...)
+	}
+}
+
+// MeasureCtx is the same as Measure, but the log is WARN with the error of ctx if ctx is done before it completes.
+// e.g. defer zl.MeasureCtx(ctx, "QUERY_USERS")()
+func MeasureCtx(ctx context.Context, message string, fields ...zap.Field) func() {
+	start := clock.Now()
+	return func() {
+		level, fs := measureFields(ctx, start, fields)
+		logger(message, level, fs).Log(level, message, fs...)
+	}
+}
+
+// Measure returns the function that writes the log of message with the elapsed time. See: Measure
+func (l *Logger) Measure(message string, fields ...zap.Field) func() {
+	start := clock.Now()
+	return func() {
+		level, fs := measureFields(nil, start, fields)
+		if !l.enabled(level) {
+			return
+		}
+		pfs := l.appendFields(fs)
+		l.logger(message, level, pfs.fields).Log(level, message, pfs.fields...)
+		pfs.free()
+	}
+}
+
+// MeasureCtx returns the function that writes the log of message with the elapsed time and the error of ctx. See: MeasureCtx
+func (l *Logger) MeasureCtx(ctx context.Context, message string, fields ...zap.Field) func() {
+	start := clock.Now()
+	return func() {
+		level, fs := measureFields(ctx, start, fields)
+		if !l.enabled(level) {
+			return
+		}
+		pfs := l.appendFields(fs)
+		l.logger(message, level, pfs.fields).Log(level, message, pfs.fields...)
+		pfs.free()
+	}
+}
+
+// measureFields returns the level and the fields of the log of Measure.
+func measureFields(ctx context.Context, start time.Time, fields []zap.Field) (zapcore.Level, []zap.Field) {
+	elapsed := clock.Now().Sub(start)
+	level := InfoLevel
+	fields = append(fields[:len(fields):len(fields)],
+		Console(formatDuration(elapsed)),
+		zap.Duration(ElapsedKey, elapsed),
+	)
+	if measureThreshold > 0 && elapsed > measureThreshold {
+		level = WarnLevel
+		fields = append(fields, zap.Duration(ThresholdKey, measureThreshold))
+	}
+	if ctx != nil && ctx.Err() != nil {
+		level = WarnLevel
+		fields = append(fields, zap.Error(context.Cause(ctx)))
+	}
+	return level, fields
+}
+
+// formatDuration returns d rounded to be readable such as "1.234s", "12.3ms" and "850µs".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second).String()
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	case d >= time.Microsecond:
+		return d.Round(time.Microsecond).String()
+	}
+	return d.String()
+}
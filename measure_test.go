@@ -0,0 +1,62 @@
+package zl
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMeasure(t *testing.T) {
+	obs := NewTestObserver(t)
+	c := &fixedClock{time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)}
+	SetClock(c)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	SetMeasureThreshold(time.Second)
+	Init()
+
+	done := Measure("LOAD_CONFIG", zap.String("path", "config.yml"))
+	c.t = c.t.Add(1234567 * time.Microsecond)
+	done()
+	done = New().Measure("QUICK")
+	c.t = c.t.Add(12345 * time.Microsecond)
+	done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done = MeasureCtx(ctx, "QUERY_USERS")
+	cancel()
+	done()
+	done = New().MeasureCtx(context.Background(), "QUERY_ITEMS")
+	done()
+
+	entries := obs.All()
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+	assert.Equal(t, WarnLevel, entries[0].Level)
+	assert.Equal(t, map[string]interface{}{
+		"path": "config.yml", consoleFieldDefault: "1.235s", ElapsedKey: 1234567 * time.Microsecond, ThresholdKey: time.Second,
+	}, entries[0].ContextMap())
+	assert.Equal(t, InfoLevel, entries[1].Level)
+	assert.Equal(t, "12.3ms", entries[1].ContextMap()[consoleFieldDefault])
+	assert.Equal(t, WarnLevel, entries[2].Level)
+	assert.Equal(t, "context canceled", entries[2].ContextMap()["error"])
+	assert.Equal(t, InfoLevel, entries[3].Level)
+	for _, e := range entries {
+		assert.True(t, strings.HasSuffix(e.Caller.File, "measure_test.go"), e.Caller.File)
+	}
+}
+
+func Test_formatDuration(t *testing.T) {
+	assert.Equal(t, "2m3s", formatDuration(123456*time.Millisecond))
+	assert.Equal(t, "1.235s", formatDuration(1234567*time.Microsecond))
+	assert.Equal(t, "12.3ms", formatDuration(12345*time.Microsecond))
+	assert.Equal(t, "850µs", formatDuration(850400*time.Nanosecond))
+	assert.Equal(t, "15ns", formatDuration(15))
+}
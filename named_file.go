@@ -0,0 +1,43 @@
+package zl
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var namedFiles = make(map[string]string)
+
+// SetNamedFile set the file to write the logs of the named logger to instead of the file set by SetRotateFileName.
+// The loggers whose names start with loggerName and a period also write to the file.
+// e.g. SetNamedFile("audit", "./log/audit.jsonl") applies to Named("audit") and Named("audit").Named("login").
+// The console output is shared with the other loggers, and the rotation settings are the same as the global ones.
+// The placeholders of SetRotateFileName can also be used.
+func SetNamedFile(loggerName, file string) {
+	namedFiles[loggerName] = file
+}
+
+// getNamedFile returns the file of the longest matching logger name.
+func getNamedFile(loggerName string) (string, bool) {
+	var matched, file string
+	for name, f := range namedFiles {
+		if loggerName != name && !strings.HasPrefix(loggerName, name+".") {
+			continue
+		}
+		if len(name) > len(matched) {
+			matched, file = name, f
+		}
+	}
+	return file, matched != ""
+}
+
+// newNamedLogger returns the zap logger that writes to the file set by SetNamedFile.
+// It returns nil if the file of loggerName is not set.
+func newNamedLogger(loggerName string) *zap.Logger {
+	file, ok := getNamedFile(loggerName)
+	if !ok {
+		return nil
+	}
+	syncers := getSyncers(func() *rotator { return getRotator(file) })
+	return newLoggerWithSyncers(encoderConfig, syncers).Named(loggerName)
+}
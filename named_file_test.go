@@ -0,0 +1,66 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetNamedFile(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetNamedFile("audit", filepath.Join(dir, "audit.jsonl"))
+	Init()
+
+	New().Named("audit").Info("AUDIT")
+	New().Named("audit").Named("login").Info("LOGIN")
+	New().Named("auditor").Info("AUDITOR")
+	New().Named("access").Info("ACCESS")
+	Info("GLOBAL")
+
+	b, err := os.ReadFile(filepath.Join(dir, "audit.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"INFO","logger":"audit","message":"AUDIT"}`+"\n"+
+			`{"severity":"INFO","logger":"audit.login","message":"LOGIN"}`+"\n",
+		string(b),
+	)
+	b, err = os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "AUDIT\"")
+	assert.Contains(t, string(b), `{"severity":"INFO","logger":"auditor","message":"AUDITOR"}`)
+	assert.Contains(t, string(b), `{"severity":"INFO","logger":"access","message":"ACCESS"}`)
+	assert.Contains(t, string(b), `{"severity":"INFO","message":"GLOBAL"}`)
+	assert.Len(t, rotators, 2)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_getNamedFile(t *testing.T) {
+	SetNamedFile("audit", "./log/audit.jsonl")
+	SetNamedFile("audit.login", "./log/login.jsonl")
+	tests := []struct {
+		name string
+		file string
+		ok   bool
+	}{
+		{"audit", "./log/audit.jsonl", true},
+		{"audit.logout", "./log/audit.jsonl", true},
+		{"audit.login", "./log/login.jsonl", true},
+		{"audit.login.failed", "./log/login.jsonl", true},
+		{"auditor", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, ok := getNamedFile(tt.name)
+			assert.Equal(t, tt.file, file)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,115 @@
+package zl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testObserverCore is the core that captures the logs for NewTestObserver.
+var testObserverCore zapcore.Core
+
+// TestObserver captures the logs in memory for the tests.
+// It is a wrapper of zaptest/observer. See: https://pkg.go.dev/go.uber.org/zap/zaptest/observer
+type TestObserver struct {
+	logs *observer.ObservedLogs
+}
+
+// NewTestObserver returns a TestObserver that captures the logs of the level set by SetLevel or higher.
+// The logs of the global functions and the loggers created by New are captured.
+// It must be called before Init, and ResetGlobalLoggerSettings is called by t.Cleanup.
+// The default log file and audit file are written to t.TempDir() instead of ./log.
+// e.g.
+//
+//	obs := zl.NewTestObserver(t)
+//	zl.Init()
+//	zl.Info("USER_CREATED", zap.Int("user_id", 42))
+//	obs.AssertLogged(t, zl.InfoLevel, "USER")
+//	assert.Equal(t, 1, obs.FilterField("user_id", 42).Len())
+func NewTestObserver(t testing.TB) *TestObserver {
+	t.Helper()
+	core, logs := observer.New(zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= minSeverityLevel()
+	}))
+	testObserverCore = core
+	if fileName == "" {
+		fileName = filepath.Join(t.TempDir(), filepath.Base(FileNameDefault))
+	}
+	if auditFileName == AuditFileNameDefault {
+		auditFileName = filepath.Join(t.TempDir(), filepath.Base(AuditFileNameDefault))
+	}
+	t.Cleanup(ResetGlobalLoggerSettings)
+	return &TestObserver{logs: logs}
+}
+
+// All returns the captured logs.
+func (o *TestObserver) All() []observer.LoggedEntry {
+	return o.logs.All()
+}
+
+// Len returns the number of the captured logs.
+func (o *TestObserver) Len() int {
+	return o.logs.Len()
+}
+
+// FilterField returns a TestObserver of the logs that have the field of key and value.
+// value is converted by zap.Any. e.g. obs.FilterField("user_id", 42)
+func (o *TestObserver) FilterField(key string, value interface{}) *TestObserver {
+	return &TestObserver{logs: o.logs.FilterField(zap.Any(key, value))}
+}
+
+// FilterFieldKey returns a TestObserver of the logs that have the field of key.
+func (o *TestObserver) FilterFieldKey(key string) *TestObserver {
+	return &TestObserver{logs: o.logs.FilterFieldKey(key)}
+}
+
+// FilterLevel returns a TestObserver of the logs of level.
+func (o *TestObserver) FilterLevel(level zapcore.Level) *TestObserver {
+	return &TestObserver{logs: o.logs.FilterLevelExact(level)}
+}
+
+// FilterMessage returns a TestObserver of the logs whose message contains msgSubstring.
+func (o *TestObserver) FilterMessage(msgSubstring string) *TestObserver {
+	return &TestObserver{logs: o.logs.FilterMessageSnippet(msgSubstring)}
+}
+
+// AssertLogged asserts that a log of level whose message contains msgSubstring is captured.
+func (o *TestObserver) AssertLogged(t testing.TB, level zapcore.Level, msgSubstring string) bool {
+	t.Helper()
+	if o.FilterLevel(level).FilterMessage(msgSubstring).Len() > 0 {
+		return true
+	}
+	t.Errorf("no %s log contains %q in the captured logs:\n%s", level.CapitalString(), msgSubstring, o)
+	return false
+}
+
+// AssertNotLogged asserts that no log of level whose message contains msgSubstring is captured.
+func (o *TestObserver) AssertNotLogged(t testing.TB, level zapcore.Level, msgSubstring string) bool {
+	t.Helper()
+	if o.FilterLevel(level).FilterMessage(msgSubstring).Len() == 0 {
+		return true
+	}
+	t.Errorf("a %s log contains %q in the captured logs:\n%s", level.CapitalString(), msgSubstring, o)
+	return false
+}
+
+// String returns the captured logs in lines of the level and the message.
+func (o *TestObserver) String() string {
+	var b strings.Builder
+	for _, e := range o.logs.All() {
+		b.WriteString(e.Level.CapitalString() + " " + e.Message + "\n")
+	}
+	return b.String()
+}
+
+// newTestObserverCore returns core teed with the core set by NewTestObserver.
+func newTestObserverCore(core zapcore.Core) zapcore.Core {
+	if testObserverCore == nil {
+		return core
+	}
+	return zapcore.NewTee(core, newRedactCore(testObserverCore))
+}
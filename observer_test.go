@@ -0,0 +1,64 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestNewTestObserver(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	obs := NewTestObserver(t)
+	SetOutput(ConsoleOutput)
+	SetStdout()
+	Init()
+
+	Debug("NOT_CAPTURED")
+	Info("USER_CREATED", zap.Int("user_id", 42))
+	New(zap.String("trace_id", "a")).InfoErr("USER_UPDATE_FAILED", errors.New("error"), zap.Int("user_id", 43))
+	Warn("CACHE_MISS")
+
+	assert.Equal(t, 3, obs.Len())
+	assert.True(t, obs.AssertLogged(t, InfoLevel, "USER_"))
+	assert.True(t, obs.AssertNotLogged(t, DebugLevel, "NOT_CAPTURED"))
+	assert.Equal(t, 1, obs.FilterField("user_id", 42).Len())
+	assert.Equal(t, 2, obs.FilterFieldKey("user_id").Len())
+	assert.Equal(t, 1, obs.FilterField("trace_id", "a").Len())
+	assert.Equal(t, 1, obs.FilterLevel(WarnLevel).Len())
+	assert.Equal(t, "USER_UPDATE_FAILED", obs.FilterMessage("FAILED").All()[0].Message)
+	assert.Equal(t, "INFO USER_CREATED\nINFO USER_UPDATE_FAILED\nWARN CACHE_MISS\n", obs.String())
+
+	ft := &fakeTB{TB: t}
+	assert.False(t, obs.AssertLogged(ft, ErrorLevel, "USER"))
+	assert.False(t, obs.AssertNotLogged(ft, WarnLevel, "CACHE"))
+	assert.Equal(t, 2, ft.errors)
+}
+
+func TestNewTestObserver_file(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	NewTestObserver(t)
+	SetOutput(FileOutput)
+	Init()
+	Info("USER_CREATED")
+	Sync()
+
+	assert.NotEqual(t, filepath.Clean(FileNameDefault), filepath.Clean(currentFileName()))
+	b, err := os.ReadFile(currentFileName())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "USER_CREATED")
+	assert.NotEqual(t, filepath.Clean(AuditFileNameDefault), filepath.Clean(auditFileName))
+}
+
+// fakeTB records the failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors int
+}
+
+func (f *fakeTB) Errorf(string, ...interface{}) {
+	f.errors++
+}
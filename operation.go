@@ -0,0 +1,151 @@
+package zl
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// OperationKey is the field key of the name of the operation. See: Begin
+	OperationKey = "operation"
+
+	// OperationIDKey is the field key of the id shared by the logs of the operation. See: Begin
+	OperationIDKey = "operation_id"
+
+	// StatusKey is the field key of the status of the operation. See: Begin
+	StatusKey = "status"
+
+	// The values of StatusKey.
+	StatusStarted   = "started"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Operation is an operation started by Begin.
+type Operation struct {
+	logger *Logger // logger is nil if it is started by the global Begin.
+	name   string
+	id     string
+	start  time.Time
+	fields []zap.Field
+	done   atomic.Bool
+}
+
+// Begin writes the INFO log of the start of the operation and returns the Operation to write the log of the end of it.
+// The logs have the OperationKey, OperationIDKey and StatusKey fields, and the log of the end has the ElapsedKey field.
+// The messages are the upper case of name with the suffixes such as "SYNC_USERS_STARTED" and "SYNC_USERS_FAILED".
+// The fields are added to all the logs of the operation. It is a lightweight alternative to the tracing for the batch jobs.
+// e.g.
+//
+//	op := zl.Begin("sync_users", zap.Int("batch", n))
+//	if err := syncUsers(); err != nil {
+//		op.Fail(err)
+//		return
+//	}
+//	op.Success(zap.Int("synced", count))
+func Begin(name string, fields ...zap.Field) *Operation {
+	op := newOperation(nil, name, fields)
+	fs := op.logFields(StatusStarted, nil)
+	logger(op.message(StatusStarted), InfoLevel, fs).Info(op.message(StatusStarted), fs...)
+	return op
+}
+
+// Begin writes the INFO log of the start of the operation with the Logger. See: Begin
+func (l *Logger) Begin(name string, fields ...zap.Field) *Operation {
+	op := newOperation(l, name, fields)
+	if l.enabled(InfoLevel) {
+		fs := l.appendFields(op.logFields(StatusStarted, nil))
+		l.logger(op.message(StatusStarted), InfoLevel, fs.fields).Info(op.message(StatusStarted), fs.fields...)
+		fs.free()
+	}
+	return op
+}
+
+func newOperation(l *Logger, name string, fields []zap.Field) *Operation {
+	return &Operation{
+		logger: l,
+		name:   name,
+		id:     newOperationID(),
+		start:  clock.Now(),
+		fields: append([]zap.Field{zap.String(OperationKey, name)}, fields...),
+	}
+}
+
+// ID returns the id of the operation. It can be added to the logs in the operation with OperationIDKey.
+func (op *Operation) ID() string {
+	return op.id
+}
+
+// Success writes the INFO log of the success of the operation with the elapsed time.
+// Only the first call of Success or Fail writes the log.
+func (op *Operation) Success(fields ...zap.Field) {
+	if !op.done.CompareAndSwap(false, true) {
+		return
+	}
+	msg, fs := op.message(StatusSucceeded), op.logFields(StatusSucceeded, fields)
+	if op.logger == nil {
+		logger(msg, InfoLevel, fs).Info(msg, fs...)
+		return
+	}
+	if op.logger.enabled(InfoLevel) {
+		pfs := op.logger.appendFields(fs)
+		op.logger.logger(msg, InfoLevel, pfs.fields).Info(msg, pfs.fields...)
+		pfs.free()
+	}
+}
+
+// Fail writes the ERROR log of the failure of the operation with err and the elapsed time.
+// Only the first call of Success or Fail writes the log.
+func (op *Operation) Fail(err error, fields ...zap.Field) {
+	if !op.done.CompareAndSwap(false, true) {
+		return
+	}
+	msg, fs := op.message(StatusFailed), op.logFields(StatusFailed, append(fields, errorFields(err)...))
+	if op.logger == nil {
+		loggerErr(msg, ErrorLevel, err, fs).Error(msg, appendFingerprint(fs, msg, err)...)
+		return
+	}
+	if op.logger.enabled(ErrorLevel) {
+		pfs := op.logger.appendFields(fs)
+		pfs.fields = appendFingerprint(pfs.fields, msg, err)
+		op.logger.loggerErr(msg, ErrorLevel, err, pfs.fields).Error(msg, pfs.fields...)
+		pfs.free()
+	}
+}
+
+func (op *Operation) message(status string) string {
+	var suffix string
+	switch status {
+	case StatusStarted:
+		suffix = "_STARTED"
+	case StatusSucceeded:
+		suffix = "_SUCCEEDED"
+	case StatusFailed:
+		suffix = "_FAILED"
+	}
+	return strings.ToUpper(op.name) + suffix
+}
+
+// logFields returns the fields of the log of the status.
+func (op *Operation) logFields(status string, fields []zap.Field) []zap.Field {
+	fs := make([]zap.Field, 0, len(op.fields)+len(fields)+4)
+	fs = append(fs, op.fields...)
+	fs = append(fs, zap.String(OperationIDKey, op.id), zap.String(StatusKey, status))
+	if status != StatusStarted {
+		elapsed := clock.Now().Sub(op.start)
+		fs = append(fs, Console(formatDuration(elapsed)), zap.Duration(ElapsedKey, elapsed))
+	}
+	return append(fs, fields...)
+}
+
+// newOperationID returns a random 64-bit hex id.
+func newOperationID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
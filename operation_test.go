@@ -0,0 +1,58 @@
+package zl
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBegin(t *testing.T) {
+	obs := NewTestObserver(t)
+	c := &fixedClock{time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)}
+	SetClock(c)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	op := Begin("sync_users", zap.Int("batch", 1))
+	c.t = c.t.Add(1500 * time.Millisecond)
+	op.Success(zap.Int("synced", 10))
+	op.Fail(errors.New("ignored"))
+
+	op2 := New(zap.String("job", "nightly")).Begin("import_items")
+	op2.Fail(errors.New("timeout"))
+	op2.Success()
+
+	entries := obs.All()
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+	assert.Equal(t, "SYNC_USERS_STARTED", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		OperationKey: "sync_users", "batch": int64(1), OperationIDKey: op.ID(), StatusKey: StatusStarted,
+	}, entries[0].ContextMap())
+	assert.Equal(t, "SYNC_USERS_SUCCEEDED", entries[1].Message)
+	assert.Equal(t, InfoLevel, entries[1].Level)
+	assert.Equal(t, map[string]interface{}{
+		OperationKey: "sync_users", "batch": int64(1), OperationIDKey: op.ID(), StatusKey: StatusSucceeded,
+		consoleFieldDefault: "1.5s", ElapsedKey: 1500 * time.Millisecond, "synced": int64(10),
+	}, entries[1].ContextMap())
+
+	assert.Equal(t, "IMPORT_ITEMS_STARTED", entries[2].Message)
+	assert.Equal(t, "nightly", entries[2].ContextMap()["job"])
+	assert.Equal(t, "IMPORT_ITEMS_FAILED", entries[3].Message)
+	assert.Equal(t, ErrorLevel, entries[3].Level)
+	assert.Equal(t, "timeout", entries[3].ContextMap()["error"])
+	assert.Equal(t, op2.ID(), entries[3].ContextMap()[OperationIDKey])
+	assert.NotEqual(t, op.ID(), op2.ID())
+	assert.Len(t, op.ID(), 16)
+	for _, e := range entries {
+		assert.True(t, strings.HasSuffix(e.Caller.File, "operation_test.go"), e.Caller.File)
+	}
+}
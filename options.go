@@ -3,14 +3,15 @@ package zl
 import (
 	"fmt"
 	"log"
-	"strings"
 
+	"github.com/samber/lo"
 	"go.uber.org/zap/zapcore"
 )
 
 // Key defines a commonly used field name for each log entry.
 // Each field defined in Key is output to all logs by default.
 // Unnecessary fields can also be excluded using SetOmitKeys.
+// Optional fields are output only when enabled using SetEnableKeys.
 //
 // Field names such as LevelKey and TimeKey are defined with reference to Google Cloud Logging.
 // See: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry
@@ -42,26 +43,66 @@ const (
 	HostnameKey Key = "hostname"
 	// PIDKey is the name of the field that outputs the process ID of the application.
 	PIDKey Key = "pid"
+	// ServiceKey is the name of the field that outputs the service name set by SetService.
+	// It is output only when the service name is set.
+	ServiceKey Key = "service"
+	// EnvironmentKey is the name of the field that outputs the environment set by SetEnvironment.
+	// It is output only when the environment is set.
+	EnvironmentKey Key = "env"
+
+	// Optional fields
+
+	// ContainerIDKey is the name of the field that outputs the ID of the container.
+	// It is detected from /proc/self/cgroup or /proc/self/mountinfo.
+	ContainerIDKey Key = "container.id"
+	// ContainerImageKey is the name of the field that outputs the image of the container.
+	// It is set by SetContainerImage or the CONTAINER_IMAGE environment variable.
+	ContainerImageKey Key = "container.image"
+	// VCSTimeKey is the name of the field that outputs the commit time embedded in the binary.
+	VCSTimeKey Key = "vcs_time"
+	// GoVersionKey is the name of the field that outputs the Go version used to build the binary.
+	GoVersionKey Key = "go_version"
+	// SeqKey is the name of the field that outputs the sequence number of the entry in the process.
+	// It is used to reconstruct the order of the entries reordered by the sinks or the collectors.
+	SeqKey Key = "seq"
+	// EntryIDKey is the name of the field that outputs the ULID of the entry to reference it uniquely.
+	EntryIDKey Key = "entry_id"
+	// UptimeKey is the name of the field that outputs the duration since Init.
+	UptimeKey Key = "uptime"
+	// GoroutineKey is the name of the field that outputs the id of the goroutine of the log. See: LabelGoroutine
+	GoroutineKey Key = "goroutine"
+	// UserKey is the name of the field that outputs the OS user of the process.
+	UserKey Key = "user"
+	// ExecutableKey is the name of the field that outputs the path of the executable.
+	ExecutableKey Key = "executable"
+	// ArgsKey is the name of the field that outputs the command-line arguments.
+	// The values of the flags such as "-password" and the keys set by SetRedactKeys are redacted.
+	ArgsKey Key = "args"
+	// PPIDKey is the name of the field that outputs the parent process ID.
+	PPIDKey Key = "ppid"
+	// PprofLabelsKey is the name of the field that outputs the pprof labels of the goroutine set by PprofDo and LabelGoroutine.
+	PprofLabelsKey Key = "pprof_labels"
 )
 
 // ErrorGroup is a group of ErrorLog.
 // It is used in prettyLogger's error report.
 type ErrorGroup struct {
 	ErrorLogs []*ErrorLog
-	Key       string
+	Key       string // Key is the fingerprint if the logs have it. See: SetErrorFingerprint
 }
 
 // ErrorLog is a log that contains error information.
 // It is used in prettyLogger's error report.
 type ErrorLog struct {
-	Severity   zapcore.Level `json:"severity"`
-	Timestamp  string        `json:"timestamp"`
-	Caller     string        `json:"caller"`
-	Message    string        `json:"message"`
-	Error      string        `json:"error"`
-	Stacktrace string        `json:"stacktrace"`
-	Pid        int           `json:"pid"`
-	Line       int
+	Severity    zapcore.Level `json:"severity"`
+	Timestamp   string        `json:"timestamp"`
+	Caller      string        `json:"caller"`
+	Message     string        `json:"message"`
+	Error       string        `json:"error"`
+	Stacktrace  string        `json:"stacktrace"`
+	Pid         int           `json:"pid"`
+	Fingerprint string        `json:"fingerprint"`
+	Line        int
 }
 
 const (
@@ -153,20 +194,33 @@ func SetLevelByString(levelStr string) {
 // SetRepositoryCallerEncoder is set CallerEncoder.
 // It set caller's source code's URL of the Repository that called.
 // It is used in the log output CallerKey field.
+// urlFormat is the URL of the files with the `%s` of revisionOrTag. ex. https://github.com/owner/repo/blob/%s
+// See SetRepositoryCaller for the repositories other than GitHub.
+// The empty arguments are detected from the build info and go.mod by DetectRepositoryRoot,
+// so SetRepositoryCallerEncoder("", "", "") works in most projects hosted on the known services.
+// Nothing is set if they are not detected. Set them manually in that case.
 func SetRepositoryCallerEncoder(urlFormat, revisionOrTag, srcRootDir string) {
+	if urlFormat == "" || revisionOrTag == "" || srcRootDir == "" {
+		detected, _ := DetectRepositoryRoot()
+		if revisionOrTag == "" {
+			revisionOrTag = detected.Revision
+		}
+		if srcRootDir == "" {
+			srcRootDir = detected.Dir
+		}
+		if urlFormat == "" {
+			detected.Revision, detected.Dir = revisionOrTag, srcRootDir
+			if detected.URL != "" && revisionOrTag != "" && srcRootDir != "" {
+				SetRepositoryCaller(detected)
+			}
+			return
+		}
+	}
 	if revisionOrTag == "" || srcRootDir == "" {
 		return
 	}
-	url := fmt.Sprintf(urlFormat, revisionOrTag)
-	callerEncoder = buildRepositoryCallerEncoder(srcRootDir, url)
-}
-
-func buildRepositoryCallerEncoder(dir, url string) zapcore.CallerEncoder {
-	return func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
-		enc.AppendString(
-			fmt.Sprintf("%v#L%v", strings.Replace(caller.File, dir, url, 1), caller.Line),
-		)
-	}
+	repositoryRoots = nil
+	addRepositoryRoot(srcRootDir, fmt.Sprintf(urlFormat, revisionOrTag), "#L%d")
 }
 
 // SetVersion `revisionOrTag` should be a git revision or a tag. ex. `e86b9a7` or `v1.0.0`.
@@ -176,9 +230,67 @@ func SetVersion(revisionOrTag string) {
 	version = revisionOrTag
 }
 
-// SetConsoleFields add the fields to be displayed in the console when PrettyOutput is used.
+// SetGitVersionFallback determines if GetVersion executes `git rev-parse --short HEAD`
+// when the version is neither set by SetVersion nor embedded in the binary.
+// It is disabled by default because git is not available in most containers.
+func SetGitVersionFallback(val bool) {
+	gitVersionFallback = val
+}
+
+// SetConsoleFields set the fields to be displayed in the console when PrettyOutput is used.
+// It replaces all the console fields including the default `console` field.
+// Use AddConsoleFields to keep the default field.
 func SetConsoleFields(fieldKey ...string) {
-	consoleFields = append(consoleFields, fieldKey...)
+	consoleFields = fieldKey
+	consoleFieldLevels = nil
+}
+
+// AddConsoleFields add the fields to be displayed in the console when PrettyOutput is used.
+// The fields are displayed in all levels.
+func AddConsoleFields(fieldKey ...string) {
+	for _, key := range fieldKey {
+		addConsoleField(key)
+		delete(consoleFieldLevels, key)
+	}
+}
+
+// AddLevelConsoleFields add the fields to be displayed in the console only at the level
+// when PrettyOutput is used. It can be called several times to display the fields at multiple levels.
+// e.g. Display `latency` at INFO and `query` only at DEBUG.
+//
+//	zl.AddLevelConsoleFields(zl.InfoLevel, "latency")
+//	zl.AddLevelConsoleFields(zl.DebugLevel, "query")
+func AddLevelConsoleFields(level zapcore.Level, fieldKey ...string) {
+	if consoleFieldLevels == nil {
+		consoleFieldLevels = map[string][]zapcore.Level{}
+	}
+	for _, key := range fieldKey {
+		addConsoleField(key)
+		if !lo.Contains(consoleFieldLevels[key], level) {
+			consoleFieldLevels[key] = append(consoleFieldLevels[key], level)
+		}
+	}
+}
+
+// RemoveConsoleFields remove the fields from the console fields.
+// The default `console` field can also be removed.
+func RemoveConsoleFields(fieldKey ...string) {
+	consoleFields = lo.Without(consoleFields, fieldKey...)
+	for _, key := range fieldKey {
+		delete(consoleFieldLevels, key)
+	}
+}
+
+func addConsoleField(key string) {
+	if !lo.Contains(consoleFields, key) {
+		consoleFields = append(consoleFields, key)
+	}
+}
+
+// isConsoleField reports whether the field of key is displayed in the console at the level.
+func isConsoleField(key string, level zapcore.Level) bool {
+	levels, ok := consoleFieldLevels[key]
+	return !ok || lo.Contains(levels, level)
 }
 
 // SetOmitKeys set fields to omit from default fields that used in each log.
@@ -186,6 +298,13 @@ func SetOmitKeys(key ...Key) {
 	omitKeys = key
 }
 
+// SetEnableKeys set optional fields to add to default fields that used in each log.
+// key can use (ContainerIDKey, ContainerImageKey, VCSTimeKey, GoVersionKey, SeqKey, EntryIDKey, UptimeKey, GoroutineKey,
+// UserKey, ExecutableKey, ArgsKey, PPIDKey, PprofLabelsKey).
+func SetEnableKeys(key ...Key) {
+	enableKeys = key
+}
+
 // SetFieldKey is changes the key of the default field.
 func SetFieldKey(key Key, val string) {
 	if key == "" || val == "" {
@@ -203,3 +322,17 @@ func SetStdout() {
 func SetSeparator(val string) {
 	separator = val
 }
+
+// SetExitFunc is changes the function to exit the process on Fatal, FatalErr and the signals of SyncWhenStop.
+// It can run the cleanup such as flushing the sinks and alerting before the exit,
+// or assert the Fatal behavior in the tests without exiting. Default is os.Exit.
+func SetExitFunc(fn func(code int)) {
+	exitFunc = fn
+}
+
+// SetQuietInit disables the INIT_LOGGER log of Init, the GOT_SIGNAL_* logs of SyncWhenStop
+// and the SHUTDOWN log of SyncOnShutdown.
+// They are useful in the development but not in the structured pipelines and the golden tests.
+func SetQuietInit(val bool) {
+	quietInit = val
+}
@@ -1,9 +1,14 @@
 package zl
 
 import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -87,3 +92,71 @@ func TestSetSeparator(t *testing.T) {
 	assert.Equal(t, ":", separator)
 	ResetGlobalLoggerSettings()
 }
+
+func TestSetConsoleFields(t *testing.T) {
+	AddConsoleFields("trace", "trace")
+	assert.Equal(t, []string{consoleFieldDefault, "trace"}, consoleFields)
+
+	SetConsoleFields("latency")
+	assert.Equal(t, []string{"latency"}, consoleFields)
+
+	RemoveConsoleFields("latency")
+	assert.Empty(t, consoleFields)
+	ResetGlobalLoggerSettings()
+}
+
+func TestAddLevelConsoleFields(t *testing.T) {
+	SetColor(ColorNever)
+	AddLevelConsoleFields(InfoLevel, "latency")
+	AddLevelConsoleFields(DebugLevel, "query")
+	AddLevelConsoleFields(WarnLevel, "latency")
+	assert.Equal(t, []string{consoleFieldDefault, "latency", "query"}, consoleFields)
+
+	var buf bytes.Buffer
+	severityLevel = DebugLevel
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	fields := []zap.Field{zap.String("latency", "10ms"), zap.String("query", "SELECT 1")}
+	l.log("DEBUG_MESSAGE", DebugLevel, fields)
+	l.log("INFO_MESSAGE", InfoLevel, fields)
+	l.log("WARN_MESSAGE", WarnLevel, fields)
+	l.log("ERROR_MESSAGE", ErrorLevel, fields)
+	assert.Equal(t, "DEBUG DEBUG_MESSAGE SELECT 1\n"+
+		"INFO INFO_MESSAGE 10ms\n"+
+		"WARN WARN_MESSAGE 10ms\n"+
+		"ERROR ERROR_MESSAGE\n", buf.String())
+
+	AddConsoleFields("query")
+	assert.True(t, isConsoleField("query", ErrorLevel))
+	RemoveConsoleFields("latency")
+	assert.NotContains(t, consoleFieldLevels, "latency")
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetExitFunc(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	var codes []int
+	SetExitFunc(func(code int) { codes = append(codes, code) })
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	Fatal("FATAL")
+	New().FatalErr("FATAL_ERR", errors.New("error"))
+	New().Named("named").Fatal("NAMED_FATAL")
+	assert.Equal(t, []int{1, 1, 1}, codes)
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetQuietInit(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetLevel(DebugLevel)
+	SetQuietInit(true)
+	obs := NewTestObserver(t)
+	Init()
+	Info("FIRST")
+
+	assert.Equal(t, 1, obs.Len())
+	obs.AssertNotLogged(t, DebugLevel, "INIT_LOGGER")
+}
@@ -0,0 +1,285 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultOTLPTimeout is the default timeout of a request of the OTLP exporter.
+const DefaultOTLPTimeout = 10 * time.Second
+
+// otlpScopeName is the instrumentation scope name of the exported LogRecords.
+const otlpScopeName = "github.com/nkmr-jp/zl"
+
+// OTLPConfig is the config of NewOTLPWriter.
+type OTLPConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP logs endpoint. e.g. http://localhost:4318/v1/logs
+	Endpoint string
+	// Headers are added to the requests. e.g. {"Authorization": "Bearer xxx"}
+	Headers map[string]string
+	// Timeout is the timeout of a request. Default is DefaultOTLPTimeout.
+	Timeout time.Duration
+	// Transport is the TLS and the proxy config.
+	Transport TransportConfig
+
+	// MaxBatchEntries, MaxBatchBytes and FlushInterval are the batching settings. See: NewBatchWriter
+	MaxBatchEntries int
+	MaxBatchBytes   int
+	FlushInterval   time.Duration
+}
+
+// NewOTLPWriter returns a BatchWriter that exports the json logs as OpenTelemetry LogRecords
+// over OTLP/HTTP with the JSON encoding. See: https://opentelemetry.io/docs/specs/otlp/
+// The Resource has service.name, service.version and deployment.environment
+// set by SetService, SetVersion and SetEnvironment.
+// The trace_id and span_id fields are exported as the trace context, and the other fields are exported as attributes.
+// OTLP/gRPC is provided by the github.com/nkmr-jp/zl/otlpgrpc module so that zl does not depend on gRPC.
+// e.g. zl.AddSink(zl.NewOTLPWriter(zl.OTLPConfig{Endpoint: "http://localhost:4318/v1/logs", FlushInterval: time.Second}))
+func NewOTLPWriter(config OTLPConfig) *BatchWriter {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultOTLPTimeout
+	}
+	// The error of the Transport config is returned by each export so that it is reported by Sync.
+	client, err := config.Transport.HTTPClient(config.Timeout)
+	return NewBatchWriter(func(batch [][]byte) error {
+		if err != nil {
+			return err
+		}
+		return exportOTLP(client, config, batch)
+	}, config.MaxBatchEntries, config.MaxBatchBytes, config.FlushInterval)
+}
+
+// MarshalOTLPLogs returns the OTLP/JSON encoding of ExportLogsServiceRequest of the json logs of batch,
+// which is the body of the requests of NewOTLPWriter. It is used by the exporters of the other protocols.
+// See: github.com/nkmr-jp/zl/otlpgrpc
+func MarshalOTLPLogs(batch [][]byte) ([]byte, error) {
+	return json.Marshal(newOTLPLogs(batch))
+}
+
+func exportOTLP(client *http.Client, config OTLPConfig, batch [][]byte) error {
+	body, err := MarshalOTLPLogs(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("zl: otlp export failed: %s", res.Status)
+	}
+	return nil
+}
+
+// The following types are the OTLP/HTTP JSON encoding of ExportLogsServiceRequest.
+type (
+	otlpLogs struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano         string         `json:"timeUnixNano,omitempty"`
+		ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+		SeverityNumber       int            `json:"severityNumber,omitempty"`
+		SeverityText         string         `json:"severityText,omitempty"`
+		Body                 otlpAnyValue   `json:"body"`
+		Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+		TraceID              string         `json:"traceId,omitempty"`
+		SpanID               string         `json:"spanId,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string        `json:"stringValue,omitempty"`
+		BoolValue   *bool          `json:"boolValue,omitempty"`
+		IntValue    *string        `json:"intValue,omitempty"` // int64 is a string in the JSON encoding.
+		DoubleValue *float64       `json:"doubleValue,omitempty"`
+		ArrayValue  *otlpArray     `json:"arrayValue,omitempty"`
+		KvlistValue *otlpKeyValues `json:"kvlistValue,omitempty"`
+	}
+	otlpArray struct {
+		Values []otlpAnyValue `json:"values"`
+	}
+	otlpKeyValues struct {
+		Values []otlpKeyValue `json:"values"`
+	}
+)
+
+func newOTLPLogs(batch [][]byte) otlpLogs {
+	observed := strconv.FormatInt(clock.Now().UnixNano(), 10)
+	records := make([]otlpLogRecord, 0, len(batch))
+	for _, line := range batch {
+		records = append(records, newOTLPLogRecord(line, observed))
+	}
+	return otlpLogs{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: otlpResourceAttributes()},
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: otlpScopeName},
+			LogRecords: records,
+		}},
+	}}}
+}
+
+func otlpResourceAttributes() []otlpKeyValue {
+	name := serviceName
+	if name == "" {
+		name = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	attrs := []otlpKeyValue{{Key: "service.name", Value: otlpString(name)}}
+	if ver := GetVersion(); ver != "" {
+		attrs = append(attrs, otlpKeyValue{Key: "service.version", Value: otlpString(ver)})
+	}
+	if environment != "" {
+		attrs = append(attrs, otlpKeyValue{Key: "deployment.environment", Value: otlpString(environment)})
+	}
+	return attrs
+}
+
+// newOTLPLogRecord converts the json log to a LogRecord.
+// The log is exported as the body if it is not a json object.
+func newOTLPLogRecord(line []byte, observed string) otlpLogRecord {
+	record := otlpLogRecord{ObservedTimeUnixNano: observed}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var entry map[string]interface{}
+	if err := dec.Decode(&entry); err != nil {
+		record.Body = otlpString(string(bytes.TrimSpace(line)))
+		return record
+	}
+
+	if s, ok := entry[fieldKey(TimeKey)].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			record.TimeUnixNano = strconv.FormatInt(t.UnixNano(), 10)
+			delete(entry, fieldKey(TimeKey))
+		}
+	}
+	if s, ok := entry[fieldKey(LevelKey)].(string); ok {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(s)); err == nil {
+			record.SeverityNumber = otlpSeverityNumber(level)
+			record.SeverityText = s
+			delete(entry, fieldKey(LevelKey))
+		}
+	}
+	if v, ok := entry[fieldKey(MessageKey)]; ok {
+		record.Body = otlpValue(v)
+		delete(entry, fieldKey(MessageKey))
+	}
+	record.TraceID = takeHexID(entry, "trace_id", 16)
+	record.SpanID = takeHexID(entry, "span_id", 8)
+	record.Attributes = otlpKeyValueList(entry)
+	return record
+}
+
+// takeHexID removes and returns the id of key if it is a hex string of size bytes.
+func takeHexID(entry map[string]interface{}, key string, size int) string {
+	s, ok := entry[key].(string)
+	if !ok {
+		return ""
+	}
+	if b, err := hex.DecodeString(s); err != nil || len(b) != size {
+		return ""
+	}
+	delete(entry, key)
+	return s
+}
+
+// otlpSeverityNumber returns the SeverityNumber of level.
+// See: https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber
+func otlpSeverityNumber(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 19
+	case zapcore.PanicLevel:
+		return 21
+	case zapcore.FatalLevel:
+		return 21
+	}
+	return 0
+}
+
+func otlpKeyValueList(m map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ret := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		ret = append(ret, otlpKeyValue{Key: k, Value: otlpValue(m[k])})
+	}
+	return ret
+}
+
+func otlpValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return otlpString(val)
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case json.Number:
+		if _, err := strconv.ParseInt(val.String(), 10, 64); err == nil {
+			s := val.String()
+			return otlpAnyValue{IntValue: &s}
+		}
+		f, _ := val.Float64()
+		return otlpAnyValue{DoubleValue: &f}
+	case []interface{}:
+		values := make([]otlpAnyValue, 0, len(val))
+		for i := range val {
+			values = append(values, otlpValue(val[i]))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArray{Values: values}}
+	case map[string]interface{}:
+		return otlpAnyValue{KvlistValue: &otlpKeyValues{Values: otlpKeyValueList(val)}}
+	}
+	return otlpAnyValue{}
+}
+
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
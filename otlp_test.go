@@ -0,0 +1,111 @@
+package zl
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestNewOTLPWriter(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &body))
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	ResetGlobalLoggerSettings()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(CallerKey, FunctionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetService("api")
+	SetVersion("v1.0.0")
+	SetClock(&fixedClock{time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)})
+	AddSink(NewOTLPWriter(OTLPConfig{
+		Endpoint:        server.URL,
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+		MaxBatchEntries: 2,
+	}))
+	Init()
+
+	Info("USER_CREATED", zap.Int("user_id", 42), zap.Bool("admin", true),
+		zap.String("trace_id", "0af7651916cd43dd8448eb211c80319c"), zap.String("span_id", "b7ad6b7169203331"))
+	WarnErr("RETRY", io.EOF, zap.Float64("ratio", 0.5), zap.Strings("tags", []string{"a"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, bodies, 1)
+	expected := `{"resourceLogs":[{
+		"resource":{"attributes":[
+			{"key":"service.name","value":{"stringValue":"api"}},
+			{"key":"service.version","value":{"stringValue":"v1.0.0"}}
+		]},
+		"scopeLogs":[{"scope":{"name":"github.com/nkmr-jp/zl"},"logRecords":[
+			{
+				"timeUnixNano":"1694271600000000000","observedTimeUnixNano":"1694271600000000000",
+				"severityNumber":9,"severityText":"INFO","body":{"stringValue":"USER_CREATED"},
+				"attributes":[
+					{"key":"admin","value":{"boolValue":true}},
+					{"key":"service","value":{"stringValue":"api"}},
+					{"key":"user_id","value":{"intValue":"42"}},
+					{"key":"version","value":{"stringValue":"v1.0.0"}}
+				],
+				"traceId":"0af7651916cd43dd8448eb211c80319c","spanId":"b7ad6b7169203331"
+			},
+			{
+				"timeUnixNano":"1694271600000000000","observedTimeUnixNano":"1694271600000000000",
+				"severityNumber":13,"severityText":"WARN","body":{"stringValue":"RETRY"},
+				"attributes":[
+					{"key":"error","value":{"stringValue":"EOF"}},
+					{"key":"ratio","value":{"doubleValue":0.5}},
+					{"key":"service","value":{"stringValue":"api"}},
+					{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"}]}}},
+					{"key":"version","value":{"stringValue":"v1.0.0"}}
+				]
+			}
+		]}]
+	}]}`
+	b, err := json.Marshal(bodies[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(b))
+	ResetGlobalLoggerSettings()
+}
+
+func TestNewOTLPWriter_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ResetGlobalLoggerSettings()
+	w := NewOTLPWriter(OTLPConfig{Endpoint: server.URL})
+	_, err := w.Write([]byte("not json\n"))
+	assert.NoError(t, err)
+	assert.EqualError(t, w.Close(), "zl: otlp export failed: 503 Service Unavailable")
+	assert.Equal(t, uint64(1), Stats().BatchDropped)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_newOTLPLogRecord(t *testing.T) {
+	record := newOTLPLogRecord([]byte("not json\n"), "1")
+	b, err := json.Marshal(record)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"observedTimeUnixNano":"1","body":{"stringValue":"not json"}}`, string(b))
+}
@@ -0,0 +1,154 @@
+// Package otlpgrpc provides the exporter of the logs of zl over OTLP/gRPC.
+// It is a separate module so that zl does not depend on gRPC. See: zl.NewOTLPWriter for OTLP/HTTP.
+// e.g. zl.AddSink(otlpgrpc.NewWriter(otlpgrpc.Config{Endpoint: "localhost:4317", Insecure: true, FlushInterval: time.Second}))
+package otlpgrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Config is the config of NewWriter.
+type Config struct {
+	// Endpoint is the host and the port of the OTLP/gRPC receiver. e.g. localhost:4317
+	Endpoint string
+	// Insecure disables TLS. It is used for the collectors on the same host or in the same pod.
+	Insecure bool
+	// Headers are added to the metadata of the requests. e.g. {"authorization": "Bearer xxx"}
+	Headers map[string]string
+	// Timeout is the timeout of a request. Default is zl.DefaultOTLPTimeout.
+	Timeout time.Duration
+	// Transport is the TLS config. The proxy settings are not used.
+	Transport zl.TransportConfig
+
+	// MaxBatchEntries, MaxBatchBytes and FlushInterval are the batching settings. See: zl.NewBatchWriter
+	MaxBatchEntries int
+	MaxBatchBytes   int
+	FlushInterval   time.Duration
+}
+
+// Writer is the sink that exports the json logs as OpenTelemetry LogRecords over OTLP/gRPC.
+// The LogRecords are the same as zl.NewOTLPWriter.
+type Writer struct {
+	*zl.BatchWriter
+	conn *grpc.ClientConn
+}
+
+// NewWriter returns the Writer of config. The connection is established by the first export.
+func NewWriter(config Config) *Writer {
+	if config.Timeout <= 0 {
+		config.Timeout = zl.DefaultOTLPTimeout
+	}
+	w := &Writer{}
+	// The error of the config is returned by each export so that it is reported by Sync.
+	conn, err := dial(config)
+	w.conn = conn
+	client := collogs.NewLogsServiceClient(conn)
+	w.BatchWriter = zl.NewBatchWriter(func(batch [][]byte) error {
+		if err != nil {
+			return err
+		}
+		return export(client, config, batch)
+	}, config.MaxBatchEntries, config.MaxBatchBytes, config.FlushInterval)
+	return w
+}
+
+// Close exports the buffered logs and closes the connection.
+func (w *Writer) Close() error {
+	err := w.BatchWriter.Close()
+	if w.conn != nil {
+		if err2 := w.conn.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+func dial(config Config) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if !config.Insecure {
+		conf, err := config.Transport.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(conf)
+	}
+	return grpc.Dial(config.Endpoint, grpc.WithTransportCredentials(creds))
+}
+
+func export(client collogs.LogsServiceClient, config Config, batch [][]byte) error {
+	req, err := newRequest(batch)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	if len(config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(config.Headers))
+	}
+	res, err := client.Export(ctx, req)
+	if err != nil {
+		return fmt.Errorf("zl: otlp export failed: %w", err)
+	}
+	if p := res.GetPartialSuccess(); p != nil && p.GetRejectedLogRecords() > 0 {
+		return fmt.Errorf("zl: otlp export rejected %d logs: %s", p.GetRejectedLogRecords(), p.GetErrorMessage())
+	}
+	return nil
+}
+
+// newRequest converts the OTLP/JSON encoding of zl.MarshalOTLPLogs to the protobuf message.
+// The trace ids and the span ids are converted from hex to base64
+// because they are hex in OTLP/JSON unlike the protobuf JSON mapping.
+func newRequest(batch [][]byte) (*collogs.ExportLogsServiceRequest, error) {
+	b, err := zl.MarshalOTLPLogs(batch)
+	if err != nil {
+		return nil, err
+	}
+	var logs map[string]interface{}
+	if err := json.Unmarshal(b, &logs); err != nil {
+		return nil, err
+	}
+	for _, rl := range list(logs["resourceLogs"]) {
+		for _, sl := range list(rl["scopeLogs"]) {
+			for _, record := range list(sl["logRecords"]) {
+				for _, key := range []string{"traceId", "spanId"} {
+					if s, ok := record[key].(string); ok {
+						id, err := hex.DecodeString(s)
+						if err != nil {
+							return nil, err
+						}
+						record[key] = base64.StdEncoding.EncodeToString(id)
+					}
+				}
+			}
+		}
+	}
+	if b, err = json.Marshal(logs); err != nil {
+		return nil, err
+	}
+	req := &collogs.ExportLogsServiceRequest{}
+	return req, protojson.Unmarshal(b, req)
+}
+
+func list(v interface{}) []map[string]interface{} {
+	values, _ := v.([]interface{})
+	ret := make([]map[string]interface{}, 0, len(values))
+	for _, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,88 @@
+package otlpgrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/stretchr/testify/assert"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type logsServer struct {
+	collogs.UnimplementedLogsServiceServer
+	mu       sync.Mutex
+	requests []*collogs.ExportLogsServiceRequest
+	auth     []string
+}
+
+func (s *logsServer) Export(ctx context.Context, req *collogs.ExportLogsServiceRequest) (*collogs.ExportLogsServiceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.auth = append(s.auth, md.Get("authorization")...)
+	return &collogs.ExportLogsServiceResponse{}, nil
+}
+
+func startServer(t *testing.T) (*logsServer, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	s := &logsServer{}
+	collogs.RegisterLogsServiceServer(srv, s)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+	return s, ln.Addr().String()
+}
+
+func TestNewWriter(t *testing.T) {
+	s, addr := startServer(t)
+	w := NewWriter(Config{Endpoint: addr, Insecure: true, Headers: map[string]string{"authorization": "Bearer xxx"}})
+
+	_, err := w.Write([]byte(`{"severity":"ERROR","timestamp":"2023-09-09T00:00:00Z","message":"FAILED",` +
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","user_id":42}` + "\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Sync())
+	assert.NoError(t, w.Close())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !assert.Len(t, s.requests, 1) {
+		return
+	}
+	assert.Equal(t, []string{"Bearer xxx"}, s.auth)
+	records := s.requests[0].GetResourceLogs()[0].GetScopeLogs()[0].GetLogRecords()
+	if assert.Len(t, records, 1) {
+		r := records[0]
+		assert.Equal(t, "FAILED", r.GetBody().GetStringValue())
+		assert.Equal(t, "ERROR", r.GetSeverityText())
+		assert.EqualValues(t, 17, r.GetSeverityNumber())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hexString(r.GetTraceId()))
+		assert.Equal(t, "00f067aa0ba902b7", hexString(r.GetSpanId()))
+		assert.Equal(t, "user_id", r.GetAttributes()[0].GetKey())
+		assert.Equal(t, int64(42), r.GetAttributes()[0].GetValue().GetIntValue())
+	}
+	assert.Equal(t, "service.name", s.requests[0].GetResourceLogs()[0].GetResource().GetAttributes()[0].GetKey())
+}
+
+func TestNewWriter_error(t *testing.T) {
+	w := NewWriter(Config{Endpoint: "localhost:4317", Transport: zlTransportWithMissingCA()})
+	_, err := w.Write([]byte(`{"message":"X"}` + "\n"))
+	assert.NoError(t, err)
+	assert.Error(t, w.Sync())
+	_ = w.Close()
+}
+
+func hexString(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func zlTransportWithMissingCA() zl.TransportConfig {
+	return zl.TransportConfig{CAFile: "testdata/missing.pem"}
+}
@@ -0,0 +1,75 @@
+package zl
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	consoleLevel *zapcore.Level
+	fileLevel    *zapcore.Level
+)
+
+// SetConsoleLevel set the level of the pretty console of PrettyOutput instead of the level set by SetLevel.
+// It is used with SetFileLevel to keep the console readable while the file has the full details.
+// e.g.
+//
+//	zl.SetConsoleLevel(zl.InfoLevel)
+//	zl.SetFileLevel(zl.DebugLevel)
+func SetConsoleLevel(level zapcore.Level) {
+	consoleLevel = &level
+}
+
+// SetFileLevel set the level of the json logs of PrettyOutput, which are written to the file and the sinks,
+// instead of the level set by SetLevel. See: SetConsoleLevel
+func SetFileLevel(level zapcore.Level) {
+	fileLevel = &level
+}
+
+// consoleSeverityLevel returns the level of the pretty console.
+func consoleSeverityLevel() zapcore.Level {
+	if outputType == PrettyOutput && consoleLevel != nil {
+		return *consoleLevel
+	}
+	return severityLevel
+}
+
+// fileSeverityLevel returns the level of the json logs.
+func fileSeverityLevel() zapcore.Level {
+	if outputType == PrettyOutput && fileLevel != nil {
+		return *fileLevel
+	}
+	return severityLevel
+}
+
+// baseSeverityLevel returns the lower level of the pretty console and the json logs.
+func baseSeverityLevel() zapcore.Level {
+	if c, f := consoleSeverityLevel(), fileSeverityLevel(); c < f {
+		return c
+	}
+	return fileSeverityLevel()
+}
+
+// fileLevelCore is a zapcore.Core that checks the level set by SetFileLevel.
+type fileLevelCore struct {
+	zapcore.Core
+}
+
+func newFileLevelCore(core zapcore.Core) zapcore.Core {
+	if fileSeverityLevel() <= baseSeverityLevel() {
+		return core
+	}
+	return &fileLevelCore{Core: core}
+}
+
+func (c *fileLevelCore) With(fields []zap.Field) zapcore.Core {
+	return &fileLevelCore{Core: c.Core.With(fields)}
+}
+
+// Check delegates to the wrapped core so that the level is checked before the wrapped cores add themselves.
+func (c *fileLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < zapcore.DPanicLevel && ent.Level < callerSeverityLevel(fileSeverityLevel()) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,82 @@
+package zl
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func initOutputLevel(t *testing.T) (console *bytes.Buffer, file string) {
+	t.Helper()
+	file = filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(PrettyOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	SetRotateFileName(file)
+	Init()
+	console = &bytes.Buffer{}
+	pretty.Logger = log.New(console, "", 0)
+	pretty.noColor = true
+	return console, file
+}
+
+func readFileMessages(t *testing.T, file string) string {
+	t.Helper()
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if e, ok := (Filter{Level: DebugLevel}).match([]byte(line)); ok && e.Message != "INIT_LOGGER" {
+			messages = append(messages, e.Level.CapitalString()+" "+e.Message)
+		}
+	}
+	return strings.Join(messages, ",")
+}
+
+func TestSetConsoleLevel(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetConsoleLevel(InfoLevel)
+	SetFileLevel(DebugLevel)
+	console, file := initOutputLevel(t)
+
+	Debug("DEBUG_MESSAGE")
+	New().Info("INFO_MESSAGE")
+	Sync()
+
+	assert.Equal(t, "INFO INFO_MESSAGE\n", console.String())
+	assert.Equal(t, "DEBUG DEBUG_MESSAGE,INFO INFO_MESSAGE", readFileMessages(t, file))
+}
+
+func TestSetFileLevel(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetLevel(DebugLevel)
+	SetFileLevel(WarnLevel)
+	console, file := initOutputLevel(t)
+
+	Debug("DEBUG_MESSAGE")
+	Info("INFO_MESSAGE")
+	New().Warn("WARN_MESSAGE")
+	Sync()
+
+	assert.Equal(t, "DEBUG DEBUG_MESSAGE\nINFO INFO_MESSAGE\nWARN WARN_MESSAGE\n", console.String())
+	assert.Equal(t, "WARN WARN_MESSAGE", readFileMessages(t, file))
+}
+
+func Test_consoleSeverityLevel(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetOutput(FileOutput)
+	SetLevel(WarnLevel)
+	SetConsoleLevel(DebugLevel)
+	SetFileLevel(DebugLevel)
+	assert.Equal(t, WarnLevel, consoleSeverityLevel())
+	assert.Equal(t, WarnLevel, fileSeverityLevel())
+	SetOutput(PrettyOutput)
+	assert.Equal(t, DebugLevel, consoleSeverityLevel())
+	assert.Equal(t, DebugLevel, baseSeverityLevel())
+}
@@ -0,0 +1,114 @@
+package zl
+
+import (
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// packageLevel is the level of the logs called from the packages of path.
+type packageLevel struct {
+	path  string
+	level zapcore.Level
+}
+
+var packageLevels []packageLevel
+
+// SetPackageLevel set the level of the logs called from the package of pkgPath and its sub packages
+// instead of the level set by SetLevel. The longest path is used if the caller matches multiple paths.
+// It is finer-grained than the named loggers, and works for the code that does not use Named.
+// e.g.
+//
+//	zl.SetLevel(zl.InfoLevel)
+//	zl.SetPackageLevel("github.com/acme/app/internal/payments", zl.DebugLevel)
+func SetPackageLevel(pkgPath string, level zapcore.Level) {
+	pkgPath = strings.TrimSuffix(pkgPath, "/")
+	for i := range packageLevels {
+		if packageLevels[i].path == pkgPath {
+			packageLevels[i].level = level
+			return
+		}
+	}
+	packageLevels = append(packageLevels, packageLevel{path: pkgPath, level: level})
+}
+
+// minSeverityLevel returns the lowest level of SetLevel, SetConsoleLevel, SetFileLevel and SetPackageLevel.
+// The cores are enabled for it, and the packageLevelCore checks the level of each caller.
+func minSeverityLevel() zapcore.Level {
+	level := baseSeverityLevel()
+	for i := range packageLevels {
+		if packageLevels[i].level < level {
+			level = packageLevels[i].level
+		}
+	}
+	return level
+}
+
+// severityEnabled reports whether the pretty log of level is written
+// according to SetLevel, SetConsoleLevel and SetPackageLevel.
+func severityEnabled(level zapcore.Level) bool {
+	if level < minSeverityLevel() {
+		return false
+	}
+	return level >= callerSeverityLevel(consoleSeverityLevel())
+}
+
+// callerSeverityLevel returns the level of the package of the caller of zl, or defaultLevel if it is not set.
+func callerSeverityLevel(defaultLevel zapcore.Level) zapcore.Level {
+	if len(packageLevels) == 0 {
+		return defaultLevel
+	}
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !isFilteredFrame(frame.Function) {
+			return packageSeverityLevel(frame.Function, defaultLevel)
+		}
+		if !more {
+			return defaultLevel
+		}
+	}
+}
+
+// packageSeverityLevel returns the level of the package of the function name such as "github.com/acme/app.(*T).Run",
+// or defaultLevel if it is not set.
+func packageSeverityLevel(function string, defaultLevel zapcore.Level) zapcore.Level {
+	level, matched := defaultLevel, -1
+	for i := range packageLevels {
+		p := packageLevels[i].path
+		if !strings.HasPrefix(function, p) || len(function) > len(p) && function[len(p)] != '.' && function[len(p)] != '/' {
+			continue
+		}
+		if matched < 0 || len(p) > len(packageLevels[matched].path) {
+			level, matched = packageLevels[i].level, i
+		}
+	}
+	return level
+}
+
+// packageLevelCore is a zapcore.Core that checks the level of the package of the caller.
+type packageLevelCore struct {
+	zapcore.Core
+}
+
+func newPackageLevelCore(core zapcore.Core) zapcore.Core {
+	if len(packageLevels) == 0 {
+		return core
+	}
+	return &packageLevelCore{Core: core}
+}
+
+func (c *packageLevelCore) With(fields []zap.Field) zapcore.Core {
+	return &packageLevelCore{Core: c.Core.With(fields)}
+}
+
+// Check delegates to the wrapped core so that a Tee checks the level of each core.
+func (c *packageLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < zapcore.DPanicLevel && ent.Level < callerSeverityLevel(baseSeverityLevel()) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,60 @@
+package zl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetPackageLevel(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(WarnLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	// The tests of this package are called by the testing package because the frames of zl are skipped.
+	SetPackageLevel("testing", DebugLevel)
+	Init()
+
+	Debug("DEBUG")
+	New().Info("LOGGER_INFO")
+	assert.Equal(t, 1, obs.FilterMessage("DEBUG").Len())
+	assert.Equal(t, 1, obs.FilterMessage("LOGGER_INFO").Len())
+
+	ResetGlobalLoggerSettings()
+	obs = NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetPackageLevel("testing", ErrorLevel)
+	Init()
+
+	Warn("WARN")
+	Error("ERROR")
+	assert.Equal(t, 0, obs.FilterMessage("WARN").Len())
+	assert.Equal(t, 1, obs.FilterMessage("ERROR").Len())
+}
+
+func Test_packageSeverityLevel(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetLevel(InfoLevel)
+	SetPackageLevel("github.com/acme/app/internal/payments/", DebugLevel)
+	SetPackageLevel("github.com/acme/app/internal/payments/stripe", WarnLevel)
+	SetPackageLevel("github.com/acme/app", ErrorLevel)
+
+	tests := map[string]zapcore.Level{
+		"github.com/acme/app/internal/payments.(*Service).Charge": DebugLevel,
+		"github.com/acme/app/internal/payments/paypal.Pay":        DebugLevel,
+		"github.com/acme/app/internal/payments/stripe.Pay":        WarnLevel,
+		"github.com/acme/app/internal/paymentsv2.Pay":             ErrorLevel,
+		"github.com/acme/app.main":                                ErrorLevel,
+		"github.com/acme/application.main":                        InfoLevel,
+		"main.main":                                               InfoLevel,
+	}
+	for function, want := range tests {
+		assert.Equal(t, want, packageSeverityLevel(function, severityLevel), function)
+	}
+	assert.Equal(t, DebugLevel, minSeverityLevel())
+	ResetGlobalLoggerSettings()
+}
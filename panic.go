@@ -0,0 +1,125 @@
+package zl
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// PanicTypeKey is the field key of the type of the panic value. See: PanicErr
+const PanicTypeKey = "panic_type"
+
+// PanicError is the error of a recovered panic value. See: ErrFromPanic
+type PanicError struct {
+	Value interface{} // Value is the recovered panic value.
+	pcs   []uintptr
+}
+
+// Error is return the panic value formatted as "panic: value".
+// The errors, the strings and the fmt.Stringers are formatted by their messages,
+// and the other values are formatted with their types such as `main.Item{ID:1}`.
+func (e *PanicError) Error() string {
+	switch v := e.Value.(type) {
+	case error:
+		return "panic: " + v.Error()
+	case string:
+		return "panic: " + v
+	case fmt.Stringer:
+		return "panic: " + v.String()
+	}
+	return fmt.Sprintf("panic: %#v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Format writes the stack of the panicking goroutine after the message with %+v.
+// It is written to the errorVerbose field of the logs.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	_, _ = io.WriteString(s, e.Error())
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.Stack())
+	}
+}
+
+// Stack returns the stack of the panicking goroutine from the function that recovered the panic.
+func (e *PanicError) Stack() string {
+	var b []byte
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		frame, more := frames.Next()
+		b = append(b, '\n')
+		b = append(b, frame.Function...)
+		b = append(b, "\n\t"...)
+		b = append(b, frame.File...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(frame.Line), 10)
+		if !more {
+			break
+		}
+	}
+	return string(b)
+}
+
+// ErrFromPanic returns the PanicError of the value returned by recover() with the goroutine stack.
+// It must be called in the deferred function that calls recover(). It returns nil if recovered is nil.
+// e.g.
+//
+//	defer func() {
+//		if err := zl.ErrFromPanic(recover()); err != nil {
+//			zl.ErrorErr("PANIC_RECOVERED", err)
+//		}
+//	}()
+func ErrFromPanic(recovered interface{}) error {
+	if err := errFromPanic(recovered, 3); err != nil {
+		return err
+	}
+	return nil
+}
+
+func errFromPanic(recovered interface{}, skip int) *PanicError {
+	if recovered == nil {
+		return nil
+	}
+	if err, ok := recovered.(*PanicError); ok {
+		return err
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	return &PanicError{Value: recovered, pcs: pcs[:n]}
+}
+
+// PanicErr write error log of the panic value returned by recover() with the goroutine stack.
+// It must be called in the deferred function. It does nothing if recovered is nil.
+// e.g.
+//
+//	defer func() { zl.PanicErr("PANIC_RECOVERED", recover()) }()
+func PanicErr(message string, recovered interface{}, fields ...zap.Field) {
+	err := errFromPanic(recovered, 3)
+	if err == nil {
+		return
+	}
+	fields = append(fields, zap.String(PanicTypeKey, fmt.Sprintf("%T", err.Value)))
+	loggerErr(message, ErrorLevel, err, fields).Error(message, appendFingerprint(append(fields, errorFields(err)...), message, err)...)
+}
+
+// PanicErr write error log of the panic value returned by recover() with the goroutine stack. See: PanicErr
+func (l *Logger) PanicErr(message string, recovered interface{}, fields ...zap.Field) {
+	err := errFromPanic(recovered, 3)
+	if err == nil || !l.enabled(ErrorLevel) {
+		return
+	}
+	fields = append(fields, zap.String(PanicTypeKey, fmt.Sprintf("%T", err.Value)))
+	fs := l.appendFields(fields, errorFields(err)...)
+	fs.fields = appendFingerprint(fs.fields, message, err)
+	l.loggerErr(message, ErrorLevel, err, fs.fields).Error(message, fs.fields...)
+	fs.free()
+}
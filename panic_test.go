@@ -0,0 +1,74 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type panicTestItem struct {
+	ID int
+}
+
+func TestErrFromPanic(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{base, "panic: boom"},
+		{"boom", "panic: boom"},
+		{panicTestItem{ID: 1}, "panic: zl.panicTestItem{ID:1}"},
+		{42, "panic: 42"},
+	}
+	for _, tt := range tests {
+		err := func() (err error) {
+			defer func() { err = ErrFromPanic(recover()) }()
+			panic(tt.value)
+		}()
+		assert.EqualError(t, err, tt.want)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "panic_test.go:")
+		assert.Contains(t, fmt.Sprintf("%+v", err), "TestErrFromPanic")
+	}
+
+	err := func() (err error) {
+		defer func() { err = ErrFromPanic(recover()) }()
+		panic(base)
+	}()
+	assert.ErrorIs(t, err, base)
+	assert.Nil(t, ErrFromPanic(nil))
+	assert.Same(t, err, ErrFromPanic(err))
+}
+
+func TestPanicErr(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	func() {
+		defer func() { PanicErr("PANIC_RECOVERED", recover()) }()
+		panic(panicTestItem{ID: 1})
+	}()
+	func() {
+		defer func() { New().PanicErr("LOGGER_PANIC_RECOVERED", recover()) }()
+		panic("boom")
+	}()
+	func() {
+		defer func() { PanicErr("NOT_PANICKED", recover()) }()
+	}()
+
+	entries := obs.FilterFieldKey(PanicTypeKey).All()
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, ErrorLevel, entries[0].Level)
+	assert.Equal(t, "zl.panicTestItem", entries[0].ContextMap()[PanicTypeKey])
+	assert.Equal(t, "panic: zl.panicTestItem{ID:1}", entries[0].ContextMap()["error"])
+	assert.Contains(t, entries[0].ContextMap()["errorVerbose"], "panic_test.go:")
+	assert.Equal(t, "string", entries[1].ContextMap()[PanicTypeKey])
+	assert.Equal(t, "LOGGER_PANIC_RECOVERED", entries[1].Message)
+}
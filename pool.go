@@ -0,0 +1,99 @@
+package zl
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+)
+
+// maxPooledFields is the capacity limit of the field slices returned to the pool,
+// so that an occasional log with many fields does not keep a large slice alive.
+const maxPooledFields = 64
+
+var (
+	// fieldsPool reuses the field slices built by the Logger methods.
+	fieldsPool = sync.Pool{
+		New: func() interface{} {
+			return &fieldSlice{fields: make([]zap.Field, 0, 16)}
+		},
+	}
+
+	// bufferPool reuses the buffers to format the pretty logs.
+	bufferPool = buffer.NewPool()
+
+	// requestLoggerPool reuses the Loggers returned by Request.
+	requestLoggerPool = sync.Pool{
+		New: func() interface{} {
+			return &Logger{fields: make([]zap.Field, 0, 8)}
+		},
+	}
+)
+
+// fieldSlice is a pooled slice of the fields.
+// The fields are only valid until free is called, so zap cores must not retain them.
+// zapcore.Core.With copies the fields, and Write encodes them before returning.
+type fieldSlice struct {
+	fields []zap.Field
+}
+
+// appendFields returns a pooled slice of fields, extra and the default fields of the logger.
+// If fields has a Namespace, the others are put before fields so that they are not nested in it.
+func (l *Logger) appendFields(fields []zap.Field, extra ...zap.Field) *fieldSlice {
+	fs := fieldsPool.Get().(*fieldSlice)
+	if hasNamespace(fields) {
+		fs.fields = append(append(append(fs.fields[:0], l.fields...), extra...), fields...)
+		return fs
+	}
+	fs.fields = append(append(append(fs.fields[:0], fields...), extra...), l.fields...)
+	return fs
+}
+
+// free returns the slice to the pool.
+func (fs *fieldSlice) free() {
+	if cap(fs.fields) > maxPooledFields {
+		return
+	}
+	for i := range fs.fields {
+		fs.fields[i] = zap.Field{} // Drop the references to the values.
+	}
+	fs.fields = fs.fields[:0]
+	fieldsPool.Put(fs)
+}
+
+// Request returns a pooled Logger with the default fields, and the func to return it to the pool.
+// It is the same as New except that the Logger shares the logger of Init instead of creating a new one,
+// so that the per-request loggers of high-QPS servers do not allocate every time.
+// The Logger and the Loggers derived from it (e.g. by Named) must not be used after release is called.
+// e.g.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		log, release := zl.Request(zap.String("request_id", r.Header.Get("X-Request-Id")))
+//		defer release()
+//		log.Info("REQUEST")
+//	}
+func Request(fields ...zap.Field) (l *Logger, release func()) {
+	checkInit()
+	l = requestLoggerPool.Get().(*Logger)
+	l.pretty = pretty
+	l.zapLogger = zapLogger
+	l.fields = append(l.fields[:0], fields...)
+	if l.release == nil {
+		// The func is created once for each pooled Logger.
+		l.release = func() { releaseRequestLogger(l) }
+	}
+	return l, l.release
+}
+
+// releaseRequestLogger returns the Logger of Request to the pool.
+func releaseRequestLogger(l *Logger) {
+	if cap(l.fields) > maxPooledFields {
+		l.fields = make([]zap.Field, 0, 8)
+	}
+	for i := range l.fields {
+		l.fields[i] = zap.Field{} // Drop the references to the values.
+	}
+	l.fields = l.fields[:0]
+	l.pretty, l.zapLogger, l.tail = nil, nil, nil
+	requestLoggerPool.Put(l)
+}
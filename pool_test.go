@@ -0,0 +1,139 @@
+package zl
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogger_appendFields(t *testing.T) {
+	l := &Logger{fields: []zap.Field{zap.String("default", "d")}}
+	fs := l.appendFields([]zap.Field{zap.Int("n", 1)}, zap.Bool("extra", true))
+	assert.Equal(t, []zap.Field{zap.Int("n", 1), zap.Bool("extra", true), zap.String("default", "d")}, fs.fields)
+	fs.free()
+	assert.Len(t, fs.fields, 0)
+
+	fs = l.appendFields(make([]zap.Field, maxPooledFields+1))
+	fs.free()
+	assert.Len(t, fs.fields, maxPooledFields+2, "the large slice is not returned to the pool")
+}
+
+func TestRequest(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	obs := NewTestObserver(t)
+	Init()
+
+	l, release := Request(zap.String("request_id", "r1"))
+	l.Info("REQUEST", zap.Int("n", 1))
+	release()
+	assert.Len(t, l.fields, 0, "the fields are cleared by release")
+	assert.Nil(t, l.zapLogger)
+
+	l, release = Request(zap.String("request_id", "r2"))
+	l.Info("REQUEST")
+	release()
+
+	logs := obs.FilterMessage("REQUEST").All()
+	assert.Len(t, logs, 2)
+	assert.Equal(t, "r1", logs[0].ContextMap()["request_id"])
+	assert.Equal(t, int64(1), logs[0].ContextMap()["n"])
+	assert.Equal(t, "r2", logs[1].ContextMap()["request_id"])
+	assert.NotContains(t, logs[1].ContextMap(), "n")
+}
+
+func initBenchmark(b *testing.B, output Output, level zapcore.Level) {
+	b.Helper()
+	ResetGlobalLoggerSettings()
+	SetOutput(output)
+	SetLevel(level)
+	SetRotateFileName(filepath.Join(b.TempDir(), "app.jsonl"))
+	SetStdout()
+	Init()
+	if pretty != nil {
+		pretty.Logger = log.New(io.Discard, "", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	b.Cleanup(ResetGlobalLoggerSettings)
+}
+
+func BenchmarkLogger_Info(b *testing.B) {
+	initBenchmark(b, FileOutput, InfoLevel)
+	l := New(zap.String("request_id", "abc"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("BENCHMARK", zap.Int("i", i))
+	}
+}
+
+func BenchmarkLogger_Info_disabled(b *testing.B) {
+	initBenchmark(b, FileOutput, WarnLevel)
+	l := New(zap.String("request_id", "abc"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("BENCHMARK", zap.Int("i", i))
+	}
+}
+
+func BenchmarkLogger_InfoErr(b *testing.B) {
+	initBenchmark(b, FileOutput, InfoLevel)
+	l := New(zap.String("request_id", "abc"))
+	err := io.EOF
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.InfoErr("BENCHMARK", err, zap.Int("i", i))
+	}
+}
+
+func BenchmarkLogger_Info_pretty(b *testing.B) {
+	initBenchmark(b, PrettyOutput, InfoLevel)
+	l := New(zap.String("request_id", "abc"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("BENCHMARK", zap.Int("i", i))
+	}
+}
+
+func BenchmarkConsoleEncoding(b *testing.B) {
+	for _, encoding := range []ConsoleEncoding{ConsoleEncodingJSON, ConsoleEncodingText} {
+		b.Run(encoding.String(), func(b *testing.B) {
+			ResetGlobalLoggerSettings()
+			SetConsoleEncoding(encoding)
+			b.Cleanup(ResetGlobalLoggerSettings)
+			logger := newLoggerWithSyncers(newEncoderConfig(), []zapcore.WriteSyncer{consoleWriteSyncer{io.Discard}})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info("BENCHMARK", zap.Int("i", i))
+			}
+		})
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	initBenchmark(b, FileOutput, InfoLevel)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New()
+	}
+}
+
+func BenchmarkRequest(b *testing.B) {
+	initBenchmark(b, FileOutput, InfoLevel)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l, release := Request(zap.String("request_id", "r1"))
+		release()
+		_ = l
+	}
+}
@@ -0,0 +1,82 @@
+package zl
+
+import (
+	"context"
+	"runtime/pprof"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// goroutinePprofLabels has the pprof labels of the goroutine ids set by PprofDo and LabelGoroutine.
+var goroutinePprofLabels sync.Map
+
+// pprofLabels is the sorted key and value pairs of the pprof labels.
+type pprofLabels [][2]string
+
+// MarshalLogObject adds the labels to enc.
+func (p pprofLabels) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, kv := range p {
+		enc.AddString(kv[0], kv[1])
+	}
+	return nil
+}
+
+// PprofDo is a wrapper of pprof.Do that calls f with the pprof labels of ctx and labels,
+// and adds the labels to the PprofLabelsKey field of the logs of the goroutine in f if PprofLabelsKey is enabled by SetEnableKeys.
+// The logs of the goroutines started in f do not have the labels. Use PprofLabelFields for them.
+// e.g.
+//
+//	zl.PprofDo(ctx, pprof.Labels("handler", "users"), func(ctx context.Context) {
+//		zl.Info("LIST_USERS") // {"message":"LIST_USERS","pprof_labels":{"handler":"users"}}
+//	})
+func PprofDo(ctx context.Context, labels pprof.LabelSet, f func(context.Context)) {
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		defer setGoroutinePprofLabels(goroutineID(), contextPprofLabels(ctx))()
+		f(ctx)
+	})
+}
+
+// PprofLabelFields returns the PprofLabelsKey field of the pprof labels of ctx. It returns nil if ctx has no labels.
+// e.g. zl.Info("WORK", zl.PprofLabelFields(ctx)...)
+func PprofLabelFields(ctx context.Context) []zap.Field {
+	labels := contextPprofLabels(ctx)
+	if len(labels) == 0 {
+		return nil
+	}
+	return []zap.Field{zap.Object(string(PprofLabelsKey), labels)}
+}
+
+func contextPprofLabels(ctx context.Context) pprofLabels {
+	var labels pprofLabels
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels = append(labels, [2]string{key, value})
+		return true
+	})
+	sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+	return labels
+}
+
+// setGoroutinePprofLabels set the pprof labels of the goroutine id, and returns the function to restore the previous ones.
+func setGoroutinePprofLabels(id uint64, labels pprofLabels) func() {
+	prev, loaded := goroutinePprofLabels.Load(id)
+	goroutinePprofLabels.Store(id, labels)
+	return func() {
+		if loaded {
+			goroutinePprofLabels.Store(id, prev)
+		} else {
+			goroutinePprofLabels.Delete(id)
+		}
+	}
+}
+
+// goroutinePprofLabel returns the pprof labels of the goroutine id.
+func goroutinePprofLabel(id uint64) (pprofLabels, bool) {
+	v, ok := goroutinePprofLabels.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(pprofLabels), true
+}
@@ -0,0 +1,68 @@
+package zl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPprofDo(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetEnableKeys(PprofLabelsKey)
+	Init()
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("tenant", "acme"))
+	PprofDo(ctx, pprof.Labels("handler", "users"), func(ctx context.Context) {
+		Info("OUTER")
+		PprofDo(ctx, pprof.Labels("step", "query"), func(context.Context) {
+			Info("INNER")
+		})
+		Info("OUTER_AFTER")
+	})
+	Info("NO_LABELS")
+	func() {
+		defer LabelGoroutine("worker-1")()
+		Info("LABELED")
+	}()
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	got := map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		got[entry["message"].(string)] = entry[string(PprofLabelsKey)]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"OUTER":       map[string]interface{}{"handler": "users", "tenant": "acme"},
+		"INNER":       map[string]interface{}{"handler": "users", "step": "query", "tenant": "acme"},
+		"OUTER_AFTER": map[string]interface{}{"handler": "users", "tenant": "acme"},
+		"NO_LABELS":   nil,
+		"LABELED":     map[string]interface{}{"goroutine": "worker-1"},
+	}, got)
+	_, ok := goroutinePprofLabel(goroutineID())
+	assert.False(t, ok, "the labels are removed")
+}
+
+func TestPprofLabelFields(t *testing.T) {
+	assert.Nil(t, PprofLabelFields(context.Background()))
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("b", "2", "a", "1"))
+	fields := PprofLabelFields(ctx)
+	assert.Len(t, fields, 1)
+	enc := zapcore.NewMapObjectEncoder()
+	fields[0].AddTo(enc)
+	assert.Equal(t, map[string]interface{}{"a": "1", "b": "2"}, enc.Fields[string(PprofLabelsKey)])
+}
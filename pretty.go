@@ -8,10 +8,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	au "github.com/logrusorgru/aurora/v4"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
@@ -25,6 +26,8 @@ import (
 type prettyLogger struct {
 	Logger      *log.Logger // Logger is used to output colored logs.
 	internalLog *log.Logger // internalLog is used to output internal errors.
+	noColor     bool        // noColor removes the colors from the output. See: SetColor
+	name        string      // name is the name of the zap logger. See: Logger.Named
 }
 
 func newPrettyLogger(out, err io.Writer) *prettyLogger {
@@ -38,65 +41,104 @@ func newPrettyLogger(out, err io.Writer) *prettyLogger {
 	return &prettyLogger{
 		Logger:      l,
 		internalLog: log.New(err, "[INTERNAL ERROR] ", log.Ldate|log.Ltime|log.Lshortfile),
+		noColor:     !colorEnabled(out),
 	}
 }
 
 func (l *prettyLogger) log(msg string, level zapcore.Level, fields []zap.Field) {
-	if outputType != PrettyOutput || level < severityLevel {
+	level = overrideLevel(msg, level)
+	if outputType != PrettyOutput || !severityEnabled(level) {
 		return
 	}
-	err := l.Logger.Output(4,
-		l.coloredLevel(level).String()+" "+l.coloredMsg(msg, level, fields),
-	)
-	if err != nil {
+	if err := l.print(5, level, msg, nil, false, fields); err != nil {
 		l.internalLog.Println(err)
 	}
 }
 
 func (l *prettyLogger) logWithError(msg string, level zapcore.Level, err error, fields []zap.Field) {
-	if outputType != PrettyOutput || level < severityLevel {
+	level = overrideLevel(msg, level)
+	if outputType != PrettyOutput || !severityEnabled(level) {
 		return
 	}
-	err2 := l.Logger.Output(
-		4,
-		l.coloredLevel(level).String()+" "+l.coloredMsg(
-			fmt.Sprintf("%s%s%s", msg, separator, au.Magenta(fmt.Sprintf("%v", err))),
-			level, fields,
-		),
-	)
-	if err2 != nil {
+	if err2 := l.print(5, level, msg, err, true, fields); err2 != nil {
 		l.internalLog.Println(err2)
 	}
 }
 
-func (l *prettyLogger) coloredMsg(msg string, level zapcore.Level, fields []zap.Field) string {
-	var fieldMsg string
+// print writes a log line. calldepth is the same as log.Logger.Output.
+func (l *prettyLogger) print(
+	calldepth int, level zapcore.Level, msg string, err error, hasErr bool, fields []zap.Field,
+) error {
+	if prettySummary {
+		summary.add(level, msg)
+	}
+	entry, body, err, fields, ok := l.format(level, msg, err, hasErr, fields)
+	if !ok {
+		return nil
+	}
+	entry.stacktrace = l.stacktraceMsg(level, err, fields)
+	return l.output(calldepth, body+entry.stacktrace, entry)
+}
+
+// format returns the entry and the line of the log without the timestamp, the caller and the stacktrace,
+// and err and fields scrubbed and redacted for the stacktrace.
+// ok is false if the log is filtered by SetConsoleFilter.
+func (l *prettyLogger) format(
+	level zapcore.Level, msg string, err error, hasErr bool, fields []zap.Field,
+) (entry *PrettyEntry, body string, _ error, _ []zap.Field, ok bool) {
+	fields = redactFields(fields)
+	if s, ok := scrubString(msg); ok {
+		msg = s
+	}
+	if hasErr && err != nil {
+		if _, ok := scrubString(err.Error()); ok {
+			err = scrubbedError{err: err}
+		}
+	}
+	if !matchConsoleFilter(level, l.name, msg, fields) {
+		return nil, "", err, fields, false
+	}
+	consoleMsg := l.consoleMsg(level, fields)
+	fieldsMsg := l.fieldsMsg(fields, hasErr)
+	entry = &PrettyEntry{
+		Level:   l.coloredLevel(level).String(),
+		Logger:  l.name,
+		Message: msg,
+		Console: strings.TrimPrefix(consoleMsg, separator),
+		Fields:  strings.TrimPrefix(fieldsMsg, separator),
+	}
+	if hasErr {
+		entry.Error = au.Colorize(fmt.Sprintf("%v", err), theme.ErrorMessage).String()
+		msg = fmt.Sprintf("%s%s%s", msg, separator, entry.Error)
+	}
+	if level == DebugLevel {
+		entry.Message = au.Colorize(entry.Message, theme.DebugMessage).String()
+		entry.Console = au.Colorize(entry.Console, theme.DebugMessage).String()
+	}
+	return entry, entry.Level + " " + l.coloredMsg(msg, level, consoleMsg) + fieldsMsg, err, fields, true
+}
+
+func (l *prettyLogger) coloredMsg(msg string, level zapcore.Level, consoleMsg string) string {
 	if level == DebugLevel {
-		msg = au.Faint(msg).String()
-		fieldMsg = au.Faint(l.consoleMsg(fields)).String()
-	} else {
-		fieldMsg = l.consoleMsg(fields)
+		msg = au.Colorize(msg, theme.DebugMessage).String()
+		consoleMsg = au.Colorize(consoleMsg, theme.DebugMessage).String()
 	}
-	return fmt.Sprintf("%s%s", msg, fieldMsg)
+	return msg + consoleMsg
 }
 
-func (l *prettyLogger) consoleMsg(fields []zap.Field) string {
+func (l *prettyLogger) consoleMsg(level zapcore.Level, fields []zap.Field) string {
 	var ret string
 	var consoles []string
 	for i := range fields {
 		for i2 := range consoleFields {
-			if consoleFields[i2] == fields[i].Key {
+			if consoleFields[i2] == fields[i].Key && isConsoleField(fields[i].Key, level) {
 				var val string
 				if fields[i].Type == zapcore.StringType {
 					val = fields[i].String
 				} else {
 					val = strconv.Itoa(int(fields[i].Integer))
 				}
-				if i2%2 == 0 {
-					consoles = append(consoles, au.Cyan(val).String())
-				} else {
-					consoles = append(consoles, au.Blue(val).String())
-				}
+				consoles = append(consoles, au.Colorize(val, theme.consoleColor(i2)).String())
 			}
 		}
 	}
@@ -108,18 +150,95 @@ func (l *prettyLogger) consoleMsg(fields []zap.Field) string {
 
 func (l *prettyLogger) coloredLevel(level zapcore.Level) au.Value {
 	switch level {
-	case FatalLevel:
-		return au.Red(level.CapitalString())
-	case ErrorLevel:
-		return au.Red(level.CapitalString())
-	case WarnLevel:
-		return au.Yellow(level.CapitalString())
-	case InfoLevel:
-		return au.BrightBlue(level.CapitalString())
-	case DebugLevel:
-		return au.BrightBlack(level.CapitalString())
-	}
-	return au.BrightBlack("")
+	case FatalLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel:
+		str := level.CapitalString()
+		if icon, ok := theme.Icons[level]; ok {
+			str = icon + " " + str
+		}
+		return au.Colorize(str, theme.levelColor(level))
+	}
+	return au.Colorize("", theme.levelColor(level))
+}
+
+// output writes the line in the same format as log.Logger.Output.
+// The timestamp is formatted by itself so that it can be styled by the theme.
+// If the layout is set by SetPrettyLayout and entry is not nil, the line is formatted by the layout.
+// calldepth is the same as log.Logger.Output.
+func (l *prettyLogger) output(calldepth int, s string, entry *PrettyEntry) error {
+	var caller string
+	if flags := l.Logger.Flags(); flags&(log.Lshortfile|log.Llongfile) != 0 {
+		_, file, line, ok := runtime.Caller(calldepth)
+		if !ok {
+			file = "???"
+			line = 0
+		}
+		caller = formatCaller(file, line, flags)
+	}
+	return l.outputAt(clock.Now(), caller, s, entry)
+}
+
+// outputAt writes the line of the log at t called from caller. See: output
+func (l *prettyLogger) outputAt(t time.Time, caller string, s string, entry *PrettyEntry) error {
+	var timestamp string
+	if flags := l.Logger.Flags(); flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 && !t.IsZero() {
+		timestamp = au.Colorize(l.formatTime(t, flags), theme.Timestamp).String()
+	}
+
+	if prettyLayout != nil && entry != nil {
+		entry.Time = timestamp
+		entry.Caller = caller
+		return l.writeLine(l.formatLayout(entry), l.Logger.Prefix()+caller+"\x00"+s)
+	}
+
+	b := bufferPool.Get()
+	defer b.Free()
+	b.AppendString(l.Logger.Prefix())
+	if timestamp != "" {
+		b.AppendString(timestamp)
+		b.AppendByte(' ')
+	}
+	if caller != "" {
+		b.AppendString(caller)
+		b.AppendString(": ")
+	}
+	b.AppendString(s)
+	if !strings.HasSuffix(s, "\n") {
+		b.AppendByte('\n')
+	}
+	if entry == nil {
+		return l.write(b.String())
+	}
+	return l.writeLine(b.String(), l.Logger.Prefix()+caller+"\x00"+s)
+}
+
+// write writes str to the console without colors if colors are disabled.
+func (l *prettyLogger) write(str string) error {
+	if l.noColor {
+		str = stripColor(str)
+	}
+	return writeConsole(l.Logger.Writer(), str)
+}
+
+func (l *prettyLogger) formatTime(t time.Time, flags int) string {
+	if timeMode != TimeWallClock {
+		return relativeTime(t)
+	}
+	if flags&log.LUTC != 0 {
+		t = t.UTC()
+	}
+	if theme.TimeFormat != "" {
+		return t.Format(theme.TimeFormat)
+	}
+	var layout []string
+	if flags&log.Ldate != 0 {
+		layout = append(layout, "2006/01/02")
+	}
+	if flags&log.Lmicroseconds != 0 {
+		layout = append(layout, "15:04:05.000000")
+	} else if flags&log.Ltime != 0 {
+		layout = append(layout, "15:04:05")
+	}
+	return t.Format(strings.Join(layout, " "))
 }
 
 // showErrorReport writes the colored error report to console.
@@ -173,6 +292,9 @@ func (l *prettyLogger) scanStackTraces(fp *os.File, pidValue int) (int, string,
 		key = fmt.Sprintf("severity:%s,message:%s,caller:%s,error:%s",
 			errorLog.Severity, errorLog.Message, errorLog.Error, errorLog.Caller,
 		)
+		if errorLog.Fingerprint != "" {
+			key = errorLog.Fingerprint
+		}
 		errorLog.Line = ln
 		for i := range groups {
 			if groups[i].Key == key {
@@ -189,7 +311,7 @@ func (l *prettyLogger) scanStackTraces(fp *os.File, pidValue int) (int, string,
 	}
 
 	for i, v := range groups {
-		traces += l.fmtStackTrace(i, len(v.ErrorLogs), v.ErrorLogs[len(v.ErrorLogs)-1])
+		traces += l.fmtStackTrace(i, v)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -207,15 +329,15 @@ func (l *prettyLogger) printTraces(count int, traces string, pidValue int) error
 	head += fmt.Sprintf("%v: %v\n", l.attr("ErrorCount"), count)
 	head += fmt.Sprintf("%v: %v\n", l.attr("PID"), pidValue)
 	output := fmt.Sprintf("\n\n%s\n\n%s", head, traces)
-	if _, err := fmt.Fprint(l.Logger.Writer(), output); err != nil {
-		return err
-	}
-	return nil
+	return l.write(output)
 }
 
-func (l *prettyLogger) fmtStackTrace(num, count int, el *ErrorLog) string {
+// fmtStackTrace formats the last log of group with the number of the logs.
+func (l *prettyLogger) fmtStackTrace(num int, group *ErrorGroup) string {
 	var output, logFileAbsPath, errorCount string
-	logFileAbsPath, err := filepath.Abs(fileName)
+	count := len(group.ErrorLogs)
+	first, el := group.ErrorLogs[0], group.ErrorLogs[count-1]
+	logFileAbsPath, err := filepath.Abs(currentFileName())
 	if err != nil {
 		return ""
 	}
@@ -229,10 +351,16 @@ func (l *prettyLogger) fmtStackTrace(num, count int, el *ErrorLog) string {
 		l.coloredLevel(el.Severity).String(),
 		el.Message,
 		separator,
-		au.Magenta(el.Error),
+		au.Colorize(el.Error, theme.ErrorMessage),
 		errorCount,
 	)
-	if el.Timestamp != "" {
+	if el.Fingerprint != "" {
+		output += fmt.Sprintf("%v:\t%v\n", l.attr("Fingerprint"), el.Fingerprint)
+	}
+	if count > 1 && el.Timestamp != "" {
+		output += fmt.Sprintf("%v:\t%v\n", l.attr("FirstSeen"), first.Timestamp)
+		output += fmt.Sprintf("%v:\t%v\n", l.attr("LastSeen"), el.Timestamp)
+	} else if el.Timestamp != "" {
 		output += fmt.Sprintf("%v:\t%v\n", l.attr("Timestamp"), el.Timestamp)
 	}
 	output += fmt.Sprintf("%v:\t%v:%v\n",
@@ -249,15 +377,20 @@ func (l *prettyLogger) fmtStackTrace(num, count int, el *ErrorLog) string {
 }
 
 func (l *prettyLogger) attr(str string) string {
-	return "  " + au.Cyan(str).String()
+	return "  " + au.Colorize(str, theme.Attr).String()
 }
 
 func (l *prettyLogger) dump(a ...interface{}) {
+	l.dumpString(4, dumpConfig.Sdump(maskDumpValues(a)...))
+}
+
+// dumpString writes the formatted dump s. calldepth is the same as log.Logger.Output.
+func (l *prettyLogger) dumpString(calldepth int, s string) {
 	if outputType != PrettyOutput {
 		return
 	}
-	err := l.Logger.Output(3,
-		au.Red("DUMP").Bold().String()+" "+spew.Sdump(a...),
+	err := l.output(calldepth,
+		au.Red(dumpMessage).Bold().String()+" "+s, nil,
 	)
 	if err != nil {
 		l.internalLog.Println(err)
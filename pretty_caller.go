@@ -0,0 +1,81 @@
+package zl
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	au "github.com/logrusorgru/aurora/v4"
+)
+
+// CallerLink is the display mode of the caller in the console log when PrettyOutput is used.
+type CallerLink int
+
+const (
+	// CallerLinkNone displays the caller as `file:line`.
+	// It is Default setting.
+	CallerLinkNone CallerLink = iota
+
+	// CallerLinkOSC8 displays the caller as `file:line` with an OSC 8 hyperlink.
+	// The link is the source code's URL of the repository when SetRepositoryCallerEncoder is used,
+	// otherwise `file://` URL of the source file.
+	// See: https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
+	CallerLinkOSC8
+
+	// CallerLinkVSCode displays the caller as `file:line` with an OSC 8 hyperlink
+	// that opens the source line in Visual Studio Code. ex. `vscode://file/path/to/main.go:10`
+	CallerLinkVSCode
+
+	// CallerLinkURL displays the link itself instead of `file:line`,
+	// for terminals that do not support OSC 8 but detect URLs.
+	CallerLinkURL
+)
+
+var callerLink CallerLink
+
+// SetPrettyCallerLink is set the display mode of the caller when PrettyOutput is used.
+// option can use (CallerLinkNone, CallerLinkOSC8, CallerLinkVSCode, CallerLinkURL).
+func SetPrettyCallerLink(option CallerLink) {
+	callerLink = option
+}
+
+// formatCaller returns the caller text according to log flags and callerLink.
+// file is the absolute path returned by runtime.Caller.
+func formatCaller(file string, line int, flags int) string {
+	text := file
+	if flags&log.Lshortfile != 0 {
+		text = filepath.Base(file)
+	}
+	text = fmt.Sprintf("%s:%d", text, line)
+	if callerLink == CallerLinkNone || line == 0 {
+		return text
+	}
+
+	target := callerTarget(file, line)
+	if callerLink == CallerLinkURL {
+		return target
+	}
+	return au.Hyperlink(text, target).String()
+}
+
+func callerTarget(file string, line int) string {
+	if callerLink == CallerLinkVSCode {
+		return fmt.Sprintf("vscode://file%s:%d", toSlashPath(file), line)
+	}
+	if u, ok := repositoryFileURL(file, line); ok {
+		return u
+	}
+	u := url.URL{Scheme: "file", Path: toSlashPath(file)}
+	return u.String()
+}
+
+// toSlashPath returns the path starting with a slash also on Windows. ex. `/C:/path/to/main.go`
+func toSlashPath(file string) string {
+	p := filepath.ToSlash(file)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
@@ -0,0 +1,70 @@
+package zl
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatCaller(t *testing.T) {
+	file := "/path/to/project/cmd/main.go"
+	tests := []struct {
+		name     string
+		link     CallerLink
+		repo     bool
+		flags    int
+		expected string
+	}{
+		{
+			name:     "none",
+			link:     CallerLinkNone,
+			flags:    log.Lshortfile,
+			expected: "main.go:10",
+		},
+		{
+			name:     "none long file",
+			link:     CallerLinkNone,
+			flags:    log.Llongfile,
+			expected: "/path/to/project/cmd/main.go:10",
+		},
+		{
+			name:     "osc8 file",
+			link:     CallerLinkOSC8,
+			flags:    log.Lshortfile,
+			expected: "\x1b]8;;file:///path/to/project/cmd/main.go\x1b\\main.go:10\x1b]8;;\x1b\\",
+		},
+		{
+			name:  "osc8 repository",
+			link:  CallerLinkOSC8,
+			repo:  true,
+			flags: log.Lshortfile,
+			expected: "\x1b]8;;https://github.com/nkmr-jp/zl/blob/v1.0.0/cmd/main.go#L10\x1b\\" +
+				"main.go:10\x1b]8;;\x1b\\",
+		},
+		{
+			name:     "vscode",
+			link:     CallerLinkVSCode,
+			repo:     true,
+			flags:    log.Lshortfile,
+			expected: "\x1b]8;;vscode://file/path/to/project/cmd/main.go:10\x1b\\main.go:10\x1b]8;;\x1b\\",
+		},
+		{
+			name:     "url",
+			link:     CallerLinkURL,
+			repo:     true,
+			flags:    log.Lshortfile,
+			expected: "https://github.com/nkmr-jp/zl/blob/v1.0.0/cmd/main.go#L10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPrettyCallerLink(tt.link)
+			if tt.repo {
+				SetRepositoryCallerEncoder("https://github.com/nkmr-jp/zl/blob/%s", "v1.0.0", "/path/to/project")
+			}
+			assert.Equal(t, tt.expected, formatCaller(file, 10, tt.flags))
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
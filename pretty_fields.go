@@ -0,0 +1,150 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	au "github.com/logrusorgru/aurora/v4"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// PrettyFields is the display mode of the fields that are not console fields when PrettyOutput is used.
+type PrettyFields int
+
+const (
+	// PrettyFieldsNone does not display the fields. Only the console fields are displayed.
+	// It is Default setting.
+	PrettyFieldsNone PrettyFields = iota
+
+	// PrettyFieldsKeyValue displays the fields as dim `key=value` pairs after the message.
+	PrettyFieldsKeyValue
+
+	// PrettyFieldsJSON displays the fields as an indented colored JSON block under the message.
+	PrettyFieldsJSON
+)
+
+const truncatedMark = "…"
+
+var (
+	prettyFields      PrettyFields
+	prettyFieldsWidth int
+
+	jsonKeyRegexp = regexp.MustCompile(`(?m)^(\s*)("(?:[^"\\]|\\.)*")(:)`)
+)
+
+// SetPrettyFields is set the display mode of the fields that are not console fields
+// when PrettyOutput is used.
+// option can use (PrettyFieldsNone, PrettyFieldsKeyValue, PrettyFieldsJSON).
+func SetPrettyFields(option PrettyFields) {
+	prettyFields = option
+}
+
+// SetPrettyFieldsWidth is set the maximum width of the fields displayed by SetPrettyFields.
+// With PrettyFieldsKeyValue it caps the whole pairs, with PrettyFieldsJSON it caps each line.
+// 0 (default) means unlimited.
+func SetPrettyFieldsWidth(width int) {
+	prettyFieldsWidth = width
+}
+
+// fieldsMsg returns the fields that are not console fields formatted according to prettyFields.
+// The error field is skipped when skipError is true because it is already displayed after the message.
+func (l *prettyLogger) fieldsMsg(fields []zap.Field, skipError bool) string {
+	if prettyFields == PrettyFieldsNone {
+		return ""
+	}
+	var extra []zap.Field
+	for i := range fields {
+		if lo.Contains(consoleFields, fields[i].Key) {
+			continue
+		}
+		if skipError && fields[i].Type == zapcore.ErrorType && fields[i].Key == "error" {
+			continue
+		}
+		if skipError && errorFingerprint && fields[i].Key == FingerprintKey {
+			continue
+		}
+		if skipError && fields[i].Type == zapcore.ArrayMarshalerType && fields[i].Key == ErrorsKey {
+			continue
+		}
+		extra = append(extra, fields[i])
+	}
+	if extra == nil {
+		return ""
+	}
+	if prettyFields == PrettyFieldsJSON {
+		return l.jsonFieldsMsg(extra)
+	}
+	return l.keyValueFieldsMsg(extra)
+}
+
+func (l *prettyLogger) keyValueFieldsMsg(fields []zap.Field) string {
+	var pairs []string
+	var namespace string // namespace is the prefix of the keys of the fields after the Namespace fields.
+	for i := range fields {
+		if fields[i].Type == zapcore.NamespaceType {
+			namespace += fields[i].Key + "."
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(enc)
+		keys := lo.Keys(enc.Fields)
+		sort.Strings(keys)
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s%s=%s", namespace, k, formatFieldValue(enc.Fields[k])))
+		}
+	}
+	ret := truncate(strings.Join(pairs, " "), prettyFieldsWidth)
+	return separator + au.Colorize(ret, theme.Fields).String()
+}
+
+func (l *prettyLogger) jsonFieldsMsg(fields []zap.Field) string {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		l.internalLog.Println(err)
+		return ""
+	}
+	defer buf.Free()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bytes.TrimSpace(buf.Bytes()), "\t", "  "); err != nil {
+		l.internalLog.Println(err)
+		return ""
+	}
+	lines := strings.Split(indented.String(), "\n")
+	for i := range lines {
+		lines[i] = jsonKeyRegexp.ReplaceAllString(
+			truncate(lines[i], prettyFieldsWidth), "$1"+au.Colorize("$2", theme.Attr).String()+"$3",
+		)
+	}
+	return "\n\t" + strings.Join(lines, "\n")
+}
+
+func formatFieldValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if strings.ContainsAny(s, " \t\n\"=") {
+			return fmt.Sprintf("%q", s)
+		}
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// truncate cuts str to width runes. 0 means unlimited.
+func truncate(str string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(str) <= width {
+		return str
+	}
+	return string([]rune(str)[:width]) + truncatedMark
+}
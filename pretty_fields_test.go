@@ -0,0 +1,91 @@
+package zl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func Test_prettyLogger_fieldsMsg(t *testing.T) {
+	fields := []zap.Field{
+		Console("console message"),
+		zap.String("user_name", "Alice Smith"),
+		zap.Int("user_age", 20),
+		zap.Bool("admin", true),
+		zap.Error(errors.New("some error")),
+	}
+	tests := []struct {
+		name      string
+		mode      PrettyFields
+		width     int
+		skipError bool
+		expected  string
+	}{
+		{
+			name:     "none",
+			mode:     PrettyFieldsNone,
+			expected: "",
+		},
+		{
+			name:     "key value",
+			mode:     PrettyFieldsKeyValue,
+			expected: " \x1b[2muser_name=\"Alice Smith\" user_age=20 admin=true error=\"some error\"\x1b[0m",
+		},
+		{
+			name:      "key value skip error",
+			mode:      PrettyFieldsKeyValue,
+			skipError: true,
+			expected:  " \x1b[2muser_name=\"Alice Smith\" user_age=20 admin=true\x1b[0m",
+		},
+		{
+			name:     "key value with width",
+			mode:     PrettyFieldsKeyValue,
+			width:    12,
+			expected: " \x1b[2muser_name=\"A…\x1b[0m",
+		},
+		{
+			name:      "json",
+			mode:      PrettyFieldsJSON,
+			skipError: true,
+			expected: "\n\t{" +
+				"\n\t  \x1b[36m\"user_name\"\x1b[0m: \"Alice Smith\"," +
+				"\n\t  \x1b[36m\"user_age\"\x1b[0m: 20," +
+				"\n\t  \x1b[36m\"admin\"\x1b[0m: true" +
+				"\n\t}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPrettyFields(tt.mode)
+			SetPrettyFieldsWidth(tt.width)
+			l := newPrettyLogger(os.Stderr, os.Stderr)
+			assert.Equal(t, tt.expected, l.fieldsMsg(fields, tt.skipError))
+			ResetGlobalLoggerSettings()
+		})
+	}
+
+	t.Run("log", func(t *testing.T) {
+		SetPrettyFields(PrettyFieldsKeyValue)
+		omitKeys = []Key{TimeKey}
+		var buf bytes.Buffer
+		l := newPrettyLogger(&buf, os.Stderr)
+		l.log("USER_INFO", InfoLevel, []zap.Field{zap.String("user_name", "Alice")})
+		assert.Contains(t, buf.String(), "USER_INFO \x1b[2muser_name=Alice\x1b[0m\n")
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		SetPrettyFields(PrettyFieldsKeyValue)
+		l := newPrettyLogger(os.Stderr, os.Stderr)
+		assert.Equal(t, " \x1b[2muser=alice http.method=GET http.status=200 http.tls.version=1.3\x1b[0m", l.fieldsMsg([]zap.Field{
+			zap.String("user", "alice"),
+			Namespace("http"), zap.String("method", "GET"), zap.Int("status", 200),
+			Namespace("tls"), zap.String("version", "1.3"),
+		}, false))
+		ResetGlobalLoggerSettings()
+	})
+}
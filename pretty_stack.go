@@ -0,0 +1,102 @@
+package zl
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	au "github.com/logrusorgru/aurora/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const maxStackDepth = 64
+
+var (
+	prettyStacktrace bool
+
+	// stackFilterPrefixes is the function name prefixes of the frames stripped from the console stacktrace.
+	stackFilterPrefixes = []string{
+		"github.com/nkmr-jp/zl.",
+		"go.uber.org/zap",
+		"runtime.",
+	}
+)
+
+// SetPrettyStacktrace is set whether the error and the stacktrace are displayed under the line
+// when PrettyOutput is used.
+// The error is displayed in the `%+v` format, and the stacktrace is displayed at ErrorLevel or higher,
+// the same as the stacktrace field of the log file. The frames of zl, zap and runtime are stripped.
+func SetPrettyStacktrace(val bool) {
+	prettyStacktrace = val
+}
+
+// stacktraceMsg returns the indented error and stacktrace displayed under the line.
+func (l *prettyLogger) stacktraceMsg(level zapcore.Level, err error, fields []zap.Field) string {
+	if !prettyStacktrace {
+		return ""
+	}
+	if err == nil {
+		err = errorField(fields)
+	}
+
+	var b strings.Builder
+	if err != nil {
+		verbose := fmt.Sprintf("%+v", err)
+		if scrubbed, ok := scrubString(verbose); ok {
+			verbose = scrubbed
+		}
+		verbose = strings.ReplaceAll(verbose, "\n", "\n\t  ")
+		b.WriteString(fmt.Sprintf("\n\t%s: %s", au.Colorize("error", theme.Attr), verbose))
+	}
+	if level >= ErrorLevel {
+		if stack := cleanStack(); stack != "" {
+			b.WriteString(fmt.Sprintf("\n\t%s:\n%s", au.Colorize("stacktrace", theme.Attr), stack))
+		}
+	}
+	return b.String()
+}
+
+// errorField returns the error of the `error` field added by zap.Error.
+func errorField(fields []zap.Field) error {
+	for i := range fields {
+		if fields[i].Type != zapcore.ErrorType || fields[i].Key != "error" {
+			continue
+		}
+		if err, ok := fields[i].Interface.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanStack returns the indented stacktrace without the frames of stackFilterPrefixes.
+func cleanStack() string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var lines []string
+	for {
+		frame, more := frames.Next()
+		if !isFilteredFrame(frame.Function) {
+			lines = append(lines,
+				"\t\t"+formatFunctionName(frame.Function),
+				au.Faint(fmt.Sprintf("\t\t\t%s:%d", frame.File, frame.Line)).String(),
+			)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isFilteredFrame(function string) bool {
+	for _, prefix := range stackFilterPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
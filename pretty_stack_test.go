@@ -0,0 +1,85 @@
+package zl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type verboseError struct{}
+
+func (e verboseError) Error() string { return "verbose error" }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprint(s, "verbose error\ndetail line")
+		return
+	}
+	_, _ = fmt.Fprint(s, e.Error())
+}
+
+func TestSetPrettyStacktrace(t *testing.T) {
+	SetColor(ColorNever)
+	SetPrettyStacktrace(true)
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	errForTest(l, "READ_ERROR", verboseError{})
+
+	out := buf.String()
+	assert.Contains(t, out, "ERROR READ_ERROR verbose error\n\terror: verbose error\n\t  detail line\n\tstacktrace:\n")
+	assert.Contains(t, out, "\t\ttesting.tRunner\n")
+	assert.NotContains(t, out, "github.com/nkmr-jp/zl.")
+	assert.NotContains(t, out, "go.uber.org/zap")
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetPrettyStacktrace_errorField(t *testing.T) {
+	SetColor(ColorNever)
+	SetPrettyStacktrace(true)
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	l.log("WARN_MESSAGE", WarnLevel, []zap.Field{zap.Error(fmt.Errorf("warn error"))})
+	assert.Equal(t, "WARN WARN_MESSAGE\n\terror: warn error\n", buf.String())
+
+	buf.Reset()
+	l.log("INFO_MESSAGE", InfoLevel, nil)
+	assert.Equal(t, "INFO INFO_MESSAGE\n", buf.String())
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetPrettyStacktrace_scrubbers(t *testing.T) {
+	SetColor(ColorNever)
+	SetPrettyStacktrace(true)
+	SetScrubbers(EmailScrubber)
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	errForTest(l, "FAIL", fmt.Errorf("user bob@example.com not found"))
+	assert.Contains(t, buf.String(), "ERROR FAIL user [EMAIL] not found\n\terror: user [EMAIL] not found\n")
+
+	buf.Reset()
+	l.log("WARN_MESSAGE", WarnLevel, []zap.Field{zap.Error(fmt.Errorf("user bob@example.com not found"))})
+	assert.Equal(t, "WARN WARN_MESSAGE\n\terror: user [EMAIL] not found\n", buf.String())
+	assert.NotContains(t, buf.String(), "bob@example.com")
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetPrettyStacktrace_disabled(t *testing.T) {
+	SetColor(ColorNever)
+
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	errForTest(l, "READ_ERROR", verboseError{})
+	assert.Equal(t, "ERROR READ_ERROR verbose error\n", buf.String())
+	ResetGlobalLoggerSettings()
+}
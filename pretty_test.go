@@ -368,7 +368,7 @@ func Test_prettyLogger_consoleMsg(t *testing.T) {
 	consoleFields = []string{"name", "id"}
 
 	expected := separator + "\u001B[36mAlice\u001B[0m" + separator + "\u001B[34m1\u001B[0m"
-	actual := l.consoleMsg([]zap.Field{
+	actual := l.consoleMsg(InfoLevel, []zap.Field{
 		zap.String("name", "Alice"),
 		zap.Int("id", 1),
 	})
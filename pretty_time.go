@@ -0,0 +1,66 @@
+package zl
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeMode is the display mode of the timestamp in the console log when PrettyOutput is used.
+type TimeMode int
+
+const (
+	// TimeWallClock displays the wall clock time. ex. `2023/09/09 15:53:17`
+	// It is Default setting.
+	TimeWallClock TimeMode = iota
+
+	// TimeElapsed displays the elapsed time since Init. ex. `+00:03.120`
+	TimeElapsed
+
+	// TimeDelta displays the elapsed time since the previous log. ex. `+00:00.015`
+	TimeDelta
+)
+
+var (
+	timeMode  TimeMode
+	startTime time.Time
+
+	// lastTimeMu guards lastTime, which is shared by the prettyLoggers of named loggers.
+	lastTimeMu sync.Mutex
+	lastTime   time.Time
+)
+
+// SetPrettyTimeMode is set the display mode of the timestamp when PrettyOutput is used.
+// option can use (TimeWallClock, TimeElapsed, TimeDelta).
+func SetPrettyTimeMode(option TimeMode) {
+	timeMode = option
+}
+
+// relativeTime returns the elapsed time of t according to timeMode.
+func relativeTime(t time.Time) string {
+	lastTimeMu.Lock()
+	defer lastTimeMu.Unlock()
+
+	if startTime.IsZero() {
+		startTime = t
+	}
+	base := startTime
+	if timeMode == TimeDelta && !lastTime.IsZero() {
+		base = lastTime
+	}
+	lastTime = t
+	return formatElapsed(t.Sub(base))
+}
+
+// formatElapsed formats d as `+mm:ss.mmm`, or `+h:mm:ss.mmm` when d is an hour or more.
+func formatElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	h, m, s, ms := ms/3600000, ms/60000%60, ms/1000%60, ms%1000
+	if h > 0 {
+		return fmt.Sprintf("+%d:%02d:%02d.%03d", h, m, s, ms)
+	}
+	return fmt.Sprintf("+%02d:%02d.%03d", m, s, ms)
+}
@@ -0,0 +1,52 @@
+package zl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatElapsed(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{0, "+00:00.000"},
+		{3*time.Second + 120*time.Millisecond, "+00:03.120"},
+		{12*time.Minute + 5*time.Second, "+12:05.000"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, "+1:02:03.004"},
+		{-time.Second, "+00:00.000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatElapsed(tt.in))
+		})
+	}
+}
+
+func Test_relativeTime(t *testing.T) {
+	start := time.Date(2023, 9, 9, 15, 53, 17, 0, time.UTC)
+
+	t.Run("elapsed", func(t *testing.T) {
+		SetPrettyTimeMode(TimeElapsed)
+		startTime = start
+		assert.Equal(t, "+00:01.000", relativeTime(start.Add(time.Second)))
+		assert.Equal(t, "+00:03.500", relativeTime(start.Add(3500*time.Millisecond)))
+		ResetGlobalLoggerSettings()
+	})
+	t.Run("delta", func(t *testing.T) {
+		SetPrettyTimeMode(TimeDelta)
+		startTime = start
+		assert.Equal(t, "+00:01.000", relativeTime(start.Add(time.Second)))
+		assert.Equal(t, "+00:02.500", relativeTime(start.Add(3500*time.Millisecond)))
+		ResetGlobalLoggerSettings()
+	})
+	t.Run("formatTime", func(t *testing.T) {
+		SetPrettyTimeMode(TimeElapsed)
+		startTime = start
+		l := &prettyLogger{}
+		assert.Equal(t, "+00:00.015", l.formatTime(start.Add(15*time.Millisecond), 0))
+		ResetGlobalLoggerSettings()
+	})
+}
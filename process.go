@@ -0,0 +1,67 @@
+package zl
+
+import (
+	"os"
+	"os/user"
+	"strings"
+)
+
+// sensitiveArgNames are the parts of the names of the command-line flags whose values are redacted in ArgsKey.
+var sensitiveArgNames = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "api-key"}
+
+// getUserName returns the name of the OS user of the process.
+func getUserName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+// getExecutable returns the path of the executable of the process.
+func getExecutable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return exe
+}
+
+// redactArgs returns the command-line arguments with the values of the sensitive flags replaced with RedactedValue.
+// The flags are sensitive if their names contain sensitiveArgNames or they are the keys set by SetRedactKeys.
+// Both "-flag=value" and "-flag value" are redacted.
+func redactArgs(args []string) []string {
+	ret := make([]string, len(args))
+	copy(ret, args)
+	for i := 0; i < len(ret); i++ {
+		if !strings.HasPrefix(ret[i], "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(ret[i], "-"), "=")
+		if !isSensitiveArg(name) {
+			continue
+		}
+		if hasValue {
+			ret[i] = ret[i][:strings.Index(ret[i], "=")+1] + RedactedValue
+			continue
+		}
+		if i+1 < len(ret) && !strings.HasPrefix(ret[i+1], "-") {
+			ret[i+1] = RedactedValue
+			i++
+		}
+	}
+	return ret
+}
+
+func isSensitiveArg(name string) bool {
+	if isRedactKey(name) {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, s := range sensitiveArgNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package zl
+
+import (
+	"os"
+	"sync"
+)
+
+// processInfo is the cache of the values of the additional fields that do not change in the process.
+// The values are computed when they are used first, and shared by all the loggers created by New,
+// because some of them are slow. ex. `git rev-parse` of SetGitVersionFallback, os.Hostname and user.Current
+var processInfo = &processInfoCache{}
+
+type processInfoCache struct {
+	host                              lazyValue[*string]
+	pid                               lazyValue[int]
+	buildVersion, gitVersion          lazyValue[string]
+	containerID, userName, executable lazyValue[string]
+}
+
+// lazyValue is a value computed once.
+type lazyValue[T any] struct {
+	once sync.Once
+	v    T
+}
+
+func (l *lazyValue[T]) get(f func() T) T {
+	l.once.Do(func() { l.v = f() })
+	return l.v
+}
+
+func cachedHost() *string        { return processInfo.host.get(getHost) }
+func cachedPID() int             { return processInfo.pid.get(os.Getpid) }
+func cachedBuildVersion() string { return processInfo.buildVersion.get(getBuildVersion) }
+func cachedGitVersion() string   { return processInfo.gitVersion.get(getGitVersion) }
+func cachedContainerID() string  { return processInfo.containerID.get(getContainerID) }
+func cachedUserName() string     { return processInfo.userName.get(getUserName) }
+func cachedExecutable() string   { return processInfo.executable.get(getExecutable) }
+
+// resetProcessInfo clears the cache so that the values are computed again.
+func resetProcessInfo() {
+	processInfo = &processInfoCache{}
+}
@@ -0,0 +1,27 @@
+package zl
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_processInfo(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	calls := 0
+	stubBuildInfo(t, nil)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		calls++
+		return &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, true
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "v1.2.3", GetVersion())
+	}
+	assert.Equal(t, 1, calls)
+	assert.Same(t, cachedHost(), cachedHost())
+
+	ResetGlobalLoggerSettings()
+	assert.Equal(t, "v1.2.3", GetVersion())
+	assert.Equal(t, 2, calls, "the cache is cleared by ResetGlobalLoggerSettings")
+}
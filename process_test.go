@@ -0,0 +1,36 @@
+package zl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func Test_getAdditionalFields_process(t *testing.T) {
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = []string{"app", "-v", "--password=p", "-token", "t", "-ssn", "123", "run"}
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	SetEnableKeys(UserKey, ExecutableKey, ArgsKey, PPIDKey)
+	SetRedactKeys("ssn")
+
+	fields := getAdditionalFields()
+	assert.Len(t, fields, 4)
+	assert.Equal(t, zap.String(string(UserKey), getUserName()), fields[0])
+	assert.NotEmpty(t, fields[0].String)
+	assert.Equal(t, string(ExecutableKey), fields[1].Key)
+	assert.NotEmpty(t, fields[1].String)
+	assert.Equal(t, zap.Strings(string(ArgsKey), []string{
+		"-v", "--password=" + RedactedValue, "-token", RedactedValue, "-ssn", RedactedValue, "run",
+	}), fields[2])
+	assert.Equal(t, zap.Int(string(PPIDKey), os.Getppid()), fields[3])
+	ResetGlobalLoggerSettings()
+}
+
+func Test_redactArgs(t *testing.T) {
+	args := []string{"--api-key", "-x", "--db-password", "--secret=", "file"}
+	assert.Equal(t, []string{"--api-key", "-x", "--db-password", "--secret=" + RedactedValue, "file"}, redactArgs(args))
+	assert.Equal(t, "--secret=", args[3], "args is not modified")
+}
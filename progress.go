@@ -0,0 +1,43 @@
+package zl
+
+import (
+	"io"
+)
+
+// clearLine moves the cursor to the beginning of the line and erases the line.
+const clearLine = "\r\x1b[2K"
+
+// progressLine is the line displayed by Progress. It is protected by consoleMu.
+var progressLine string
+
+// Progress displays msg on the current console line when PrettyOutput is used.
+// Each call rewrites the line, so it can be used for progress bars and spinners of CLI tools.
+// Regular logs are written above the progress line, and the progress line is displayed again after them.
+// It is only displayed when the console is colored. See: SetColor
+// Call ProgressDone to remove the progress line.
+func Progress(msg string) {
+	checkInit()
+	pretty.progress(msg)
+}
+
+// ProgressDone removes the progress line displayed by Progress.
+// It is also called by Sync.
+func ProgressDone() {
+	checkInit()
+	pretty.progress("")
+}
+
+func (l *prettyLogger) progress(msg string) {
+	if outputType != PrettyOutput || l.noColor {
+		return
+	}
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if msg == "" && progressLine == "" {
+		return
+	}
+	progressLine = msg
+	if _, err := io.WriteString(l.Logger.Writer(), clearLine+msg); err != nil {
+		l.internalLog.Println(err)
+	}
+}
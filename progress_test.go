@@ -0,0 +1,40 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_prettyLogger_progress(t *testing.T) {
+	t.Run("progress", func(t *testing.T) {
+		var buf bytes.Buffer
+		omitKeys = []Key{TimeKey}
+		l := newPrettyLogger(&buf, os.Stderr)
+		l.Logger.SetFlags(0)
+
+		l.progress("1/3")
+		l.progress("2/3")
+		l.log("INFO_MESSAGE", InfoLevel, nil)
+		l.progress("3/3")
+		l.progress("")
+		l.progress("")
+		assert.Equal(t,
+			clearLine+"1/3"+clearLine+"2/3"+clearLine+"\x1b[94mINFO\x1b[0m INFO_MESSAGE\n2/3"+clearLine+"3/3"+clearLine,
+			buf.String(),
+		)
+		assert.Empty(t, progressLine)
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("no color", func(t *testing.T) {
+		var buf bytes.Buffer
+		SetColor(ColorNever)
+		l := newPrettyLogger(&buf, os.Stderr)
+		l.progress("1/3")
+		assert.Empty(t, buf.String())
+		ResetGlobalLoggerSettings()
+	})
+}
@@ -0,0 +1,265 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxQueryLineSize is the maximum size of a json log read by Query.
+const maxQueryLineSize = 16 * 1024 * 1024
+
+// Filter is the condition of the logs returned by Query.
+type Filter struct {
+	// Level is the minimum level of the logs. The zero value is InfoLevel. Use DebugLevel to match all the logs.
+	Level zapcore.Level
+	// Since and Until are the time range of the logs. Zero means unlimited.
+	Since time.Time
+	Until time.Time
+	// Message is the message of the logs, or the prefix of it if it ends with "*". Empty matches all the messages.
+	Message string
+	// Field is the fields that the logs must have. The values are compared as strings. e.g. {"user_id": "42"}
+	Field map[string]string
+}
+
+// QueryEntry is a json log returned by Query.
+type QueryEntry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Message string
+	Fields  map[string]interface{} // Fields is all the fields of the json log. The numbers are json.Number.
+	Raw     []byte                 // Raw is the json log line without the newline.
+	File    string                 // File is the path of the file of the log.
+}
+
+// Query returns the iterator of the json logs in dir that match filter.
+// The current and the rotated log files are read in the order of the file names,
+// which is the order of time for the backups of lumberjack,
+// and the files compressed by SetRotateCompress (.gz and .zst) are decompressed.
+// The files are read one by one when Next is called, so that the large logs are not loaded into memory.
+// The lines that are not json logs are skipped.
+// e.g.
+//
+//	it, err := zl.Query("./log", zl.Filter{Level: zl.ErrorLevel, Field: map[string]string{"user_id": "42"}})
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(string(it.Entry().Raw))
+//	}
+//	return it.Err()
+func Query(dir string, filter Filter) (*QueryIterator, error) {
+	files, err := queryFiles(dir, filter.Since)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryIterator{filter: filter, files: files}, nil
+}
+
+// queryFiles returns the log files in dir sorted by the names.
+// The files that are not modified since since are skipped because all the logs of them are older.
+func queryFiles(dir string, since time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !isLogFileName(e.Name()) {
+			continue
+		}
+		if !since.IsZero() {
+			info, err := e.Info()
+			if err != nil {
+				return nil, err
+			}
+			if info.ModTime().Before(since) {
+				continue
+			}
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func isLogFileName(name string) bool {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, CompressionGzip.extension()), CompressionZstd.extension())
+	switch filepath.Ext(name) {
+	case ".jsonl", ".json", ".log":
+		return true
+	}
+	return false
+}
+
+// QueryIterator is the iterator of the logs returned by Query. It is not safe for concurrent use.
+type QueryIterator struct {
+	filter  Filter
+	files   []string
+	file    string
+	closer  func() error
+	scanner *bufio.Scanner
+	entry   *QueryEntry
+	err     error
+}
+
+// Next advances to the next matched log. It returns false at the end or at an error. See: Err
+func (it *QueryIterator) Next() bool {
+	for it.err == nil {
+		if it.scanner == nil {
+			if len(it.files) == 0 {
+				return false
+			}
+			if it.err = it.open(it.files[0]); it.err != nil {
+				return false
+			}
+			it.files = it.files[1:]
+		}
+		if !it.scanner.Scan() {
+			if err := it.scanner.Err(); err != nil {
+				it.err = fmt.Errorf("zl: query %s: %w", it.file, err)
+			}
+			_ = it.closeFile()
+			continue
+		}
+		if e, ok := it.filter.match(it.scanner.Bytes()); ok {
+			e.File = it.file
+			it.entry = e
+			return true
+		}
+	}
+	return false
+}
+
+// Entry returns the log of the last Next.
+func (it *QueryIterator) Entry() *QueryEntry {
+	return it.entry
+}
+
+// Err returns the first error of Next.
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// Close closes the current file. It must be called if Next has not returned false.
+func (it *QueryIterator) Close() error {
+	it.files = nil
+	return it.closeFile()
+}
+
+func (it *QueryIterator) open(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := newDecompressReader(f, path)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("zl: query %s: %w", path, err)
+	}
+	it.file = path
+	it.closer = func() error {
+		_ = r.Close()
+		return f.Close()
+	}
+	it.scanner = bufio.NewScanner(r)
+	it.scanner.Buffer(make([]byte, 0, 64*1024), maxQueryLineSize)
+	return nil
+}
+
+func (it *QueryIterator) closeFile() error {
+	it.scanner = nil
+	if it.closer == nil {
+		return nil
+	}
+	err := it.closer()
+	it.closer = nil
+	return err
+}
+
+// newDecompressReader returns the reader of the file decompressed by the extension of path.
+// Closing it does not close r.
+func newDecompressReader(r io.Reader, path string) (io.ReadCloser, error) {
+	switch filepath.Ext(path) {
+	case CompressionGzip.extension():
+		return gzip.NewReader(r)
+	case CompressionZstd.extension():
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+// match parses the json log line and reports whether it matches the filter.
+func (f Filter) match(line []byte) (*QueryEntry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, false
+	}
+	e := &QueryEntry{Level: InfoLevel, Fields: fields}
+	if s, ok := fields[fieldKey(LevelKey)].(string); ok {
+		_ = e.Level.UnmarshalText([]byte(s))
+	}
+	if e.Level < f.Level {
+		return nil, false
+	}
+	if s, ok := fields[fieldKey(TimeKey)].(string); ok {
+		e.Time, _ = time.Parse(time.RFC3339Nano, s)
+	}
+	if !e.Time.IsZero() && ((!f.Since.IsZero() && e.Time.Before(f.Since)) || (!f.Until.IsZero() && e.Time.After(f.Until))) {
+		return nil, false
+	}
+	e.Message, _ = fields[fieldKey(MessageKey)].(string)
+	if f.Message != "" {
+		if prefix, ok := strings.CutSuffix(f.Message, "*"); ok {
+			if !strings.HasPrefix(e.Message, prefix) {
+				return nil, false
+			}
+		} else if e.Message != f.Message {
+			return nil, false
+		}
+	}
+	for k, want := range f.Field {
+		v, ok := fields[k]
+		if !ok || queryValueString(v) != want {
+			return nil, false
+		}
+	}
+	e.Raw = append([]byte(nil), line...)
+	return e, true
+}
+
+func queryValueString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
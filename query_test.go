@@ -0,0 +1,90 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCompressedLog(t *testing.T, path string, codec Compression, logs string) {
+	var buf bytes.Buffer
+	w, err := newCompressWriter(&buf, codec, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, _ = w.Write([]byte(logs))
+	assert.NoError(t, w.Close())
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func TestQuery(t *testing.T) {
+	dir := t.TempDir()
+	writeCompressedLog(t, filepath.Join(dir, "app-2026-10-14T09-00-00.000.jsonl.gz"), CompressionGzip,
+		`{"severity":"ERROR","timestamp":"2026-10-14T08:00:00Z","message":"OLD_ERROR","user_id":42}`+"\n")
+	writeCompressedLog(t, filepath.Join(dir, "app-2026-10-14T10-00-00.000.jsonl.zst"), CompressionZstd,
+		`{"severity":"ERROR","timestamp":"2026-10-14T09:30:00Z","message":"DB_ERROR","user_id":42}`+"\n")
+	current := strings.Join([]string{
+		`{"severity":"INFO","timestamp":"2026-10-14T10:10:00Z","message":"USER_INFO","user_id":"42"}`,
+		`not a json log`,
+		`{"severity":"ERROR","timestamp":"2026-10-14T10:20:00Z","message":"DB_ERROR","user_id":"43"}`,
+		`{"severity":"FATAL","timestamp":"2026-10-14T10:30:00Z","message":"DB_FATAL","user_id":"42"}`,
+	}, "\n")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.jsonl"), []byte(current), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`{"severity":"ERROR"}`), 0o600))
+	// The files are modified at the time of the last logs.
+	for name, mtime := range map[string]time.Time{
+		"app-2026-10-14T09-00-00.000.jsonl.gz":  time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+		"app-2026-10-14T10-00-00.000.jsonl.zst": time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		"app.jsonl":                             time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC),
+	} {
+		assert.NoError(t, os.Chtimes(filepath.Join(dir, name), mtime, mtime))
+	}
+
+	query := func(filter Filter) []string {
+		it, err := Query(dir, filter)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer it.Close()
+		var messages []string
+		for it.Next() {
+			messages = append(messages, it.Entry().Message)
+		}
+		assert.NoError(t, it.Err())
+		return messages
+	}
+
+	assert.Equal(t, []string{"OLD_ERROR", "DB_ERROR", "DB_FATAL"},
+		query(Filter{Level: ErrorLevel, Field: map[string]string{"user_id": "42"}}))
+	assert.Equal(t, []string{"DB_ERROR", "DB_ERROR"},
+		query(Filter{Message: "DB_ERROR", Since: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)}))
+	assert.Equal(t, []string{"DB_ERROR", "DB_FATAL"},
+		query(Filter{Message: "DB_*", Until: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC).Add(time.Hour),
+			Since: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)}))
+	assert.Len(t, query(Filter{Level: DebugLevel}), 5)
+
+	it, err := Query(dir, Filter{Message: "USER_INFO"})
+	assert.NoError(t, err)
+	assert.True(t, it.Next())
+	assert.Equal(t, filepath.Join(dir, "app.jsonl"), it.Entry().File)
+	assert.Equal(t, InfoLevel, it.Entry().Level)
+	assert.NoError(t, it.Close())
+	assert.False(t, it.Next())
+
+	_, err = Query(filepath.Join(dir, "none"), Filter{})
+	assert.Error(t, err)
+}
+
+func TestQuery_brokenFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.jsonl.gz"), []byte("broken"), 0o600))
+	it, err := Query(dir, Filter{})
+	assert.NoError(t, err)
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
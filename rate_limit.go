@@ -0,0 +1,169 @@
+package zl
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// RateLimitedMessage is the message of the notice of the logs suppressed by SetRateLimit.
+	RateLimitedMessage = "RATE_LIMITED"
+
+	// SuppressedKey is the field key of the number of the suppressed logs in the notice.
+	SuppressedKey = "suppressed"
+
+	// RateLimitKey is the field key of the message of the suppressed logs in the notice.
+	RateLimitKey = "rate_limit_key"
+)
+
+var (
+	// rateLimits is shared by all the loggers because New creates a new core for each logger.
+	rateLimits   = make(map[string]*rateLimit)
+	rateLimitsMu sync.Mutex
+)
+
+// SetRateLimit set the rate limit of the logs whose message is key.
+// Only n logs are written per duration, and the rest are suppressed.
+// When the duration has passed, a notice of RateLimitedMessage with the SuppressedKey field of the number of the
+// suppressed logs is written at the same level. The notice is also written by Sync.
+// It applies to the json logs of the console and the file. e.g. SetRateLimit("CACHE_MISS", 10, time.Second)
+func SetRateLimit(key string, n int, per time.Duration) {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	rateLimits[key] = &rateLimit{key: key, n: n, per: per}
+}
+
+// rateLimit is the state of the fixed window of a key.
+type rateLimit struct {
+	key string
+	n   int
+	per time.Duration
+
+	timer      *time.Timer
+	count      int
+	suppressed int
+	core       zapcore.Core
+	entry      zapcore.Entry
+}
+
+func getRateLimit(key string) (*rateLimit, bool) {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	r, ok := rateLimits[key]
+	return r, ok
+}
+
+// allow reports whether the log is written. It starts a new window at the first log after the last window.
+func (r *rateLimit) allow(core zapcore.Core, ent zapcore.Entry) bool {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.per, r.expire)
+	}
+	if r.count < r.n {
+		r.count++
+		return true
+	}
+	r.suppressed++
+	addDropped(&droppedRateLimit, 1)
+	r.core = core
+	r.entry = ent
+	return false
+}
+
+func (r *rateLimit) expire() {
+	rateLimitsMu.Lock()
+	r.timer = nil
+	r.count = 0
+	notice := r.takeNotice()
+	rateLimitsMu.Unlock()
+	notice()
+}
+
+// takeNotice returns the function to write the notice and clears the number of the suppressed logs.
+// It must be called with rateLimitsMu locked, and the returned function must be called without it.
+func (r *rateLimit) takeNotice() func() {
+	if r.suppressed == 0 {
+		return func() {}
+	}
+	core, n := r.core, r.suppressed
+	ent := zapcore.Entry{
+		Level:      r.entry.Level,
+		Time:       clock.Now(),
+		LoggerName: r.entry.LoggerName,
+		Message:    RateLimitedMessage,
+	}
+	fields := []zap.Field{zap.String(RateLimitKey, r.key), zap.Int(SuppressedKey, n)}
+	r.suppressed = 0
+	return func() {
+		if err := core.Write(ent, fields); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// flushRateLimits writes the notices of all the keys.
+func flushRateLimits() {
+	rateLimitsMu.Lock()
+	var notices []func()
+	for _, r := range rateLimits {
+		notices = append(notices, r.takeNotice())
+	}
+	rateLimitsMu.Unlock()
+	for _, notice := range notices {
+		notice()
+	}
+}
+
+func resetRateLimits() {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	for _, r := range rateLimits {
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+	}
+	rateLimits = make(map[string]*rateLimit)
+}
+
+// rateLimitCore is a zapcore.Core that suppresses the logs over the rate limits.
+type rateLimitCore struct {
+	zapcore.Core
+}
+
+func newRateLimitCore(core zapcore.Core) zapcore.Core {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	if len(rateLimits) == 0 {
+		return core
+	}
+	return &rateLimitCore{Core: core}
+}
+
+func (c *rateLimitCore) With(fields []zap.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields)}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *rateLimitCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if r, ok := getRateLimit(ent.Message); ok && !r.allow(c.Core, ent) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync writes the notices before syncing.
+func (c *rateLimitCore) Sync() error {
+	flushRateLimits()
+	return c.Core.Sync()
+}
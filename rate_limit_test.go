@@ -0,0 +1,67 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetRateLimit(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetRateLimit("CACHE_MISS", 2, time.Hour)
+	Init()
+
+	for i := 0; i < 5; i++ {
+		Info("CACHE_MISS", zap.Int("i", i))
+	}
+	Info("CACHE_HIT")
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"INFO","message":"CACHE_MISS","i":0}`+"\n"+
+			`{"severity":"INFO","message":"CACHE_MISS","i":1}`+"\n"+
+			`{"severity":"INFO","message":"CACHE_HIT"}`+"\n"+
+			`{"severity":"WARN","message":"ZL_DROPPED_ENTRIES","rate_limit":3}`+"\n"+
+			`{"severity":"INFO","message":"RATE_LIMITED","rate_limit_key":"CACHE_MISS","suppressed":3}`+"\n",
+		string(b),
+	)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_rateLimitCore_window(t *testing.T) {
+	SetRateLimit("NOISY", 1, 10*time.Millisecond)
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newRateLimitCore(obs)
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Message: "NOISY"}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, core.Write(ent, nil))
+	}
+	assert.Equal(t, 1, logs.Len())
+
+	assert.Eventually(t, func() bool { return logs.Len() == 2 }, time.Second, time.Millisecond)
+	notice := logs.All()[1]
+	assert.Equal(t, zapcore.WarnLevel, notice.Level)
+	assert.Equal(t, RateLimitedMessage, notice.Message)
+	assert.Equal(t, map[string]interface{}{RateLimitKey: "NOISY", SuppressedKey: int64(2)}, notice.ContextMap())
+
+	assert.NoError(t, core.Write(ent, nil))
+	assert.Equal(t, 3, logs.Len(), "a new window is started after the window has passed")
+	ResetGlobalLoggerSettings()
+}
+
+func Test_newRateLimitCore_disabled(t *testing.T) {
+	core := zapcore.NewNopCore()
+	assert.Equal(t, core, newRateLimitCore(core))
+}
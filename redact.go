@@ -0,0 +1,202 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue is the value that replaces the values of the keys set by SetRedactKeys.
+const RedactedValue = "[REDACTED]"
+
+// redactKeys is the lower case keys set by SetRedactKeys.
+var redactKeys map[string]struct{}
+
+// SetRedactKeys set the field keys whose values are replaced with RedactedValue.
+// The keys are matched case-insensitively at any nesting level in the objects, the maps and the structs.
+// It applies to all the outputs including the pretty console and the sinks.
+// e.g. SetRedactKeys("password", "authorization", "ssn")
+func SetRedactKeys(keys ...string) {
+	redactKeys = make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redactKeys[strings.ToLower(k)] = struct{}{}
+	}
+}
+
+func isRedactKey(key string) bool {
+	_, ok := redactKeys[strings.ToLower(key)]
+	return ok
+}
+
+// redactEnabled reports whether SetRedactKeys, SetHashKeys or SetScrubbers is set.
+func redactEnabled() bool {
+	return len(redactKeys) > 0 || len(hashKeys) > 0 || len(scrubbers) > 0
+}
+
+// redactFields returns fields with the values of the redact keys replaced and the string values scrubbed.
+// fields is not modified because it may be the slice of the caller.
+func redactFields(fields []zap.Field) []zap.Field {
+	if !redactEnabled() {
+		return fields
+	}
+	var ret []zap.Field
+	for i := range fields {
+		f, ok := redactField(fields[i])
+		if !ok {
+			continue
+		}
+		if ret == nil {
+			ret = make([]zap.Field, len(fields))
+			copy(ret, fields)
+		}
+		ret[i] = f
+	}
+	if ret == nil {
+		return fields
+	}
+	return ret
+}
+
+// redactField returns the redacted field and true if f has a redact key, a hash key or a value to be scrubbed.
+func redactField(f zap.Field) (zap.Field, bool) {
+	if f.Key != "" && isRedactKey(f.Key) {
+		return zap.String(f.Key, RedactedValue), true
+	}
+	if f.Key != "" && isHashKey(f.Key) {
+		if f.Type == zapcore.StringType {
+			return zap.String(f.Key, hashValue(f.String)), true
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		return zap.String(f.Key, hashValue(enc.Fields[f.Key])), true
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		if s, ok := scrubString(f.String); ok {
+			return zap.String(f.Key, s), true
+		}
+		return f, false
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			if _, ok := scrubString(err.Error()); ok {
+				return zap.NamedError(f.Key, scrubbedError{err: err}), true
+			}
+		}
+		return f, false
+	}
+	if len(redactKeys) == 0 && len(hashKeys) == 0 {
+		return f, false
+	}
+	switch f.Type {
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType:
+	case zapcore.InlineMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		if v, ok := redactValue(enc.Fields); ok {
+			return zap.Inline(redactedObject(v.(map[string]interface{}))), true
+		}
+		return f, false
+	default:
+		return f, false
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	if v, ok := redactValue(enc.Fields[f.Key]); ok {
+		return zap.Any(f.Key, v), true
+	}
+	return f, false
+}
+
+// redactValue returns v converted to the json values with the redact keys and the hash keys replaced,
+// and true if it has them.
+func redactValue(v interface{}) (interface{}, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, false
+	}
+	return redactJSON(generic)
+}
+
+func redactJSON(v interface{}) (interface{}, bool) {
+	changed := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if isRedactKey(k) {
+				val[k] = RedactedValue
+				changed = true
+			} else if isHashKey(k) {
+				val[k] = hashValue(child)
+				changed = true
+			} else if redacted, ok := redactJSON(child); ok {
+				val[k] = redacted
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i := range val {
+			if redacted, ok := redactJSON(val[i]); ok {
+				val[i] = redacted
+				changed = true
+			}
+		}
+	}
+	return v, changed
+}
+
+// redactedObject is a zapcore.ObjectMarshaler of the redacted inline object.
+type redactedObject map[string]interface{}
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := enc.AddReflected(k, o[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactCore is a zapcore.Core that redacts the fields and scrubs the message before writing them.
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	if !redactEnabled() {
+		return core
+	}
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zap.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if s, ok := scrubString(ent.Message); ok {
+		ent.Message = s
+	}
+	return c.Core.Write(ent, redactFields(fields))
+}
@@ -0,0 +1,88 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type redactUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type redactToken struct {
+	Token string
+}
+
+func (t redactToken) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("token", t.Token)
+	enc.AddString("type", "bearer")
+	return nil
+}
+
+func TestSetRedactKeys(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetRedactKeys("password", "Authorization", "token")
+	Init()
+
+	Info("TOP", zap.String("password", "secret"), zap.String("AUTHORIZATION", "Bearer xxx"), zap.Int("id", 1))
+	Info("NESTED",
+		zap.Any("header", map[string]interface{}{"authorization": "Bearer xxx", "accept": "*/*"}),
+		zap.Any("users", []redactUser{{Name: "alice", Password: "secret"}}),
+		zap.Dict("request", zap.String("path", "/login"), zap.Object("auth", redactToken{Token: "xxx"})),
+	)
+	Info("INLINE", zap.Inline(redactToken{Token: "xxx"}))
+	New(zap.String("password", "secret")).Info("NEW")
+	Info("NOT_CHANGED", zap.Any("user", map[string]string{"name": "alice"}))
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"TOP","password":"[REDACTED]","AUTHORIZATION":"[REDACTED]","id":1}
+{"severity":"INFO","message":"NESTED","header":{"accept":"*/*","authorization":"[REDACTED]"},"users":[{"name":"alice","password":"[REDACTED]"}],"request":{"auth":{"token":"[REDACTED]","type":"bearer"},"path":"/login"}}
+{"severity":"INFO","message":"INLINE","token":"[REDACTED]","type":"bearer"}
+{"severity":"INFO","message":"NEW","password":"[REDACTED]"}
+{"severity":"INFO","message":"NOT_CHANGED","user":{"name":"alice"}}
+`, string(b))
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetRedactKeys_pretty(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	var buf bytes.Buffer
+	SetOutput(PrettyOutput)
+	SetOmitKeys(TimeKey)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetConsoleFields("password")
+	SetPrettyFields(PrettyFieldsKeyValue)
+	SetRedactKeys("password", "ssn")
+	SetColor(ColorNever)
+	Init()
+	pretty.Logger.SetOutput(&buf)
+
+	Info("LOGIN", zap.String("password", "secret"), zap.Any("user", map[string]string{"ssn": "123"}))
+	assert.Contains(t, buf.String(), `LOGIN [REDACTED] user={"ssn":"[REDACTED]"}`+"\n")
+	assert.NotContains(t, buf.String(), "secret")
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetRedactKeys_observer(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetRedactKeys("password")
+	Init()
+
+	Info("LOGIN", zap.String("password", "secret"))
+	assert.Equal(t, 1, obs.FilterField("password", RedactedValue).Len())
+}
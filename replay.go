@@ -0,0 +1,41 @@
+package zl
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// maxReplayWait is the maximum wait between two logs of Replay, so that an idle period is skipped.
+const maxReplayWait = 5 * time.Second
+
+// replaySleep is a variable so that tests can replace it.
+var replaySleep = time.Sleep
+
+// Replay reads the json logs written by zl from r and writes them to the console in the same format as PrettyOutput,
+// so that the logs of an incident can be watched locally with the familiar formatting.
+// speed is the pace of the replay relative to the real time of the logs. e.g. 2 is twice as fast.
+// 0 or lower writes all the logs without waiting. The wait between two logs is at most 5 seconds.
+// The logs of all the levels are written. Use View to filter them.
+// e.g. zl.Replay(f, 10)
+func Replay(r io.Reader, speed float64) error {
+	return replay(os.Stdout, r, speed)
+}
+
+func replay(w io.Writer, r io.Reader, speed float64) error {
+	config := ViewConfig{Level: DebugLevel}
+	if speed <= 0 {
+		return view(w, r, config, nil)
+	}
+	var prev time.Time
+	return view(w, r, config, func(t time.Time) {
+		if !prev.IsZero() && t.After(prev) {
+			wait := time.Duration(float64(t.Sub(prev)) / speed)
+			if wait > maxReplayWait {
+				wait = maxReplayWait
+			}
+			replaySleep(wait)
+		}
+		prev = t
+	})
+}
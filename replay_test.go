@@ -0,0 +1,38 @@
+package zl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	t.Cleanup(func() {
+		replaySleep = time.Sleep
+		ResetGlobalLoggerSettings()
+	})
+	var waits []time.Duration
+	replaySleep = func(d time.Duration) { waits = append(waits, d) }
+	SetColor(ColorNever)
+	logs := strings.Join([]string{
+		`{"severity":"DEBUG","timestamp":"2026-10-14T10:00:00Z","message":"FIRST"}`,
+		`{"severity":"INFO","timestamp":"2026-10-14T10:00:02Z","message":"SECOND"}`,
+		`{"severity":"INFO","timestamp":"2026-10-14T11:00:00Z","message":"THIRD"}`,
+		`{"severity":"INFO","message":"NO_TIME"}`,
+	}, "\n")
+
+	var buf bytes.Buffer
+	assert.NoError(t, replay(&buf, strings.NewReader(logs), 2))
+	assert.Equal(t, []time.Duration{time.Second, maxReplayWait}, waits)
+	assert.Equal(t, "2026/10/14 10:00:00 DEBUG FIRST\n2026/10/14 10:00:02 INFO SECOND\n"+
+		"2026/10/14 11:00:00 INFO THIRD\nINFO NO_TIME\n", buf.String())
+
+	waits = nil
+	buf.Reset()
+	assert.NoError(t, replay(&buf, strings.NewReader(logs), 0))
+	assert.Nil(t, waits)
+	assert.Equal(t, 4, strings.Count(buf.String(), "\n"))
+}
@@ -0,0 +1,247 @@
+package zl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RepositoryHost is the hosting service of the repository. It determines the URL of the source line.
+type RepositoryHost int
+
+const (
+	// RepositoryGitHub is GitHub. ex. https://github.com/owner/repo/blob/v1.0.0/main.go#L10
+	RepositoryGitHub RepositoryHost = iota
+
+	// RepositoryGitLab is GitLab. ex. https://gitlab.com/group/project/-/blob/v1.0.0/main.go#L10
+	RepositoryGitLab
+
+	// RepositoryBitbucket is Bitbucket. ex. https://bitbucket.org/workspace/repo/src/v1.0.0/main.go#lines-10
+	RepositoryBitbucket
+
+	// RepositoryGitea is Gitea and Forgejo. ex. https://gitea.com/owner/repo/src/tag/v1.0.0/main.go#L10
+	// The commit hashes use `src/commit` and the others use `src/tag`.
+	RepositoryGitea
+)
+
+var repositoryHostStrings = [4]string{
+	"GitHub",
+	"GitLab",
+	"Bitbucket",
+	"Gitea",
+}
+
+var (
+	commitHashRegexp    = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	majorVersionRegexp  = regexp.MustCompile(`/v[0-9]+$`)
+	pseudoVersionRegexp = regexp.MustCompile(`[.-][0-9]{14}-([0-9a-f]{12})(\+incompatible)?$`)
+	semverRegexp        = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+`)
+)
+
+// repositoryHosts is the hosts of the module paths that DetectRepositoryRoot knows.
+var repositoryHosts = map[string]RepositoryHost{
+	"github.com":    RepositoryGitHub,
+	"gitlab.com":    RepositoryGitLab,
+	"bitbucket.org": RepositoryBitbucket,
+	"gitea.com":     RepositoryGitea,
+	"codeberg.org":  RepositoryGitea,
+}
+
+// String is return RepositoryHost type string.
+func (h RepositoryHost) String() string {
+	return repositoryHostStrings[h]
+}
+
+// RepositoryRoot maps the source files in a local directory to the files of a repository. See: AddRepositoryRoot
+type RepositoryRoot struct {
+	Host RepositoryHost
+	// URL is the URL of the repository. ex. https://github.com/owner/repo
+	URL string
+	// Revision is the git revision or the tag. ex. `e86b9a7` or `v1.0.0`
+	Revision string
+	// Dir is the source root directory of the binary. ex. $PWD of `go build`
+	Dir string
+	// Path is the path of Dir in the repository. Empty means the root of the repository.
+	// It is used for the modules of a monorepo. ex. services/api
+	Path string
+}
+
+// repositoryRoot is the source root directory mapped to the URL prefix of the files.
+type repositoryRoot struct {
+	dir        string
+	url        string
+	lineFormat string // lineFormat is the anchor of the line. ex. #L%d
+}
+
+var repositoryRoots []repositoryRoot
+
+// SetGitHubCaller set the caller of the logs to the URL of the source line on github.com.
+// It is the same as SetRepositoryCallerEncoder("https://github.com/owner/repo/blob/%s", revisionOrTag, srcRootDir).
+func SetGitHubCaller(owner, repo, revisionOrTag, srcRootDir string) {
+	SetRepositoryCaller(RepositoryRoot{
+		Host:     RepositoryGitHub,
+		URL:      fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		Revision: revisionOrTag,
+		Dir:      srcRootDir,
+	})
+}
+
+// SetRepositoryCaller set the caller of the logs to the URL of the source line of the repository of root.
+// It replaces the roots set by SetRepositoryCallerEncoder, SetGitHubCaller and AddRepositoryRoot.
+// e.g. zl.SetRepositoryCaller(zl.RepositoryRoot{Host: zl.RepositoryGitLab, URL: "https://gitlab.com/group/project", Revision: version, Dir: srcRootDir})
+func SetRepositoryCaller(root RepositoryRoot) {
+	repositoryRoots = nil
+	AddRepositoryRoot(root)
+}
+
+// AddRepositoryRoot adds root to the repository roots of the callers, for the monorepos that have multiple modules,
+// or the binaries built from multiple repositories. The root of the longest Dir that has the source file is used.
+// The callers out of the roots are written as `dir/file.go:10`.
+// e.g.
+//
+//	zl.AddRepositoryRoot(zl.RepositoryRoot{URL: "https://github.com/acme/mono", Revision: rev, Dir: "/src/api", Path: "services/api"})
+//	zl.AddRepositoryRoot(zl.RepositoryRoot{URL: "https://github.com/acme/mono", Revision: rev, Dir: "/src/lib", Path: "libs/go"})
+func AddRepositoryRoot(root RepositoryRoot) {
+	if root.Revision == "" || root.Dir == "" || root.URL == "" {
+		return
+	}
+	url := strings.TrimSuffix(root.URL, "/")
+	lineFormat := "#L%d"
+	switch root.Host {
+	case RepositoryGitLab:
+		url += "/-/blob/" + root.Revision
+	case RepositoryBitbucket:
+		url += "/src/" + root.Revision
+		lineFormat = "#lines-%d"
+	case RepositoryGitea:
+		if commitHashRegexp.MatchString(root.Revision) {
+			url += "/src/commit/" + root.Revision
+		} else {
+			url += "/src/tag/" + root.Revision
+		}
+	default:
+		url += "/blob/" + root.Revision
+	}
+	if p := strings.Trim(root.Path, "/"); p != "" {
+		url += "/" + p
+	}
+	addRepositoryRoot(strings.TrimSuffix(root.Dir, "/"), url, lineFormat)
+}
+
+func addRepositoryRoot(dir, url, lineFormat string) {
+	repositoryRoots = append(repositoryRoots, repositoryRoot{dir: dir, url: url, lineFormat: lineFormat})
+}
+
+// repositoryFileURL returns the URL of the line of file in the repository roots.
+func repositoryFileURL(file string, line int) (string, bool) {
+	matched := -1
+	for i := range repositoryRoots {
+		if !strings.HasPrefix(file, repositoryRoots[i].dir) {
+			continue
+		}
+		if matched < 0 || len(repositoryRoots[i].dir) > len(repositoryRoots[matched].dir) {
+			matched = i
+		}
+	}
+	if matched < 0 {
+		return "", false
+	}
+	r := repositoryRoots[matched]
+	return strings.Replace(file, r.dir, r.url, 1) + fmt.Sprintf(r.lineFormat, line), true
+}
+
+// repositoryCallerEncoder writes the URL of the caller in the repository roots.
+func repositoryCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	if url, ok := repositoryFileURL(caller.File, caller.Line); ok {
+		enc.AppendString(url)
+		return
+	}
+	zapcore.ShortCallerEncoder(caller, enc)
+}
+
+// DetectRepositoryRoot returns the RepositoryRoot of the main module detected from the build info of the binary.
+//   - Host and URL: the module path of go.mod. ex. github.com/owner/repo/services/api is https://github.com/owner/repo with the Path services/api.
+//   - Revision: the vcs.revision embedded by `go build`, or the module version of `go install module@version`.
+//   - Dir: the module path if the binary is built with `-trimpath`, or the directory of go.mod found from the working directory.
+//
+// The fields that are not detected are empty, and ok is false if any of them is empty.
+// The module paths of the hosts other than github.com, gitlab.com, bitbucket.org, gitea.com and codeberg.org are not detected.
+func DetectRepositoryRoot() (root RepositoryRoot, ok bool) {
+	info, found := readBuildInfo()
+	if !found || info.Main.Path == "" {
+		return root, false
+	}
+	modulePath := majorVersionRegexp.ReplaceAllString(info.Main.Path, "")
+	parts := strings.Split(modulePath, "/")
+	if host, known := repositoryHosts[parts[0]]; known && len(parts) >= 3 {
+		root.Host = host
+		root.URL = "https://" + strings.Join(parts[:3], "/")
+		root.Path = strings.Join(parts[3:], "/")
+	}
+	root.Revision = buildSetting(info, "vcs.revision")
+	if root.Revision == "" {
+		root.Revision = moduleVersionRevision(info.Main.Version, root.Path)
+	}
+	if buildSetting(info, "-trimpath") == "true" {
+		root.Dir = info.Main.Path
+	} else if wd, err := os.Getwd(); err == nil {
+		root.Dir = findModuleDir(wd, info.Main.Path)
+	}
+	return root, root.URL != "" && root.Revision != "" && root.Dir != ""
+}
+
+// moduleVersionRevision returns the revision of the module version.
+// It is the commit hash of the pseudo-versions, and the tag of the others.
+// The tags of the modules in the subdirectories have the prefix of path. ex. services/api/v1.0.0
+func moduleVersionRevision(version, path string) string {
+	if version == "" || version == "(devel)" {
+		return ""
+	}
+	if m := pseudoVersionRegexp.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+	version = strings.TrimSuffix(version, "+incompatible")
+	if path != "" && semverRegexp.MatchString(version) {
+		return path + "/" + version
+	}
+	return version
+}
+
+// findModuleDir returns the directory of the go.mod of modulePath in dir or its parents. It returns empty if not found.
+func findModuleDir(dir, modulePath string) string {
+	for {
+		if goModModulePath(filepath.Join(dir, "go.mod")) == modulePath {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// goModModulePath returns the module path declared in the go.mod file.
+func goModModulePath(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if path, ok := strings.CutPrefix(line, "module"); ok && path != line {
+			return strings.Trim(strings.TrimSpace(path), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAddRepositoryRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		root RepositoryRoot
+		want string
+	}{
+		{
+			name: "github",
+			root: RepositoryRoot{Host: RepositoryGitHub, URL: "https://github.com/acme/app/", Revision: "v1.0.0", Dir: "/src/app"},
+			want: "https://github.com/acme/app/blob/v1.0.0/cmd/main.go#L10",
+		},
+		{
+			name: "gitlab",
+			root: RepositoryRoot{Host: RepositoryGitLab, URL: "https://gitlab.com/acme/app", Revision: "v1.0.0", Dir: "/src/app"},
+			want: "https://gitlab.com/acme/app/-/blob/v1.0.0/cmd/main.go#L10",
+		},
+		{
+			name: "bitbucket",
+			root: RepositoryRoot{Host: RepositoryBitbucket, URL: "https://bitbucket.org/acme/app", Revision: "e86b9a7", Dir: "/src/app"},
+			want: "https://bitbucket.org/acme/app/src/e86b9a7/cmd/main.go#lines-10",
+		},
+		{
+			name: "gitea commit",
+			root: RepositoryRoot{Host: RepositoryGitea, URL: "https://gitea.com/acme/app", Revision: "e86b9a7", Dir: "/src/app"},
+			want: "https://gitea.com/acme/app/src/commit/e86b9a7/cmd/main.go#L10",
+		},
+		{
+			name: "gitea tag",
+			root: RepositoryRoot{Host: RepositoryGitea, URL: "https://gitea.com/acme/app", Revision: "v1.0.0", Dir: "/src/app/"},
+			want: "https://gitea.com/acme/app/src/tag/v1.0.0/cmd/main.go#L10",
+		},
+		{
+			name: "monorepo path",
+			root: RepositoryRoot{URL: "https://github.com/acme/mono", Revision: "v1.0.0", Dir: "/src/app", Path: "/services/app/"},
+			want: "https://github.com/acme/mono/blob/v1.0.0/services/app/cmd/main.go#L10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(ResetGlobalLoggerSettings)
+			SetRepositoryCaller(tt.root)
+			got, ok := repositoryFileURL("/src/app/cmd/main.go", 10)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAddRepositoryRoot_monorepo(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	AddRepositoryRoot(RepositoryRoot{URL: "https://github.com/acme/mono", Revision: "v1", Dir: "/src/mono"})
+	AddRepositoryRoot(RepositoryRoot{URL: "https://github.com/acme/mono", Revision: "v1", Dir: "/build/api", Path: "services/api"})
+	AddRepositoryRoot(RepositoryRoot{URL: "https://github.com/acme/mono", Revision: "v2", Dir: "/src/mono/libs/go", Path: "libs/go"})
+	AddRepositoryRoot(RepositoryRoot{URL: "https://github.com/acme/mono", Dir: "/ignored"})
+
+	got, _ := repositoryFileURL("/build/api/main.go", 1)
+	assert.Equal(t, "https://github.com/acme/mono/blob/v1/services/api/main.go#L1", got)
+	got, _ = repositoryFileURL("/src/mono/libs/go/log.go", 2)
+	assert.Equal(t, "https://github.com/acme/mono/blob/v2/libs/go/log.go#L2", got)
+	got, _ = repositoryFileURL("/src/mono/tools/gen.go", 3)
+	assert.Equal(t, "https://github.com/acme/mono/blob/v1/tools/gen.go#L3", got)
+	_, ok := repositoryFileURL("/go/pkg/mod/github.com/x/y.go", 4)
+	assert.False(t, ok)
+	assert.Len(t, repositoryRoots, 3)
+
+	arr := sliceArrayEncoder{}
+	repositoryCallerEncoder(zapcore.NewEntryCaller(0, "/go/pkg/mod/github.com/x/y.go", 4, true), &arr)
+	repositoryCallerEncoder(zapcore.NewEntryCaller(0, "/build/api/main.go", 1, true), &arr)
+	assert.Equal(t, []string{"x/y.go:4", "https://github.com/acme/mono/blob/v1/services/api/main.go#L1"}, arr.elems)
+}
+
+func TestSetGitHubCaller(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetGitHubCaller("nkmr-jp", "zl", "v1.0.0", "/path/to/project")
+	got, _ := repositoryFileURL("/path/to/project/zl.go", 5)
+	assert.Equal(t, "https://github.com/nkmr-jp/zl/blob/v1.0.0/zl.go#L5", got)
+	assert.Equal(t, "Bitbucket", RepositoryBitbucket.String())
+}
+
+func TestDetectRepositoryRoot(t *testing.T) {
+	trimpath := debug.BuildSetting{Key: "-trimpath", Value: "true"}
+	tests := []struct {
+		name   string
+		info   *debug.BuildInfo
+		want   RepositoryRoot
+		wantOk bool
+	}{
+		{
+			name: "vcs revision",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: "github.com/acme/app/v2", Version: "(devel)"},
+				Settings: []debug.BuildSetting{trimpath, {Key: "vcs.revision", Value: "e86b9a7d1c2f"}},
+			},
+			want: RepositoryRoot{
+				Host: RepositoryGitHub, URL: "https://github.com/acme/app", Revision: "e86b9a7d1c2f", Dir: "github.com/acme/app/v2",
+			},
+			wantOk: true,
+		},
+		{
+			name: "go install tag of monorepo module",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: "gitlab.com/acme/mono/services/api", Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{trimpath},
+			},
+			want: RepositoryRoot{
+				Host: RepositoryGitLab, URL: "https://gitlab.com/acme/mono", Revision: "services/api/v1.2.3",
+				Dir: "gitlab.com/acme/mono/services/api", Path: "services/api",
+			},
+			wantOk: true,
+		},
+		{
+			name: "pseudo version",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: "codeberg.org/acme/app", Version: "v0.0.0-20240102150405-abcdef123456"},
+				Settings: []debug.BuildSetting{trimpath},
+			},
+			want: RepositoryRoot{
+				Host: RepositoryGitea, URL: "https://codeberg.org/acme/app", Revision: "abcdef123456", Dir: "codeberg.org/acme/app",
+			},
+			wantOk: true,
+		},
+		{
+			name: "unknown host",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: "example.com/app", Version: "v1.0.0"},
+				Settings: []debug.BuildSetting{trimpath},
+			},
+			want: RepositoryRoot{Revision: "v1.0.0", Dir: "example.com/app"},
+		},
+		{
+			name: "no build info",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubBuildInfo(t, tt.info)
+			got, ok := DetectRepositoryRoot()
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_findModuleDir(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "cmd", "app")
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	goMod := "// comment\nmodule \"github.com/acme/app\" // app\n\ngo 1.21\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o600))
+
+	assert.Equal(t, dir, findModuleDir(sub, "github.com/acme/app"))
+	assert.Equal(t, "", findModuleDir(sub, "github.com/acme/other"))
+}
+
+func TestSetRepositoryCallerEncoder_detect(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	stubBuildInfo(t, &debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/acme/app"},
+		Settings: []debug.BuildSetting{
+			{Key: "-trimpath", Value: "true"}, {Key: "vcs.revision", Value: "e86b9a7"},
+		},
+	})
+	SetRepositoryCallerEncoder("", "", "")
+	got, _ := repositoryFileURL("github.com/acme/app/main.go", 3)
+	assert.Equal(t, "https://github.com/acme/app/blob/e86b9a7/main.go#L3", got)
+
+	SetRepositoryCallerEncoder("", "v1.0.0", "/src/app")
+	got, _ = repositoryFileURL("/src/app/main.go", 3)
+	assert.Equal(t, "https://github.com/acme/app/blob/v1.0.0/main.go#L3", got)
+
+	SetRepositoryCallerEncoder("https://git.example.com/app/tree/%s", "", "/src/app")
+	got, _ = repositoryFileURL("/src/app/main.go", 3)
+	assert.Equal(t, "https://git.example.com/app/tree/e86b9a7/main.go#L3", got)
+
+	stubBuildInfo(t, nil)
+	SetRepositoryCallerEncoder("", "", "")
+	assert.Len(t, repositoryRoots, 1, "the roots are kept if not detected")
+}
+
+// sliceArrayEncoder is a zapcore.PrimitiveArrayEncoder that keeps the strings.
+type sliceArrayEncoder struct {
+	zapcore.PrimitiveArrayEncoder
+	elems []string
+}
+
+func (s *sliceArrayEncoder) AppendString(v string) {
+	s.elems = append(s.elems, v)
+}
@@ -0,0 +1,159 @@
+package zl
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MissingRequiredFieldsMessage is the message of the internal WARN log of the logs without the required fields.
+// See: SetRequiredFields
+const MissingRequiredFieldsMessage = "ZL_MISSING_REQUIRED_FIELDS"
+
+// requiredFieldsRule is the required fields of the messages or the logger.
+type requiredFieldsRule struct {
+	pattern string // pattern is the message, or the prefix of it if it ends with "*".
+	logger  string // logger is the name of the logger. The child loggers are also matched.
+	keys    []string
+}
+
+var (
+	requiredFieldsRules  []requiredFieldsRule
+	requiredFieldsStrict bool
+	requiredFieldsMu     sync.RWMutex
+)
+
+// SetRequiredFields set the field keys that the logs of the message pattern must have.
+// pattern is the message, or the prefix of the messages if it ends with "*".
+// The log without them is written as it is, and the internal WARN log of MissingRequiredFieldsMessage is written.
+// See SetRequiredFieldsStrict to fail by them.
+// e.g. SetRequiredFields("AUDIT_*", "actor", "action")
+func SetRequiredFields(pattern string, keys ...string) {
+	requiredFieldsMu.Lock()
+	defer requiredFieldsMu.Unlock()
+	requiredFieldsRules = append(requiredFieldsRules, requiredFieldsRule{pattern: pattern, keys: keys})
+}
+
+// SetRequiredFieldsByLogger set the field keys that the logs of the logger named loggerName must have.
+// The logs of the child loggers such as "loggerName.child" are also checked. See: SetRequiredFields
+func SetRequiredFieldsByLogger(loggerName string, keys ...string) {
+	requiredFieldsMu.Lock()
+	defer requiredFieldsMu.Unlock()
+	requiredFieldsRules = append(requiredFieldsRules, requiredFieldsRule{logger: loggerName, keys: keys})
+}
+
+// SetRequiredFieldsStrict set whether the logs without the required fields fail instead of the WARN log.
+// It panics, or fails the test if it is initialized by InitForTest. It is useful during development.
+func SetRequiredFieldsStrict(val bool) {
+	requiredFieldsStrict = val
+}
+
+func (r requiredFieldsRule) match(ent zapcore.Entry) bool {
+	if r.logger != "" {
+		return ent.LoggerName == r.logger || strings.HasPrefix(ent.LoggerName, r.logger+".")
+	}
+	if prefix, ok := strings.CutSuffix(r.pattern, "*"); ok {
+		return strings.HasPrefix(ent.Message, prefix)
+	}
+	return ent.Message == r.pattern
+}
+
+// missingRequiredFields returns the required keys of ent that are not in keys.
+func missingRequiredFields(ent zapcore.Entry, keys map[string]struct{}) []string {
+	requiredFieldsMu.RLock()
+	defer requiredFieldsMu.RUnlock()
+	var missing []string
+	for _, r := range requiredFieldsRules {
+		if !r.match(ent) {
+			continue
+		}
+		for _, k := range r.keys {
+			if _, ok := keys[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+	}
+	return missing
+}
+
+func reportMissingRequiredFields(ent zapcore.Entry, missing []string) {
+	if requiredFieldsStrict {
+		msg := fmt.Sprintf("zl: %s log %q is missing the required fields: %s",
+			ent.Level.CapitalString(), ent.Message, strings.Join(missing, ", "))
+		if testTB != nil {
+			testTB.Errorf("%s", msg)
+			return
+		}
+		panic(msg)
+	}
+	if internalLogger == nil {
+		return
+	}
+	iWarn(MissingRequiredFieldsMessage,
+		zap.String("log_message", ent.Message),
+		zap.String("log_logger", ent.LoggerName),
+		zap.Strings("missing", missing),
+	)
+}
+
+// requiredFieldsCore is a zapcore.Core that checks the required fields.
+type requiredFieldsCore struct {
+	zapcore.Core
+	keys map[string]struct{} // keys is the keys of the fields added by With.
+}
+
+func newRequiredFieldsCore(core zapcore.Core) zapcore.Core {
+	requiredFieldsMu.RLock()
+	defer requiredFieldsMu.RUnlock()
+	if len(requiredFieldsRules) == 0 {
+		return core
+	}
+	return &requiredFieldsCore{Core: core}
+}
+
+func (c *requiredFieldsCore) With(fields []zap.Field) zapcore.Core {
+	keys := make(map[string]struct{}, len(c.keys)+len(fields))
+	for k := range c.keys {
+		keys[k] = struct{}{}
+	}
+	for i := range fields {
+		keys[fields[i].Key] = struct{}{}
+	}
+	return &requiredFieldsCore{Core: c.Core.With(fields), keys: keys}
+}
+
+func (c *requiredFieldsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *requiredFieldsCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if ent.Message != MissingRequiredFieldsMessage {
+		keys := c.keys
+		if len(fields) > 0 {
+			keys = make(map[string]struct{}, len(c.keys)+len(fields))
+			for k := range c.keys {
+				keys[k] = struct{}{}
+			}
+			for i := range fields {
+				keys[fields[i].Key] = struct{}{}
+			}
+		}
+		if missing := missingRequiredFields(ent, keys); missing != nil {
+			defer reportMissingRequiredFields(ent, missing)
+		}
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func resetRequiredFields() {
+	requiredFieldsMu.Lock()
+	defer requiredFieldsMu.Unlock()
+	requiredFieldsRules = nil
+	requiredFieldsStrict = false
+}
@@ -0,0 +1,59 @@
+package zl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetRequiredFields(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetRequiredFields("AUDIT_*", "actor", "action")
+	SetRequiredFields("LOGIN", "user_id")
+	SetRequiredFieldsByLogger("payment", "order_id")
+	Init()
+
+	Info("AUDIT_USER_DELETED", zap.String("actor", "alice"), zap.String("action", "delete"))
+	New(zap.String("actor", "alice")).Info("AUDIT_ROLE_CHANGED", zap.String("action", "change"))
+	Info("LOGIN_FAILED") // It does not match "LOGIN".
+	Info("AUDIT_EXPORTED", zap.String("actor", "alice"))
+	Info("LOGIN")
+	New().Named("payment").Named("card").Warn("CHARGED")
+	New().Named("payments").Warn("CHARGED")
+
+	violations := obs.FilterMessage(MissingRequiredFieldsMessage).All()
+	assert.Len(t, violations, 3)
+	assert.Equal(t, "AUDIT_EXPORTED", violations[0].ContextMap()["log_message"])
+	assert.Equal(t, []interface{}{"action"}, violations[0].ContextMap()["missing"])
+	assert.Equal(t, "LOGIN", violations[1].ContextMap()["log_message"])
+	assert.Equal(t, "payment.card", violations[2].ContextMap()["log_logger"])
+	assert.Equal(t, []interface{}{"order_id"}, violations[2].ContextMap()["missing"])
+	assert.Equal(t, 1, obs.FilterMessage("AUDIT_EXPORTED").Len(), "the log is written")
+}
+
+func TestSetRequiredFieldsStrict(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetRequiredFields("AUDIT_*", "actor")
+	SetRequiredFieldsStrict(true)
+	Init()
+
+	assert.PanicsWithValue(t, `zl: INFO log "AUDIT_EXPORTED" is missing the required fields: actor`, func() {
+		Info("AUDIT_EXPORTED")
+	})
+	assert.NotPanics(t, func() { Info("AUDIT_EXPORTED", zap.String("actor", "alice")) })
+	ResetGlobalLoggerSettings()
+
+	tb := &recordTB{TB: t}
+	SetRequiredFields("AUDIT_*", "actor")
+	SetRequiredFieldsStrict(true)
+	InitForTest(tb)
+	Info("AUDIT_EXPORTED")
+	assert.Equal(t, []string{`zl: INFO log "AUDIT_EXPORTED" is missing the required fields: actor`}, tb.errors)
+	ResetGlobalLoggerSettings()
+}
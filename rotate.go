@@ -1,6 +1,16 @@
 package zl
 
 import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -18,24 +28,498 @@ var (
 	maxAge     int
 	localTime  bool
 	compress   bool
+	interval   time.Duration
+	onRotate   func(oldPath string)
+	fileMode   os.FileMode
+	dirMode    os.FileMode
+	fileUID    = -1
+	fileGID    = -1
+	reopen     bool
+
+	rotateNow = time.Now
+
+	rotators   = make(map[string]*rotator)
+	rotatorsMu sync.Mutex
+)
+
+const (
+	megabyte = 1024 * 1024
+
+	// reopenInterval is the interval to check if the log file has been moved. See: SetRotateReopenOnMove
+	reopenInterval = time.Second
 )
 
+// rotator is a wrapper of lumberjack.Logger.
+// It rotates the log file by the interval in addition to the size,
+// and switches the log file when the expanded file name template changes.
+type rotator struct {
+	*lumberjack.Logger
+	template   string
+	interval   time.Duration
+	nextRotate time.Time
+	mu         sync.Mutex
+	prepared   bool
+
+	// The following fields are used by SetRotateReopenOnMove.
+	reopen     bool
+	fileInfo   os.FileInfo
+	lastReopen time.Time
+
+	// The following fields are used when the rotation is managed by the rotator.
+	// The rotator rotates and compresses the files by itself to know the rotated file names.
+	// See: SetOnRotate, SetRotateCompression
+	managed  bool
+	onRotate func(oldPath string)
+	compress bool
+	codec    Compression
+	level    int
+	size     int64
+	opened   bool
+	hooks    sync.WaitGroup
+}
+
 // newRotator
 // See: https://github.com/natefinch/lumberjack
 // See: https://github.com/uber-go/zap/blob/master/FAQ.md#does-zap-support-log-rotation
-func newRotator() *lumberjack.Logger {
+func newRotator() *rotator {
 	setRotateDefault()
-	res := &lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    maxSize,
-		MaxBackups: maxBackups,
-		MaxAge:     maxAge,
-		LocalTime:  localTime,
-		Compress:   compress,
+	return newFileRotator(fileName)
+}
+
+// getRotator returns the rotator of the file name template shared by the loggers.
+// The file must be opened only once, because lumberjack overwrites the logs of the other rotators
+// and rotates the file independently.
+func getRotator(template string) *rotator {
+	rotatorsMu.Lock()
+	defer rotatorsMu.Unlock()
+	if r, ok := rotators[template]; ok {
+		return r
+	}
+	setRotateDefault()
+	r := newFileRotator(template)
+	rotators[template] = r
+	return r
+}
+
+func closeRotators() {
+	rotatorsMu.Lock()
+	defer rotatorsMu.Unlock()
+	for _, r := range rotators {
+		_ = r.Close()
+	}
+	rotators = make(map[string]*rotator)
+}
+
+// newFileRotator returns the rotator of the file name template with the global settings.
+func newFileRotator(template string) *rotator {
+	res := &rotator{
+		Logger: &lumberjack.Logger{
+			Filename:   expandFileName(template, rotateNow()),
+			MaxSize:    maxSize,
+			MaxBackups: maxBackups,
+			MaxAge:     maxAge,
+			LocalTime:  localTime,
+			Compress:   compress,
+		},
+		template: template,
+		interval: interval,
+		onRotate: onRotate,
+		reopen:   reopen,
+		codec:    compression,
+		level:    compressionLevel,
+	}
+	res.managed = onRotate != nil || (compress && (compression != CompressionGzip || compressionLevel != 0))
+	if res.managed {
+		res.compress, res.Compress = res.Compress, false
 	}
 	return res
 }
 
+// Write writes p to the log file.
+// The log file is switched if the file name has changed, and is rotated if the interval boundary has passed.
+// It is not written while the free space is low. See: SetDiskGuard
+func (r *rotator) Write(p []byte) (int, error) {
+	var warn func()
+	defer func() {
+		if warn != nil {
+			warn()
+		}
+	}()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := rotateNow()
+	if diskGuardMinFree > 0 {
+		var low bool
+		if low, warn = r.lowDiskSpace(now); low {
+			return r.writeConsoleOnly(p)
+		}
+	}
+	if err := r.switchFile(now); err != nil {
+		return 0, err
+	}
+	if r.reopen {
+		if err := r.reopenIfMoved(now); err != nil {
+			return 0, err
+		}
+	}
+	if !r.prepared {
+		r.prepared = true
+		if err := prepareFile(r.Filename); err != nil {
+			return 0, err
+		}
+	}
+	if r.interval > 0 {
+		if err := r.rotateByInterval(now); err != nil {
+			return 0, err
+		}
+	}
+	if !r.opened {
+		r.opened = true
+		if info, err := os.Stat(r.Filename); err == nil {
+			r.size = info.Size()
+		}
+		r.removeExpansionBackups(now)
+	}
+	rotated := false
+	if r.size > 0 && r.size+int64(len(p)) > int64(r.MaxSize)*megabyte {
+		if r.managed {
+			if err := r.rotate(); err != nil {
+				return 0, err
+			}
+		} else {
+			// lumberjack rotates the file in Write.
+			statsRotations.Add(1)
+			r.size = 0
+		}
+		rotated = true
+	}
+	n, err := r.Logger.Write(p)
+	r.size += int64(n)
+	if rotated {
+		r.removeExpansionBackups(now)
+	}
+	return n, err
+}
+
+// reopenIfMoved closes the log file if it has been renamed or removed by an external tool such as logrotate.
+// The log file is checked every reopenInterval, and the new file is opened by the next write.
+func (r *rotator) reopenIfMoved(now time.Time) error {
+	if now.Sub(r.lastReopen) < reopenInterval {
+		return nil
+	}
+	r.lastReopen = now
+	info, err := os.Stat(r.Filename)
+	if err == nil && r.fileInfo != nil && os.SameFile(info, r.fileInfo) {
+		return nil
+	}
+	if r.fileInfo != nil {
+		if err := r.Logger.Close(); err != nil {
+			return err
+		}
+		r.opened, r.size, r.prepared = false, 0, false
+	}
+	// The file does not exist until the next write if it has been moved.
+	r.fileInfo = info
+	if err != nil {
+		r.lastReopen = time.Time{}
+	}
+	return nil
+}
+
+// writeConsoleOnly writes p to the console instead of the log file if the logs are not written to the console.
+func (r *rotator) writeConsoleOnly(p []byte) (int, error) {
+	if outputType == FileOutput {
+		return getConsoleOutput().Write(p)
+	}
+	return len(p), nil
+}
+
+// Close closes the log file after the running SetOnRotate functions are finished.
+func (r *rotator) Close() error {
+	r.hooks.Wait()
+	return r.Logger.Close()
+}
+
+// rotate rotates the log file and calls onRotate with the rotated file.
+func (r *rotator) rotate() error {
+	if err := r.Rotate(); err != nil {
+		return err
+	}
+	statsRotations.Add(1)
+	r.size = 0
+	if !r.managed {
+		return nil
+	}
+	if backup := r.latestBackup(); backup != "" {
+		r.afterRotate(backup)
+	}
+	return nil
+}
+
+// latestBackup returns the latest uncompressed backup file of the current file created by lumberjack.
+// ex. app-2006-01-02T15-04-05.000.jsonl
+// The live files of the other loggers in the directory such as app-access.jsonl of SetNamedFile are not returned.
+func (r *rotator) latestBackup() string {
+	files, err := r.listBackups()
+	if err != nil {
+		return ""
+	}
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(r.Filename), ext) + "-"
+	for _, f := range files {
+		name := filepath.Base(f.path)
+		if f.backup && strings.HasPrefix(name, prefix) && filepath.Ext(name) == ext {
+			return f.path
+		}
+	}
+	return ""
+}
+
+// afterRotate compresses the rotated file if needed and calls onRotate in the background.
+func (r *rotator) afterRotate(oldPath string) {
+	r.hooks.Add(1)
+	go func() {
+		defer r.hooks.Done()
+		if r.compress {
+			compressed, err := compressFile(oldPath, r.codec, r.level)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			oldPath = compressed
+			if r.codec != CompressionGzip {
+				r.removeOldBackups(r.codec.extension())
+			}
+		}
+		if r.onRotate != nil {
+			r.onRotate(oldPath)
+		}
+	}()
+}
+
+// switchFile closes the current file if the expanded file name has changed. e.g. The date has changed.
+// The new file is opened by the next write.
+func (r *rotator) switchFile(now time.Time) error {
+	name := expandFileName(r.template, now)
+	if name == r.Filename {
+		return nil
+	}
+	if err := r.Logger.Close(); err != nil {
+		return err
+	}
+	oldPath := r.Filename
+	r.prepared = false
+	// The logger is replaced because the Filename is read by the goroutine of lumberjack.
+	r.Logger = &lumberjack.Logger{
+		Filename:   name,
+		MaxSize:    r.MaxSize,
+		MaxBackups: r.MaxBackups,
+		MaxAge:     r.MaxAge,
+		LocalTime:  r.LocalTime,
+		Compress:   r.Compress,
+	}
+	if r.managed && r.opened {
+		r.opened, r.size = false, 0
+		r.afterRotate(oldPath)
+	}
+	return nil
+}
+
+func (r *rotator) rotateByInterval(now time.Time) error {
+	if r.nextRotate.IsZero() {
+		r.nextRotate = nextRotateTime(now, r.interval, r.location())
+		// Rotate the file written in the previous period. e.g. It is restarted the next day.
+		info, err := os.Stat(r.Filename)
+		if err != nil || !info.ModTime().Before(r.nextRotate.Add(-r.interval)) {
+			return nil
+		}
+		return r.rotate()
+	}
+	if now.Before(r.nextRotate) {
+		return nil
+	}
+	r.nextRotate = nextRotateTime(now, r.interval, r.location())
+	return r.rotate()
+}
+
+func (r *rotator) location() *time.Location {
+	if r.LocalTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// nextRotateTime returns the next interval boundary after t.
+// The boundaries are aligned to midnight in loc. e.g. 6h rotates at 00:00, 06:00, 12:00 and 18:00.
+func nextRotateTime(t time.Time, interval time.Duration, loc *time.Location) time.Time {
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	const day = 24 * time.Hour
+	if interval >= day {
+		return midnight.AddDate(0, 0, int(interval/day))
+	}
+	return midnight.Add((t.Sub(midnight)/interval + 1) * interval)
+}
+
+// prepareFile creates the log file and the parent directory with the mode and the owner
+// set by SetRotateFileMode, SetRotateDirMode and SetRotateFileOwner.
+// lumberjack creates the rotated files with the same mode and owner as the existing file.
+func prepareFile(name string) error {
+	if fileMode == 0 && dirMode == 0 && fileUID < 0 && fileGID < 0 {
+		return nil
+	}
+	if dir := filepath.Dir(name); dirMode != 0 {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			if err := os.MkdirAll(dir, dirMode); err != nil {
+				return err
+			}
+			if err := os.Chmod(dir, dirMode); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := os.Stat(name); err == nil {
+		return nil
+	}
+	mode := fileMode
+	if mode == 0 {
+		mode = 0o600 // The same as lumberjack.
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// The mode is set again because OpenFile is affected by umask.
+	if err := os.Chmod(name, mode); err != nil {
+		return err
+	}
+	if fileUID >= 0 || fileGID >= 0 {
+		return os.Chown(name, fileUID, fileGID)
+	}
+	return nil
+}
+
+// expandFileName expands the placeholders of the file name template.
+// {date} is the date of t in UTC, or local time if SetRotateLocalTime is enabled.
+func expandFileName(template string, t time.Time) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	if localTime {
+		t = t.Local()
+	} else {
+		t = t.UTC()
+	}
+	var host string
+	if h := cachedHost(); h != nil {
+		host = *h
+	}
+	return strings.NewReplacer(
+		"{date}", t.Format("2006-01-02"),
+		"{hostname}", host,
+		"{pid}", strconv.Itoa(os.Getpid()),
+	).Replace(template)
+}
+
+// templatePlaceholders is the regular expressions of the placeholders of the file name template.
+var templatePlaceholders = map[string]string{
+	"{date}":     `\d{4}-\d{2}-\d{2}`,
+	"{hostname}": `[A-Za-z0-9._-]+?`,
+	"{pid}":      `\d+`,
+}
+
+// backupFile is a file of the file name template other than the current file.
+type backupFile struct {
+	path string
+	// time is the timestamp of the backup name, or the modification time of the file of another expansion.
+	time time.Time
+	// backup is true for the files rotated by lumberjack. ex. app-2006-01-02T15-04-05.000.jsonl.gz
+	// It is false for the files of the other expansions of the template. ex. app-2006-01-01.jsonl of {date}
+	backup bool
+}
+
+// listBackups returns the files of the template in the directory of the current file, the newest first.
+// The files of all the expansions of the template are listed because lumberjack only finds the backups
+// of the current file name. The current file and the other files in the directory are never listed.
+func (r *rotator) listBackups() ([]backupFile, error) {
+	dir := filepath.Dir(r.Filename)
+	ext := filepath.Ext(r.Filename)
+	current := filepath.Base(r.Filename)
+	base := regexp.QuoteMeta(strings.TrimSuffix(current, ext))
+	if name := filepath.Base(r.template); strings.Contains(name, "{") && !strings.Contains(filepath.Dir(r.template), "{") {
+		base = regexp.QuoteMeta(strings.TrimSuffix(name, filepath.Ext(name)))
+		for placeholder, re := range templatePlaceholders {
+			base = strings.ReplaceAll(base, regexp.QuoteMeta(placeholder), re)
+		}
+	}
+	re, err := regexp.Compile(`^(?:` + base + `)(?:-(\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}))?` +
+		regexp.QuoteMeta(ext) + `(?:` + regexp.QuoteMeta(CompressionGzip.extension()) + `|` +
+		regexp.QuoteMeta(CompressionZstd.extension()) + `)?$`)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []backupFile
+	for _, entry := range entries {
+		m := re.FindStringSubmatch(entry.Name())
+		if m == nil || entry.Name() == current || entry.IsDir() {
+			continue
+		}
+		f := backupFile{path: filepath.Join(dir, entry.Name()), backup: m[1] != ""}
+		if f.backup {
+			if f.time, err = time.ParseInLocation(backupTimeFormat, m[1], r.location()); err != nil {
+				continue
+			}
+		} else {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			f.time = info.ModTime()
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].time.After(files[j].time) })
+	return files, nil
+}
+
+// removeExpansionBackups removes the files of the other expansions of the template by MaxBackups and MaxAge.
+// The backups of all the expansions are counted together by MaxBackups,
+// and the files of the other expansions are removed only by MaxAge because they may be written by another process.
+func (r *rotator) removeExpansionBackups(now time.Time) {
+	if !strings.Contains(r.template, "{") {
+		return
+	}
+	files, err := r.listBackups()
+	if err != nil {
+		return
+	}
+	cutoff := now.Add(-time.Duration(r.MaxAge) * 24 * time.Hour)
+	backups := 0
+	for _, f := range files {
+		if f.backup {
+			backups++
+		}
+		if (f.backup && r.MaxBackups > 0 && backups > r.MaxBackups) || (r.MaxAge > 0 && f.time.Before(cutoff)) {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// currentFileName returns the file name that logs are currently written to.
+func currentFileName() string {
+	return expandFileName(fileName, rotateNow())
+}
+
 func setRotateDefault() {
 	if fileName == "" {
 		fileName = FileNameDefault
@@ -52,6 +536,15 @@ func setRotateDefault() {
 }
 
 // SetRotateFileName set the file to write logs to.
+// The following placeholders can be used so that multiple instances on one host never write to the same file.
+//   - {date}: the date. ex. 2006-01-02. The log file is switched when the date changes.
+//   - {hostname}: the hostname.
+//   - {pid}: the process ID.
+//
+// e.g. zl.SetRotateFileName("./log/app-{date}-{hostname}-{pid}.jsonl")
+// SetRotateMaxBackups and SetRotateMaxAge are applied to the files of all the expansions in the directory of the file.
+// The files of the other expansions, such as the file of the previous date or of an exited process,
+// are removed by MaxAge by their modification time. The placeholders in the directory are not supported by them.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateFileName(val string) {
 	fileName = val
@@ -82,7 +575,53 @@ func SetRotateLocalTime(val bool) {
 }
 
 // SetRotateCompress determines if the rotated log files should be compressed using gzip.
+// The format and the level can be changed by SetRotateCompression and SetRotateCompressionLevel.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateCompress(val bool) {
 	compress = val
 }
+
+// SetRotateInterval set the interval to rotate the log file in addition to the size. e.g. 24*time.Hour, time.Hour
+// The boundaries are aligned to midnight in UTC, or local time if SetRotateLocalTime is enabled.
+// The rotated files are named with the timestamp. ex. app-2006-01-02T15-04-05.000.jsonl
+// The interval of a day or more is rounded down to days.
+// 0 (default) means the log file is rotated only by the size.
+func SetRotateInterval(val time.Duration) {
+	interval = val
+}
+
+// SetOnRotate set the function called with the path of the rotated file after the log file is rotated.
+// It is also called with the previous file when the file name is switched by the {date} placeholder.
+// The function is called in the background, and the path is the compressed file if SetRotateCompress is enabled.
+// It can be used to archive the rotated files to object storage. See: NewArchiver
+func SetOnRotate(fn func(oldPath string)) {
+	onRotate = fn
+}
+
+// SetRotateFileMode set the permission of the log file. e.g. 0o600
+// It is applied when the log file is created, and the rotated files keep the same permission.
+// Default is 0600, the same as lumberjack.
+func SetRotateFileMode(mode os.FileMode) {
+	fileMode = mode
+}
+
+// SetRotateDirMode set the permission of the parent directory of the log file. e.g. 0o700
+// The directory is created with the permission if it does not exist.
+// Default is 0755, the same as lumberjack.
+func SetRotateDirMode(mode os.FileMode) {
+	dirMode = mode
+}
+
+// SetRotateFileOwner set the owner of the log file. -1 means not changed.
+// It is applied when the log file is created, and the rotated files keep the same owner.
+// It works only on Unix.
+func SetRotateFileOwner(uid, gid int) {
+	fileUID, fileGID = uid, gid
+}
+
+// SetRotateReopenOnMove determines if the log file is reopened when it has been renamed or removed
+// by an external tool such as logrotate (without copytruncate).
+// The log file is checked every second.
+func SetRotateReopenOnMove(val bool) {
+	reopen = val
+}
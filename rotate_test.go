@@ -1,7 +1,11 @@
 package zl
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +38,304 @@ func Test_newRotator(t *testing.T) {
 	})
 
 }
+
+func Test_nextRotateTime(t *testing.T) {
+	tm := time.Date(2023, 9, 9, 15, 53, 17, 0, time.UTC)
+	tests := []struct {
+		interval time.Duration
+		expected time.Time
+	}{
+		{24 * time.Hour, time.Date(2023, 9, 10, 0, 0, 0, 0, time.UTC)},
+		{48 * time.Hour, time.Date(2023, 9, 11, 0, 0, 0, 0, time.UTC)},
+		{6 * time.Hour, time.Date(2023, 9, 9, 18, 0, 0, 0, time.UTC)},
+		{time.Hour, time.Date(2023, 9, 9, 16, 0, 0, 0, time.UTC)},
+		{15 * time.Minute, time.Date(2023, 9, 9, 16, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, nextRotateTime(tm, tt.interval, time.UTC))
+		})
+	}
+
+	jst := time.FixedZone("JST", 9*60*60)
+	assert.Equal(t, time.Date(2023, 9, 11, 0, 0, 0, 0, jst), nextRotateTime(tm, 24*time.Hour, jst))
+}
+
+func TestSetRotateInterval(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	now := time.Date(2023, 9, 9, 23, 59, 0, 0, time.UTC)
+	rotateNow = func() time.Time { return now }
+
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetRotateInterval(24 * time.Hour)
+	r := newRotator()
+	_, err := r.Write([]byte("day1\n"))
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = r.Write([]byte("day2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "app*.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	b, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day2\n", string(b))
+
+	t.Run("restart in the next period", func(t *testing.T) {
+		// The modification time is the real time, so it is set to the fake time.
+		assert.NoError(t, os.Chtimes(filepath.Join(dir, "app.jsonl"), now, now))
+		time.Sleep(2 * time.Millisecond) // The backup file name is the real time in milliseconds.
+		now = now.Add(24 * time.Hour)
+		r := newRotator()
+		_, err := r.Write([]byte("day3\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		files, err := filepath.Glob(filepath.Join(dir, "app*.jsonl"))
+		assert.NoError(t, err)
+		assert.Len(t, files, 3)
+	})
+	rotateNow = time.Now
+	ResetGlobalLoggerSettings()
+}
+
+func Test_expandFileName(t *testing.T) {
+	tm := time.Date(2023, 9, 9, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	host, _ := os.Hostname()
+	assert.Equal(t, "./log/app.jsonl", expandFileName("./log/app.jsonl", tm))
+	assert.Equal(t,
+		fmt.Sprintf("./log/app-2023-09-09-%s-%d.jsonl", host, os.Getpid()),
+		expandFileName("./log/app-{date}-{hostname}-{pid}.jsonl", tm),
+	)
+
+	SetRotateLocalTime(true)
+	assert.Equal(t, "./log/app-"+tm.Local().Format("2006-01-02")+".jsonl", expandFileName("./log/app-{date}.jsonl", tm))
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetRotateFileName_template(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	now := time.Date(2023, 9, 9, 23, 59, 0, 0, time.UTC)
+	rotateNow = func() time.Time { return now }
+
+	SetRotateFileName(filepath.Join(dir, "app-{date}.jsonl"))
+	r := newRotator()
+	_, err := r.Write([]byte("day1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "app-2023-09-09.jsonl"), currentFileName())
+
+	now = now.Add(2 * time.Minute)
+	_, err = r.Write([]byte("day2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, filepath.Join(dir, "app-2023-09-10.jsonl"), currentFileName())
+
+	b, err := os.ReadFile(filepath.Join(dir, "app-2023-09-09.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day1\n", string(b))
+	b, err = os.ReadFile(filepath.Join(dir, "app-2023-09-10.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day2\n", string(b))
+	rotateNow = time.Now
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetRotateFileName_templateRetention(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	backup := func(pid int, age time.Duration, ext string) string {
+		name := fmt.Sprintf("app-%d-%s.jsonl%s", pid, now.Add(-age).Format(backupTimeFormat), ext)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o600))
+		return name
+	}
+	// The files of the previous process.
+	oldPID := os.Getpid() + 1
+	kept := []string{
+		backup(oldPID, time.Hour, ".gz"),
+		backup(os.Getpid(), 2*time.Hour, ""),
+	}
+	removed := []string{
+		backup(oldPID, 3*time.Hour, ""),
+		backup(oldPID, 10*24*time.Hour, ".gz"),
+		fmt.Sprintf("app-%d.jsonl", oldPID+1),
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, removed[2]), []byte("old\n"), 0o600))
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, removed[2]), now.Add(-10*24*time.Hour), now.Add(-10*24*time.Hour)))
+	// The files of another process and the other loggers are not removed.
+	kept = append(kept, fmt.Sprintf("app-%d.jsonl", oldPID), "app-access.jsonl", "app-access-2020-01-01T00-00-00.000.jsonl")
+	for _, name := range kept[2:] {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("live\n"), 0o600))
+	}
+
+	SetRotateFileName(filepath.Join(dir, "app-{pid}.jsonl"))
+	SetRotateMaxBackups(2)
+	SetRotateMaxAge(1)
+	r := newRotator()
+	_, err := r.Write([]byte("new\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	for _, name := range kept {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+	for _, name := range removed {
+		assert.NoFileExists(t, filepath.Join(dir, name))
+	}
+	assert.FileExists(t, filepath.Join(dir, fmt.Sprintf("app-%d.jsonl", os.Getpid())))
+}
+
+func TestSetOnRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		ext      string
+	}{
+		{"uncompressed", false, ".jsonl"},
+		{"compressed", true, ".jsonl.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetGlobalLoggerSettings()
+			dir := t.TempDir()
+			var rotated []string
+			SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+			SetRotateMaxSize(1)
+			SetRotateCompress(tt.compress)
+			SetOnRotate(func(oldPath string) { rotated = append(rotated, oldPath) })
+
+			r := newRotator()
+			assert.False(t, r.Compress)
+			line := make([]byte, 600*1024)
+			_, err := r.Write(line)
+			assert.NoError(t, err)
+			_, err = r.Write(line)
+			assert.NoError(t, err)
+			assert.NoError(t, r.Close())
+
+			assert.Len(t, rotated, 1)
+			assert.Regexp(t, `app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}`+tt.ext+"$", rotated[0])
+			info, err := os.Stat(rotated[0])
+			assert.NoError(t, err)
+			if !tt.compress {
+				assert.Equal(t, int64(len(line)), info.Size())
+			}
+			ResetGlobalLoggerSettings()
+		})
+	}
+
+	t.Run("named file in the same directory", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		named := filepath.Join(dir, "app-access.jsonl")
+		assert.NoError(t, os.WriteFile(named, []byte("access\n"), 0o600))
+		var rotated []string
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		SetRotateMaxSize(1)
+		SetOnRotate(func(oldPath string) { rotated = append(rotated, oldPath) })
+
+		r := newRotator()
+		line := make([]byte, 600*1024)
+		_, err := r.Write(line)
+		assert.NoError(t, err)
+		_, err = r.Write(line)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+
+		if assert.Len(t, rotated, 1) {
+			assert.Regexp(t, `app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}\.jsonl$`, rotated[0])
+		}
+		assert.FileExists(t, named)
+		ResetGlobalLoggerSettings()
+	})
+
+	t.Run("file name switched", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		dir := t.TempDir()
+		now := time.Date(2023, 9, 9, 23, 59, 0, 0, time.UTC)
+		rotateNow = func() time.Time { return now }
+		var rotated []string
+		SetRotateFileName(filepath.Join(dir, "app-{date}.jsonl"))
+		SetOnRotate(func(oldPath string) { rotated = append(rotated, oldPath) })
+
+		r := newRotator()
+		_, err := r.Write([]byte("day1\n"))
+		assert.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+		_, err = r.Write([]byte("day2\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, []string{filepath.Join(dir, "app-2023-09-09.jsonl")}, rotated)
+		rotateNow = time.Now
+		ResetGlobalLoggerSettings()
+	})
+}
+
+func TestSetRotateFileMode(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := filepath.Join(t.TempDir(), "log")
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetRotateFileMode(0o640)
+	SetRotateDirMode(0o750)
+	SetRotateFileOwner(os.Getuid(), os.Getgid())
+
+	r := newRotator()
+	_, err := r.Write([]byte("test\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.rotate())
+	_, err = r.Write([]byte("test\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, f := range files {
+		info, err := os.Stat(f)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm(), f)
+	}
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetRotateReopenOnMove(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	dir := t.TempDir()
+	now := time.Date(2023, 9, 9, 0, 0, 0, 0, time.UTC)
+	rotateNow = func() time.Time { return now }
+	name := filepath.Join(dir, "app.jsonl")
+	SetRotateFileName(name)
+	SetRotateReopenOnMove(true)
+
+	r := newRotator()
+	write := func(s string) {
+		now = now.Add(reopenInterval)
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	write("1\n")
+	write("2\n")
+	assert.NoError(t, os.Rename(name, name+".1")) // e.g. logrotate
+	write("3\n")
+	write("4\n")
+	assert.NoError(t, os.Remove(name))
+	write("5\n")
+	assert.NoError(t, r.Close())
+
+	b, err := os.ReadFile(name + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n", string(b))
+	b, err = os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "5\n", string(b))
+	rotateNow = time.Now
+	ResetGlobalLoggerSettings()
+}
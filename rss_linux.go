@@ -0,0 +1,26 @@
+//go:build linux
+
+package zl
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// residentMemory returns the resident memory of the process in bytes from /proc/self/statm.
+func residentMemory() (uint64, bool) {
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := bytes.Fields(b)
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * uint64(os.Getpagesize()), true
+}
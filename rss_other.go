@@ -0,0 +1,8 @@
+//go:build !linux
+
+package zl
+
+// residentMemory is not supported on this platform, so WatchResources only watches the goroutine count.
+func residentMemory() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package zl
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RuntimeStatsKey is the field key of the runtime statistics added by SetRuntimeStats.
+const RuntimeStatsKey = "runtime"
+
+var (
+	runtimeStatsEnabled  bool
+	runtimeStatsLevel    zapcore.Level
+	runtimeStatsInterval time.Duration
+
+	runtimeStatsMu   sync.Mutex
+	runtimeStatsLast runtimeStats
+	runtimeStatsTime time.Time
+)
+
+// runtimeStats is the snapshot of the runtime statistics.
+type runtimeStats struct {
+	Goroutines int
+	HeapInuse  uint64
+	NumGC      uint32
+	GCPause    time.Duration // GCPause is the pause of the last GC.
+}
+
+// MarshalLogObject adds the statistics to enc.
+func (s runtimeStats) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("goroutines", s.Goroutines)
+	enc.AddUint64("heap_inuse", s.HeapInuse)
+	enc.AddUint32("num_gc", s.NumGC)
+	enc.AddDuration("gc_pause", s.GCPause)
+	return nil
+}
+
+// SetRuntimeStats adds the RuntimeStatsKey field of the goroutine count, the heap in use and the GC pause
+// to the logs of level or higher, so that the errors have the context of the resources. e.g. SetRuntimeStats(WarnLevel, time.Second)
+// The statistics are read at most once per interval and shared by the logs because reading them stops the world briefly.
+// 0 interval reads them for each log.
+func SetRuntimeStats(level zapcore.Level, interval time.Duration) {
+	runtimeStatsEnabled = true
+	runtimeStatsLevel = level
+	runtimeStatsInterval = interval
+}
+
+// readRuntimeStats returns the statistics read within runtimeStatsInterval.
+func readRuntimeStats() runtimeStats {
+	runtimeStatsMu.Lock()
+	defer runtimeStatsMu.Unlock()
+	now := clock.Now()
+	if !runtimeStatsTime.IsZero() && now.Sub(runtimeStatsTime) < runtimeStatsInterval {
+		return runtimeStatsLast
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	runtimeStatsLast = runtimeStats{
+		Goroutines: runtime.NumGoroutine(),
+		HeapInuse:  m.HeapInuse,
+		NumGC:      m.NumGC,
+	}
+	if m.NumGC > 0 {
+		runtimeStatsLast.GCPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+	runtimeStatsTime = now
+	return runtimeStatsLast
+}
+
+// runtimeStatsCore is a zapcore.Core that adds the RuntimeStatsKey field to the logs of runtimeStatsLevel or higher.
+type runtimeStatsCore struct {
+	zapcore.Core
+}
+
+func newRuntimeStatsCore(core zapcore.Core) zapcore.Core {
+	if !runtimeStatsEnabled {
+		return core
+	}
+	return &runtimeStatsCore{Core: core}
+}
+
+func (c *runtimeStatsCore) With(fields []zap.Field) zapcore.Core {
+	return &runtimeStatsCore{Core: c.Core.With(fields)}
+}
+
+func (c *runtimeStatsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *runtimeStatsCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if ent.Level >= runtimeStatsLevel {
+		fields = append(fields[:len(fields):len(fields)], zap.Object(RuntimeStatsKey, readRuntimeStats()))
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func resetRuntimeStats() {
+	runtimeStatsEnabled = false
+	runtimeStatsLevel = zapcore.InfoLevel
+	runtimeStatsInterval = 0
+	runtimeStatsMu.Lock()
+	defer runtimeStatsMu.Unlock()
+	runtimeStatsLast = runtimeStats{}
+	runtimeStatsTime = time.Time{}
+}
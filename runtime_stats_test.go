@@ -0,0 +1,63 @@
+package zl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRuntimeStats(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetRuntimeStats(WarnLevel, time.Hour)
+	Init()
+
+	Info("INFO")
+	Warn("WARN")
+	Error("ERROR")
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	got := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		stats, _ := entry[RuntimeStatsKey].(map[string]interface{})
+		got[entry["message"].(string)] = stats
+	}
+	assert.Nil(t, got["INFO"])
+	if assert.NotNil(t, got["WARN"]) {
+		assert.Greater(t, got["WARN"]["goroutines"], float64(0))
+		assert.Greater(t, got["WARN"]["heap_inuse"], float64(0))
+		assert.Contains(t, got["WARN"], "num_gc")
+		assert.Contains(t, got["WARN"], "gc_pause")
+	}
+	assert.Equal(t, got["WARN"], got["ERROR"], "the snapshot is shared within the interval")
+}
+
+func TestReadRuntimeStats(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	c := &fixedClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	SetClock(c)
+	SetRuntimeStats(WarnLevel, time.Minute)
+
+	first := readRuntimeStats()
+	read := runtimeStatsTime
+	c.t = c.t.Add(time.Second)
+	assert.Equal(t, first, readRuntimeStats(), "cached within the interval")
+	assert.Equal(t, read, runtimeStatsTime)
+
+	c.t = c.t.Add(time.Minute)
+	readRuntimeStats()
+	assert.Equal(t, c.t, runtimeStatsTime, "read again after the interval")
+}
@@ -0,0 +1,136 @@
+package zl
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// sampledCounters has the number of the calls of each key of Once and EveryN.
+var sampledCounters sync.Map
+
+// Sampled writes only the logs sampled by Once or EveryN.
+type Sampled struct {
+	logger     *Logger // logger is nil if it is created by the global Once or EveryN.
+	ok         bool
+	suppressed uint64
+}
+
+// Once returns a Sampled that writes the log only at the first call of key in the process.
+// e.g. zl.Once("DEPRECATED_API").Warn("DEPRECATED_API_CALLED")
+func Once(key string) *Sampled {
+	return sample(nil, key, 0)
+}
+
+// EveryN returns a Sampled that writes the log at the first and every n-th call of key.
+// The log has the SuppressedKey field of the number of the logs suppressed since the previous one.
+// It is used for the logs in the tight loops. e.g. zl.EveryN("ROW_SKIPPED", 100).Info("ROW_SKIPPED", zap.Int("row", i))
+func EveryN(key string, n int) *Sampled {
+	return sample(nil, key, n)
+}
+
+// Once returns a Sampled that writes the log of the Logger only at the first call of key. See: Once
+func (l *Logger) Once(key string) *Sampled {
+	return sample(l, key, 0)
+}
+
+// EveryN returns a Sampled that writes the log of the Logger at the first and every n-th call of key. See: EveryN
+func (l *Logger) EveryN(key string, n int) *Sampled {
+	return sample(l, key, n)
+}
+
+// sample counts the call of key. n is 0 for Once.
+func sample(l *Logger, key string, n int) *Sampled {
+	v, ok := sampledCounters.Load(key)
+	if !ok {
+		v, _ = sampledCounters.LoadOrStore(key, new(atomic.Uint64))
+	}
+	count := v.(*atomic.Uint64).Add(1)
+	s := &Sampled{logger: l}
+	switch {
+	case count == 1:
+		s.ok = true
+	case n > 1 && (count-1)%uint64(n) == 0:
+		s.ok, s.suppressed = true, uint64(n-1)
+	case n == 1:
+		s.ok = true
+	}
+	return s
+}
+
+// fields returns the fields with the SuppressedKey field.
+func (s *Sampled) fields(fields []zap.Field) []zap.Field {
+	if s.suppressed == 0 {
+		return fields
+	}
+	return append(fields, zap.Uint64(SuppressedKey, s.suppressed))
+}
+
+// Debug writes the DEBUG log if it is sampled.
+func (s *Sampled) Debug(message string, fields ...zap.Field) {
+	if !s.ok {
+		return
+	}
+	fields = s.fields(fields)
+	if s.logger == nil {
+		logger(message, DebugLevel, fields).Debug(message, fields...)
+		return
+	}
+	if s.logger.enabled(DebugLevel) {
+		fs := s.logger.appendFields(fields)
+		s.logger.logger(message, DebugLevel, fs.fields).Debug(message, fs.fields...)
+		fs.free()
+	}
+}
+
+// Info writes the INFO log if it is sampled.
+func (s *Sampled) Info(message string, fields ...zap.Field) {
+	if !s.ok {
+		return
+	}
+	fields = s.fields(fields)
+	if s.logger == nil {
+		logger(message, InfoLevel, fields).Info(message, fields...)
+		return
+	}
+	if s.logger.enabled(InfoLevel) {
+		fs := s.logger.appendFields(fields)
+		s.logger.logger(message, InfoLevel, fs.fields).Info(message, fs.fields...)
+		fs.free()
+	}
+}
+
+// Warn writes the WARN log if it is sampled.
+func (s *Sampled) Warn(message string, fields ...zap.Field) {
+	if !s.ok {
+		return
+	}
+	fields = s.fields(fields)
+	if s.logger == nil {
+		logger(message, WarnLevel, fields).Warn(message, fields...)
+		return
+	}
+	if s.logger.enabled(WarnLevel) {
+		fs := s.logger.appendFields(fields)
+		s.logger.logger(message, WarnLevel, fs.fields).Warn(message, fs.fields...)
+		fs.free()
+	}
+}
+
+// Error writes the ERROR log if it is sampled.
+func (s *Sampled) Error(message string, fields ...zap.Field) {
+	if !s.ok {
+		return
+	}
+	fields = s.fields(fields)
+	if s.logger == nil {
+		logger(message, ErrorLevel, fields).Error(message, fields...)
+		return
+	}
+	if s.logger.enabled(ErrorLevel) {
+		fs := s.logger.appendFields(fields)
+		s.logger.logger(message, ErrorLevel, fs.fields).Error(message, fs.fields...)
+		fs.free()
+	}
+}
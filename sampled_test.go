@@ -0,0 +1,43 @@
+package zl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestEveryN(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+
+	for i := 0; i < 25; i++ {
+		EveryN("ROW_SKIPPED", 10).Info("ROW_SKIPPED", zap.Int("row", i))
+		Once("DEPRECATED").Warn("DEPRECATED")
+	}
+	l := New()
+	for i := 0; i < 3; i++ {
+		l.Once("LOGGER_ONCE").Error("LOGGER_ONCE")
+		l.EveryN("LOGGER_EVERY", 1).Debug("LOGGER_EVERY")
+	}
+
+	rows := obs.FilterMessage("ROW_SKIPPED").All()
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, int64(0), rows[0].ContextMap()["row"])
+		assert.NotContains(t, rows[0].ContextMap(), SuppressedKey)
+		assert.Equal(t, int64(10), rows[1].ContextMap()["row"])
+		assert.Equal(t, uint64(9), rows[1].ContextMap()[SuppressedKey])
+		assert.Equal(t, int64(20), rows[2].ContextMap()["row"])
+	}
+	assert.Equal(t, 1, obs.FilterMessage("DEPRECATED").Len())
+	assert.Equal(t, 1, obs.FilterMessage("LOGGER_ONCE").FilterLevel(ErrorLevel).Len())
+	assert.Equal(t, 3, obs.FilterMessage("LOGGER_EVERY").Len())
+	for _, e := range obs.All()[1:] { // Skip INIT_LOGGER.
+		assert.True(t, strings.HasSuffix(e.Caller.File, "sampled_test.go"), e.Caller.File)
+	}
+}
@@ -0,0 +1,77 @@
+package zl
+
+import (
+	"regexp"
+)
+
+// Scrubber replaces the matches of Pattern in the messages and the string field values with Replacement.
+// Replacement can use the submatches such as $1. See: regexp.Regexp.ReplaceAllString
+type Scrubber struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// The built-in scrubbers. e.g. SetScrubbers(CreditCardScrubber, BearerTokenScrubber, EmailScrubber)
+var (
+	// CreditCardScrubber replaces the credit card numbers of 13 to 19 digits with or without the separators.
+	CreditCardScrubber = Scrubber{
+		Pattern:     regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Replacement: "[CREDIT_CARD]",
+	}
+
+	// BearerTokenScrubber replaces the tokens of the bearer authorization.
+	BearerTokenScrubber = Scrubber{
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "$1 [TOKEN]",
+	}
+
+	// EmailScrubber replaces the email addresses.
+	EmailScrubber = Scrubber{
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+)
+
+var scrubbers []Scrubber
+
+// SetScrubbers set the scrubbers that run over the messages, the string field values and the error messages.
+// It applies to all the outputs including the pretty console and the sinks.
+// The values in the objects are not scrubbed. Use SetRedactKeys for them.
+// e.g. SetScrubbers(EmailScrubber, Scrubber{Pattern: regexp.MustCompile(`sk_live_\w+`), Replacement: "[API_KEY]"})
+func SetScrubbers(scrubber ...Scrubber) {
+	scrubbers = scrubber
+}
+
+// AddScrubber adds the scrubber of the regular expression pattern to the scrubbers set by SetScrubbers.
+// It returns an error if pattern can not be compiled.
+func AddScrubber(pattern, replacement string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	scrubbers = append(scrubbers, Scrubber{Pattern: re, Replacement: replacement})
+	return nil
+}
+
+// scrubString returns s scrubbed by the scrubbers and true if it is changed.
+func scrubString(s string) (string, bool) {
+	ret := s
+	for i := range scrubbers {
+		ret = scrubbers[i].Pattern.ReplaceAllString(ret, scrubbers[i].Replacement)
+	}
+	return ret, ret != s
+}
+
+// scrubbedError is an error whose message is scrubbed.
+type scrubbedError struct {
+	err error
+}
+
+func (e scrubbedError) Error() string {
+	s, _ := scrubString(e.err.Error())
+	return s
+}
+
+func (e scrubbedError) Unwrap() error {
+	return e.err
+}
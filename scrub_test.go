@@ -0,0 +1,63 @@
+package zl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetScrubbers(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetScrubbers(CreditCardScrubber, BearerTokenScrubber, EmailScrubber)
+	assert.NoError(t, AddScrubber(`sk_live_\w+`, "[API_KEY]"))
+	Init()
+
+	Info("PAYMENT card=4111 1111 1111 1111", zap.String("card", "4111-1111-1111-1111"), zap.Int("amount", 100))
+	Info("REQUEST", zap.String("header", "Authorization: Bearer abc.def-123"), zap.String("key", "sk_live_abc123"))
+	WarnErr("SEND_FAILED", errors.New("send to alice@example.com"), zap.String("to", "bob@example.co.jp"))
+	Info("NOT_CHANGED", zap.String("id", "1234"))
+	Sync()
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"PAYMENT card=[CREDIT_CARD]","card":"[CREDIT_CARD]","amount":100}
+{"severity":"INFO","message":"REQUEST","header":"Authorization: Bearer [TOKEN]","key":"[API_KEY]"}
+{"severity":"WARN","message":"SEND_FAILED","to":"[EMAIL]","error":"send to [EMAIL]"}
+{"severity":"INFO","message":"NOT_CHANGED","id":"1234"}
+`, string(b))
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetScrubbers_pretty(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	var buf bytes.Buffer
+	SetOutput(PrettyOutput)
+	SetOmitKeys(TimeKey)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetPrettyFields(PrettyFieldsKeyValue)
+	SetScrubbers(Scrubber{Pattern: regexp.MustCompile(`secret-\d+`), Replacement: "***"})
+	SetColor(ColorNever)
+	Init()
+	pretty.Logger.SetOutput(&buf)
+
+	ErrorErr("LEAK secret-1", errors.New("invalid secret-2"), zap.String("value", "secret-3"))
+	assert.Contains(t, buf.String(), "LEAK *** invalid *** value=***\n")
+	assert.NotContains(t, buf.String(), "secret-")
+	ResetGlobalLoggerSettings()
+}
+
+func TestAddScrubber(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	assert.Error(t, AddScrubber(`(`, ""))
+	assert.Empty(t, scrubbers)
+}
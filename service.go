@@ -0,0 +1,108 @@
+package zl
+
+import (
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Schema is the naming convention of the ServiceKey, EnvironmentKey and VersionKey fields.
+type Schema int
+
+const (
+	// DefaultSchema outputs `service`, `env` and `version` fields.
+	// It is Default setting.
+	DefaultSchema Schema = iota
+
+	// ECSSchema outputs `service.name`, `service.environment` and `service.version` fields.
+	// See: https://www.elastic.co/guide/en/ecs/current/ecs-service.html
+	ECSSchema
+
+	// DatadogSchema outputs `service`, `env` and `version` fields (Unified Service Tagging).
+	// See: https://docs.datadoghq.com/getting_started/tagging/unified_service_tagging/
+	DatadogSchema
+
+	// GCPSchema outputs `serviceContext` (service, version) and
+	// `logging.googleapis.com/labels` (env) fields.
+	// See: https://cloud.google.com/error-reporting/docs/formatting-error-messages
+	GCPSchema
+)
+
+const (
+	gcpServiceContextKey = "serviceContext"
+	gcpLabelsKey         = "logging.googleapis.com/labels"
+)
+
+var schemaStrings = [4]string{
+	"Default",
+	"ECS",
+	"Datadog",
+	"GCP",
+}
+
+var (
+	serviceName string
+	environment string
+	schema      Schema
+)
+
+// String is return Schema type string.
+func (s Schema) String() string {
+	return schemaStrings[s]
+}
+
+// SetService set the name of the service.
+// It is used in the log output ServiceKey field.
+func SetService(name string) {
+	serviceName = name
+}
+
+// SetEnvironment set the environment the application is running in. ex. `production` or `staging`.
+// It is used in the log output EnvironmentKey field.
+func SetEnvironment(env string) {
+	environment = env
+}
+
+// SetSchema is set the naming convention of service, environment and version fields.
+// option can use (DefaultSchema, ECSSchema, DatadogSchema, GCPSchema).
+func SetSchema(option Schema) {
+	schema = option
+}
+
+// getServiceFields returns version, service and environment fields according to the schema.
+// The service and environment fields are output only when they are set.
+func getServiceFields() (fields []zapcore.Field) {
+	var ver string
+	if !lo.Contains(omitKeys, VersionKey) {
+		ver = GetVersion()
+	}
+
+	switch schema {
+	case ECSSchema:
+		fields = appendIfNotEmpty(fields, "service.version", ver)
+		fields = appendIfNotEmpty(fields, "service.name", serviceName)
+		fields = appendIfNotEmpty(fields, "service.environment", environment)
+	case GCPSchema:
+		var ctx []zapcore.Field
+		ctx = appendIfNotEmpty(ctx, "service", serviceName)
+		ctx = appendIfNotEmpty(ctx, "version", ver)
+		if ctx != nil {
+			fields = append(fields, zap.Dict(gcpServiceContextKey, ctx...))
+		}
+		if environment != "" {
+			fields = append(fields, zap.Dict(gcpLabelsKey, zap.String("env", environment)))
+		}
+	default:
+		fields = appendIfNotEmpty(fields, string(VersionKey), ver)
+		fields = appendIfNotEmpty(fields, string(ServiceKey), serviceName)
+		fields = appendIfNotEmpty(fields, string(EnvironmentKey), environment)
+	}
+	return fields
+}
+
+func appendIfNotEmpty(fields []zapcore.Field, key, val string) []zapcore.Field {
+	if val == "" {
+		return fields
+	}
+	return append(fields, zap.String(key, val))
+}
@@ -0,0 +1,50 @@
+package zl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_getServiceFields(t *testing.T) {
+	tests := []struct {
+		schema   Schema
+		expected string
+	}{
+		{DefaultSchema, `{"version":"v1.0.0","service":"api","env":"production"}`},
+		{ECSSchema, `{"service.version":"v1.0.0","service.name":"api","service.environment":"production"}`},
+		{DatadogSchema, `{"version":"v1.0.0","service":"api","env":"production"}`},
+		{
+			GCPSchema,
+			`{"serviceContext":{"service":"api","version":"v1.0.0"},` +
+				`"logging.googleapis.com/labels":{"env":"production"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema.String(), func(t *testing.T) {
+			SetVersion("v1.0.0")
+			SetService("api")
+			SetEnvironment("production")
+			SetSchema(tt.schema)
+
+			assert.JSONEq(t, tt.expected, encodeFields(t, getServiceFields()))
+			ResetGlobalLoggerSettings()
+		})
+	}
+
+	t.Run("not set", func(t *testing.T) {
+		SetOmitKeys(VersionKey)
+		assert.Empty(t, getServiceFields())
+		ResetGlobalLoggerSettings()
+	})
+}
+
+func encodeFields(t *testing.T, fields []zapcore.Field) string {
+	t.Helper()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, fields)
+	assert.NoError(t, err)
+	return string(bytes.TrimSpace(buf.Bytes()))
+}
@@ -0,0 +1,14 @@
+package zl
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+var sinks []zapcore.WriteSyncer
+
+// AddSink adds ws to the outputs of the json logs in addition to the console and the file set by SetOutput.
+// It is used to send the logs to the network sinks. e.g. AddSink(NewBatchWriter(send, 1000, 1<<20, time.Second))
+// The sinks are synced by Sync. It must be called before Init.
+func AddSink(ws zapcore.WriteSyncer) {
+	sinks = append(sinks, ws)
+}
@@ -0,0 +1,27 @@
+package zl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSink(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	r := &batchRecorder{}
+	SetOutput(ConsoleOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetStdout()
+	AddSink(NewBatchWriter(r.flush, 0, 0, 0))
+	Init()
+	SetLevel(InfoLevel)
+
+	Info("SINK1")
+	New().Info("SINK2")
+	assert.Empty(t, r.get())
+	Sync()
+	assert.Equal(t, []string{
+		`{"severity":"INFO","message":"SINK1"}` + "\n," + `{"severity":"INFO","message":"SINK2"}` + "\n",
+	}, r.get())
+	ResetGlobalLoggerSettings()
+}
@@ -0,0 +1,195 @@
+package zl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultSQLiteTable is the default table of SQLiteStore.
+	DefaultSQLiteTable = "logs"
+
+	// sqlitePurgeInterval is the minimum interval to delete the logs older than the retention.
+	sqlitePurgeInterval = time.Minute
+)
+
+var sqliteTableRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteConfig is the config of NewSQLiteStore.
+type SQLiteConfig struct {
+	// Table is the table of the logs. Default is DefaultSQLiteTable.
+	Table string
+	// Retention is the age of the logs to keep. The older logs are deleted after the writes. 0 keeps all the logs.
+	Retention time.Duration
+
+	// MaxBatchEntries, MaxBatchBytes and FlushInterval are the batching settings. See: NewBatchWriter
+	// The logs of a batch are inserted in a transaction.
+	MaxBatchEntries int
+	MaxBatchBytes   int
+	FlushInterval   time.Duration
+}
+
+// SQLiteStore is a sink that writes the json logs to a SQLite database,
+// so that the local logs can be searched by the indexes instead of scanning the json files.
+// The table has the time (unix nanoseconds), level, logger and message columns with the indexes,
+// and the entry column of the json log.
+// zl does not depend on a SQLite driver. Open db with the driver of your choice.
+// e.g.
+//
+//	db, err := sql.Open("sqlite", "./log/app.db") // import _ "modernc.org/sqlite"
+//	store, err := zl.NewSQLiteStore(db, zl.SQLiteConfig{Retention: 7 * 24 * time.Hour, FlushInterval: time.Second})
+//	zl.AddSink(store)
+type SQLiteStore struct {
+	*BatchWriter
+	db        *sql.DB
+	table     string
+	retention time.Duration
+
+	purgeMu   sync.Mutex
+	lastPurge time.Time
+}
+
+// NewSQLiteStore creates the table and the indexes if they do not exist, and returns the SQLiteStore.
+func NewSQLiteStore(db *sql.DB, config SQLiteConfig) (*SQLiteStore, error) {
+	if config.Table == "" {
+		config.Table = DefaultSQLiteTable
+	}
+	if !sqliteTableRegexp.MatchString(config.Table) {
+		return nil, fmt.Errorf("zl: invalid sqlite table name: %q", config.Table)
+	}
+	s := &SQLiteStore{db: db, table: config.Table, retention: config.Retention}
+	if err := s.createTable(); err != nil {
+		return nil, err
+	}
+	s.BatchWriter = NewBatchWriter(s.insert, config.MaxBatchEntries, config.MaxBatchBytes, config.FlushInterval)
+	return s, nil
+}
+
+func (s *SQLiteStore) createTable() error {
+	stmts := []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time INTEGER NOT NULL,
+	level TEXT NOT NULL,
+	logger TEXT NOT NULL,
+	message TEXT NOT NULL,
+	entry TEXT NOT NULL
+)`, s.table)}
+	for _, col := range []string{"time", "level", "logger", "message"} {
+		stmts = append(stmts, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s ON %s (%s)", s.table, col, s.table, col))
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert inserts the json logs of batch in a transaction. The lines that are not json logs are skipped.
+func (s *SQLiteStore) insert(batch [][]byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO %s (time, level, logger, message, entry) VALUES (?, ?, ?, ?, ?)", s.table))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, line := range batch {
+		e, ok := (Filter{Level: DebugLevel}).match(line)
+		if !ok {
+			continue
+		}
+		if e.Time.IsZero() {
+			e.Time = clock.Now()
+		}
+		logger, _ := e.Fields[fieldKey(LoggerKey)].(string)
+		if _, err := stmt.Exec(e.Time.UnixNano(), e.Level.CapitalString(), logger, e.Message, string(e.Raw)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return s.purge()
+}
+
+// purge deletes the logs older than the retention at most once per sqlitePurgeInterval.
+func (s *SQLiteStore) purge() error {
+	if s.retention <= 0 {
+		return nil
+	}
+	s.purgeMu.Lock()
+	defer s.purgeMu.Unlock()
+	now := clock.Now()
+	if now.Sub(s.lastPurge) < sqlitePurgeInterval {
+		return nil
+	}
+	s.lastPurge = now
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE time < ?", s.table), now.Add(-s.retention).UnixNano())
+	return err
+}
+
+// Query returns the logs that match filter in the order of time. limit is the maximum number of them. 0 means unlimited.
+// The time, the level and the message of filter are searched by the indexes, and the fields are matched by zl.
+// The logs not synced yet are not returned. See: BatchWriter.Sync
+func (s *SQLiteStore) Query(ctx context.Context, filter Filter, limit int) ([]*QueryEntry, error) {
+	var where []string
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		where = append(where, "time >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "time <= ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+	var levels []string
+	for level := filter.Level; level <= zapcore.FatalLevel; level++ {
+		levels = append(levels, "?")
+		args = append(args, level.CapitalString())
+	}
+	where = append(where, fmt.Sprintf("level IN (%s)", strings.Join(levels, ", ")))
+	if filter.Message != "" {
+		if prefix, ok := strings.CutSuffix(filter.Message, "*"); ok {
+			where = append(where, `message LIKE ? ESCAPE '\'`)
+			args = append(args, escapeLike(prefix)+"%")
+		} else {
+			where = append(where, "message = ?")
+			args = append(args, filter.Message)
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT entry FROM %s WHERE %s ORDER BY time, id", s.table, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []*QueryEntry
+	for rows.Next() && (limit <= 0 || len(ret) < limit) {
+		var entry string
+		if err := rows.Scan(&entry); err != nil {
+			return nil, err
+		}
+		if e, ok := filter.match([]byte(entry)); ok {
+			ret = append(ret, e)
+		}
+	}
+	return ret, rows.Err()
+}
+
+// escapeLike escapes the wildcards of LIKE with the escape character `\`.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,141 @@
+package zl
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSQLDB is an in-memory database of fakeSQLDriver that records the statements.
+// SELECT returns all the inserted entries without evaluating WHERE, and the rest is matched by zl.
+type fakeSQLDB struct {
+	mu      sync.Mutex
+	execs   []string
+	queries []string
+	args    [][]driver.Value
+	entries []string
+}
+
+type fakeSQLDriver struct{ db *fakeSQLDB }
+
+func (d fakeSQLDriver) Open(string) (driver.Conn, error) { return fakeSQLConn(d), nil }
+
+type fakeSQLConn struct{ db *fakeSQLDB }
+
+func (c fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSQLStmt{db: c.db, query: query}, nil
+}
+func (c fakeSQLConn) Close() error              { return nil }
+func (c fakeSQLConn) Begin() (driver.Tx, error) { return fakeSQLTx{}, nil }
+
+type fakeSQLTx struct{}
+
+func (fakeSQLTx) Commit() error   { return nil }
+func (fakeSQLTx) Rollback() error { return nil }
+
+type fakeSQLStmt struct {
+	db    *fakeSQLDB
+	query string
+}
+
+func (s fakeSQLStmt) Close() error  { return nil }
+func (s fakeSQLStmt) NumInput() int { return -1 }
+
+func (s fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.execs = append(s.db.execs, s.query)
+	if strings.HasPrefix(s.query, "INSERT") {
+		s.db.entries = append(s.db.entries, args[4].(string))
+	}
+	s.db.args = append(s.db.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.queries = append(s.db.queries, s.query)
+	s.db.args = append(s.db.args, args)
+	return &fakeSQLRows{entries: append([]string(nil), s.db.entries...)}, nil
+}
+
+type fakeSQLRows struct{ entries []string }
+
+func (r *fakeSQLRows) Columns() []string { return []string{"entry"} }
+func (r *fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.entries) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.entries[0]
+	r.entries = r.entries[1:]
+	return nil
+}
+
+func openFakeSQLDB(t *testing.T) (*sql.DB, *fakeSQLDB) {
+	fake := &fakeSQLDB{}
+	name := "zl-fake-" + strings.ReplaceAll(t.Name(), "/", "-")
+	sql.Register(name, fakeSQLDriver{db: fake})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, fake
+}
+
+func TestSQLiteStore(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	SetClock(&fixedClock{t: now})
+	db, fake := openFakeSQLDB(t)
+	store, err := NewSQLiteStore(db, SQLiteConfig{Retention: time.Hour})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, fake.execs, 5)
+	assert.Contains(t, fake.execs[0], "CREATE TABLE IF NOT EXISTS logs (")
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS logs_message ON logs (message)", fake.execs[4])
+
+	_, _ = store.Write([]byte(`{"severity":"INFO","timestamp":"2026-10-14T09:59:00Z","logger":"api","message":"USER_CREATED","user_id":"42"}` + "\n"))
+	_, _ = store.Write([]byte("not a json log\n"))
+	_, _ = store.Write([]byte(`{"severity":"ERROR","message":"DB_ERROR","user_id":"43"}` + "\n"))
+	assert.NoError(t, store.Close())
+
+	assert.Len(t, fake.entries, 2)
+	insert := fake.args[len(fake.args)-3]
+	assert.Equal(t, []driver.Value{
+		time.Date(2026, 10, 14, 9, 59, 0, 0, time.UTC).UnixNano(), "INFO", "api", "USER_CREATED", fake.entries[0],
+	}, insert)
+	assert.Equal(t, "DELETE FROM logs WHERE time < ?", fake.execs[len(fake.execs)-1])
+	assert.Equal(t, []driver.Value{now.Add(-time.Hour).UnixNano()}, fake.args[len(fake.args)-1])
+
+	entries, err := store.Query(context.Background(), Filter{Message: "USER_*", Since: now.Add(-time.Hour)}, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "USER_CREATED", entries[0].Message)
+	}
+	assert.Equal(t,
+		`SELECT entry FROM logs WHERE time >= ? AND level IN (?, ?, ?, ?, ?, ?) AND message LIKE ? ESCAPE '\' ORDER BY time, id`,
+		fake.queries[0])
+	assert.Equal(t, "USER\\_%", fake.args[len(fake.args)-1][7])
+
+	entries, err = store.Query(context.Background(), Filter{Level: DebugLevel, Field: map[string]string{"user_id": "43"}}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	entries, err = store.Query(context.Background(), Filter{Level: DebugLevel}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = NewSQLiteStore(db, SQLiteConfig{Table: "logs; DROP TABLE logs"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package zl
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DroppedEntriesMessage is the message of the internal log of the number of the dropped logs.
+// See: SetDroppedEntriesInterval
+const DroppedEntriesMessage = "ZL_DROPPED_ENTRIES"
+
+// DefaultDroppedEntriesInterval is the default interval of DroppedEntriesMessage.
+const DefaultDroppedEntriesInterval = time.Minute
+
+// LoggerStats is the statistics of the logger itself since Init. See: Stats
+type LoggerStats struct {
+	Entries      map[string]uint64 `json:"entries"`       // The number of the written json logs by level.
+	BytesWritten uint64            `json:"bytes_written"` // The bytes of the written json logs.
+	Rotations    uint64            `json:"rotations"`     // The number of the rotations of the log files.
+	SinkErrors   uint64            `json:"sink_errors"`   // The number of the failed writes to the outputs and the sinks.
+	QueueDepth   int               `json:"queue_depth"`   // The number of the logs queued by SetAsync.
+
+	AsyncDropped      uint64 `json:"async_dropped"`       // Dropped by AsyncDropOldest.
+	RateLimited       uint64 `json:"rate_limited"`        // Suppressed by SetRateLimit.
+	TailBufferDropped uint64 `json:"tail_buffer_dropped"` // Dropped because the buffer of Logger.Buffered is full.
+	BatchDropped      uint64 `json:"batch_dropped"`       // Dropped because BatchWriter failed to send them.
+}
+
+// droppedCounter is a counter of the dropped logs.
+type droppedCounter struct {
+	atomic.Uint64
+	reported uint64 // reported is the value of the last DroppedEntriesMessage. It is protected by statsMu.
+}
+
+var (
+	droppedEntriesInterval = DefaultDroppedEntriesInterval
+
+	// statsEntries is the number of the written logs indexed by the level minus zapcore.DebugLevel.
+	statsEntries    [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+	statsBytes      atomic.Uint64
+	statsRotations  atomic.Uint64
+	statsSinkErrors atomic.Uint64
+
+	droppedAsync      droppedCounter
+	droppedRateLimit  droppedCounter
+	droppedTailBuffer droppedCounter
+	droppedBatch      droppedCounter
+
+	statsMu       sync.Mutex
+	statsReporter chan struct{} // statsReporter is closed to stop the reporter.
+)
+
+// SetDroppedEntriesInterval set the interval to write the internal WARN log of DroppedEntriesMessage
+// when the logs are dropped by the asynchronous logging, the rate limits, the tail buffer or the BatchWriter.
+// The log has the number of the dropped logs since the last one, so that the operators can know the logs are incomplete.
+// It is also written by Sync. 0 means it is not written periodically.
+// Default is DefaultDroppedEntriesInterval.
+func SetDroppedEntriesInterval(interval time.Duration) {
+	droppedEntriesInterval = interval
+}
+
+// Stats returns the statistics of the logger itself since Init.
+func Stats() LoggerStats {
+	entries := make(map[string]uint64)
+	for i := range statsEntries {
+		if n := statsEntries[i].Load(); n > 0 {
+			entries[(zapcore.DebugLevel + zapcore.Level(i)).CapitalString()] = n
+		}
+	}
+	return LoggerStats{
+		Entries:           entries,
+		BytesWritten:      statsBytes.Load(),
+		Rotations:         statsRotations.Load(),
+		SinkErrors:        statsSinkErrors.Load(),
+		QueueDepth:        asyncQueueDepth(),
+		AsyncDropped:      droppedAsync.Load(),
+		RateLimited:       droppedRateLimit.Load(),
+		TailBufferDropped: droppedTailBuffer.Load(),
+		BatchDropped:      droppedBatch.Load(),
+	}
+}
+
+// addDropped adds n to c and starts the reporter at the first drop.
+func addDropped(c *droppedCounter, n uint64) {
+	c.Add(n)
+	if droppedEntriesInterval <= 0 {
+		return
+	}
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if statsReporter == nil {
+		statsReporter = make(chan struct{})
+		go runStatsReporter(droppedEntriesInterval, statsReporter)
+	}
+}
+
+func runStatsReporter(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reportDropped()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reportDropped writes DroppedEntriesMessage if the logs have been dropped since the last one.
+func reportDropped() {
+	statsMu.Lock()
+	var fields []zap.Field
+	for _, c := range []struct {
+		key     string
+		counter *droppedCounter
+	}{
+		{"async", &droppedAsync},
+		{"rate_limit", &droppedRateLimit},
+		{"tail_buffer", &droppedTailBuffer},
+		{"batch", &droppedBatch},
+	} {
+		n := c.counter.Load()
+		if n > c.counter.reported {
+			fields = append(fields, zap.Uint64(c.key, n-c.counter.reported))
+			c.counter.reported = n
+		}
+	}
+	statsMu.Unlock()
+	if fields == nil || internalLogger == nil {
+		return
+	}
+	iWarn(DroppedEntriesMessage, fields...)
+}
+
+// PublishExpvar publishes Stats as the expvar variable of name. e.g. PublishExpvar("zl")
+// The variable can be got from /debug/vars if expvar is served by net/http.
+// It does nothing if the variable of name has already been published.
+func PublishExpvar(name string) {
+	if expvar.Get(name) != nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} { return Stats() }))
+}
+
+func resetStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if statsReporter != nil {
+		close(statsReporter)
+		statsReporter = nil
+	}
+	for _, c := range []*droppedCounter{&droppedAsync, &droppedRateLimit, &droppedTailBuffer, &droppedBatch} {
+		c.Store(0)
+		c.reported = 0
+	}
+	for i := range statsEntries {
+		statsEntries[i].Store(0)
+	}
+	statsBytes.Store(0)
+	statsRotations.Store(0)
+	statsSinkErrors.Store(0)
+	droppedEntriesInterval = DefaultDroppedEntriesInterval
+}
+
+// statsCore is a zapcore.Core that counts the written logs by level.
+type statsCore struct {
+	zapcore.Core
+}
+
+func (c *statsCore) With(fields []zap.Field) zapcore.Core {
+	return &statsCore{Core: c.Core.With(fields)}
+}
+
+func (c *statsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *statsCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if err := c.Core.Write(ent, fields); err != nil {
+		return err
+	}
+	if ent.Level >= zapcore.DebugLevel && ent.Level <= zapcore.FatalLevel {
+		statsEntries[ent.Level-zapcore.DebugLevel].Add(1)
+	}
+	return nil
+}
+
+// statsWriteSyncer is a zapcore.WriteSyncer that counts the written bytes and the errors.
+type statsWriteSyncer struct {
+	zapcore.WriteSyncer
+}
+
+func (s statsWriteSyncer) Write(p []byte) (int, error) {
+	n, err := s.WriteSyncer.Write(p)
+	statsBytes.Add(uint64(n))
+	if err != nil {
+		statsSinkErrors.Add(1)
+	}
+	return n, err
+}
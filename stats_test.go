@@ -0,0 +1,119 @@
+package zl
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestStats(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetRateLimit("NOISY", 1, time.Hour)
+	SetDroppedEntriesInterval(0)
+	Init()
+
+	for i := 0; i < 3; i++ {
+		Info("NOISY")
+	}
+	w := NewBatchWriter(func([][]byte) error { return errors.New("send error") }, 0, 0, 0)
+	_, _ = w.Write([]byte("a"))
+	_, _ = w.Write([]byte("b"))
+	assert.Error(t, w.Close())
+	stats := Stats()
+	assert.Equal(t, uint64(2), stats.RateLimited)
+	assert.Equal(t, uint64(2), stats.BatchDropped)
+	assert.Equal(t, map[string]uint64{"INFO": 1}, stats.Entries)
+	assert.Equal(t, uint64(len(`{"severity":"INFO","message":"NOISY"}`+"\n")), stats.BytesWritten)
+
+	Sync()
+	Sync() // It is not written again if no logs are dropped.
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `{"severity":"WARN","message":"ZL_DROPPED_ENTRIES","rate_limit":2,"batch":2}`+"\n")
+	assert.Equal(t, 1, strings.Count(string(b), "ZL_DROPPED_ENTRIES"))
+	ResetGlobalLoggerSettings()
+	assert.Equal(t, LoggerStats{Entries: map[string]uint64{}}, Stats())
+}
+
+func TestStats_rotations(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(file)
+	SetRotateMaxSize(1)
+	Init()
+
+	large := strings.Repeat("a", 300*1024)
+	for i := 0; i < 4; i++ {
+		Warn("LARGE", zap.String("value", large))
+	}
+	stats := Stats()
+	assert.Equal(t, uint64(1), stats.Rotations)
+	assert.Equal(t, uint64(4), stats.Entries["WARN"])
+	assert.Greater(t, stats.BytesWritten, uint64(4*len(large)))
+	assert.Zero(t, stats.SinkErrors)
+	ResetGlobalLoggerSettings()
+}
+
+func TestStats_sinkErrors(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	AddSink(zapcore.AddSync(errWriter{}))
+	Init()
+
+	Info("SINK_ERROR")
+	assert.Equal(t, uint64(1), Stats().SinkErrors)
+	ResetGlobalLoggerSettings()
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestPublishExpvar(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+	PublishExpvar("zl_test")
+	PublishExpvar("zl_test") // It does not panic if it has already been published.
+
+	Error("EXPVAR")
+	var stats LoggerStats
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("zl_test").String()), &stats))
+	assert.Equal(t, map[string]uint64{"ERROR": 1}, stats.Entries)
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetDroppedEntriesInterval(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	SetRotateFileName(file)
+	SetDroppedEntriesInterval(time.Millisecond)
+	Init()
+
+	addDropped(&droppedAsync, 3)
+	assert.Eventually(t, func() bool {
+		b, _ := os.ReadFile(file)
+		return strings.Count(string(b), `"message":"ZL_DROPPED_ENTRIES","async":3`) == 1
+	}, time.Second, time.Millisecond)
+	ResetGlobalLoggerSettings()
+}
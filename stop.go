@@ -0,0 +1,40 @@
+package zl
+
+import (
+	"os"
+	"syscall"
+)
+
+// StopOption is the option of SyncWhenStop.
+type StopOption func(*stopConfig)
+
+type stopConfig struct {
+	signals    []os.Signal
+	beforeExit func(sig os.Signal, code int) int
+}
+
+func newStopConfig(opts []StopOption) *stopConfig {
+	config := &stopConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if len(config.signals) == 0 {
+		config.signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	return config
+}
+
+// WithSignals set the signals that SyncWhenStop handles. Default is SIGINT and SIGTERM.
+func WithSignals(sig ...os.Signal) StopOption {
+	return func(c *stopConfig) {
+		c.signals = sig
+	}
+}
+
+// WithBeforeExit set the func called before SyncWhenStop flushes the log buffer and exits.
+// It can close the other resources, and returns the exit code. code is the default exit code (128 + the signal number).
+func WithBeforeExit(fn func(sig os.Signal, code int) int) StopOption {
+	return func(c *stopConfig) {
+		c.beforeExit = fn
+	}
+}
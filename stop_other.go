@@ -0,0 +1,16 @@
+//go:build !plan9
+
+package zl
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNumber returns the number of sig. It is 0 if sig is not a syscall.Signal.
+func signalNumber(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return int(s)
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+package zl
+
+import "os"
+
+// signalNumber returns 0 because the notes of plan9 do not have the numbers.
+func signalNumber(sig os.Signal) int {
+	return 0
+}
@@ -0,0 +1,27 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func initStopTest(t *testing.T) chan int {
+	t.Helper()
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	codes := make(chan int, 1)
+	SetExitFunc(func(code int) { codes <- code })
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	Init()
+	return codes
+}
+
+func Test_newStopConfig(t *testing.T) {
+	assert.Equal(t, []os.Signal{syscall.SIGINT, syscall.SIGTERM}, newStopConfig(nil).signals)
+	assert.Equal(t, []os.Signal{os.Interrupt}, newStopConfig([]StopOption{WithSignals(os.Interrupt)}).signals)
+}
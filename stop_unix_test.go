@@ -0,0 +1,51 @@
+//go:build unix
+
+package zl
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncWhenStop_options(t *testing.T) {
+	codes := initStopTest(t)
+	var got []interface{}
+	stop := SyncWhenStop(WithSignals(syscall.SIGUSR1), WithBeforeExit(func(sig os.Signal, code int) int {
+		got = append(got, sig, code)
+		return 3
+	}))
+	defer stop()
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	select {
+	case code := <-codes:
+		assert.Equal(t, 3, code)
+		assert.Equal(t, []interface{}{syscall.SIGUSR1, 128 + int(syscall.SIGUSR1)}, got)
+	case <-time.After(time.Second):
+		t.Fatal("not exited")
+	}
+}
+
+func TestSyncWhenStop_stop(t *testing.T) {
+	codes := initStopTest(t)
+	stop := SyncWhenStop(WithSignals(syscall.SIGUSR2))
+	stop()
+	stop()
+
+	// Keep the signal from terminating the test process.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR2)
+	defer signal.Stop(c)
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	<-c
+	select {
+	case code := <-codes:
+		t.Fatalf("exited with %d after stop", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,169 @@
+package zl
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The functions of this file are wrappers of Zap's SugaredLogger to ease the migration from the standard log
+// and logrus. The structured logs of the fixed messages such as Info are recommended for the new code.
+
+// Debugf is Outputs a DEBUG log with the message formatted by fmt.Sprintf.
+// It is wrapper of Zap's SugaredLogger.Debugf.
+func Debugf(format string, args ...interface{}) {
+	loggerf(DebugLevel, format, args).Debugf(format, args...)
+}
+
+// Infof is Outputs an INFO log with the message formatted by fmt.Sprintf.
+// It is wrapper of Zap's SugaredLogger.Infof.
+func Infof(format string, args ...interface{}) {
+	loggerf(InfoLevel, format, args).Infof(format, args...)
+}
+
+// Warnf is Outputs a WARN log with the message formatted by fmt.Sprintf.
+// It is wrapper of Zap's SugaredLogger.Warnf.
+func Warnf(format string, args ...interface{}) {
+	loggerf(WarnLevel, format, args).Warnf(format, args...)
+}
+
+// Errorf is Outputs an ERROR log with the message formatted by fmt.Sprintf.
+// It is wrapper of Zap's SugaredLogger.Errorf.
+func Errorf(format string, args ...interface{}) {
+	loggerf(ErrorLevel, format, args).Errorf(format, args...)
+}
+
+// Debugw is Outputs a DEBUG log with the fields of the loosely typed key-value pairs.
+// It is wrapper of Zap's SugaredLogger.Debugw. e.g. zl.Debugw("USER", "id", id, "name", name)
+func Debugw(message string, keysAndValues ...interface{}) {
+	logger(message, DebugLevel, sweetenFields(keysAndValues)).Sugar().Debugw(message, keysAndValues...)
+}
+
+// Infow is Outputs an INFO log with the fields of the loosely typed key-value pairs.
+// It is wrapper of Zap's SugaredLogger.Infow. e.g. zl.Infow("USER", "id", id, "name", name)
+func Infow(message string, keysAndValues ...interface{}) {
+	logger(message, InfoLevel, sweetenFields(keysAndValues)).Sugar().Infow(message, keysAndValues...)
+}
+
+// Warnw is Outputs a WARN log with the fields of the loosely typed key-value pairs.
+// It is wrapper of Zap's SugaredLogger.Warnw. e.g. zl.Warnw("USER", "id", id, "name", name)
+func Warnw(message string, keysAndValues ...interface{}) {
+	logger(message, WarnLevel, sweetenFields(keysAndValues)).Sugar().Warnw(message, keysAndValues...)
+}
+
+// Errorw is Outputs an ERROR log with the fields of the loosely typed key-value pairs.
+// It is wrapper of Zap's SugaredLogger.Errorw. e.g. zl.Errorw("USER", "id", id, "name", name)
+func Errorw(message string, keysAndValues ...interface{}) {
+	logger(message, ErrorLevel, sweetenFields(keysAndValues)).Sugar().Errorw(message, keysAndValues...)
+}
+
+// Debugf is Outputs a DEBUG log with the message formatted by fmt.Sprintf. See: Debugf
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	l.logger(message, DebugLevel, l.fields).Sugar().Debugw(message, l.fieldArgs()...)
+}
+
+// Infof is Outputs an INFO log with the message formatted by fmt.Sprintf. See: Infof
+func (l *Logger) Infof(format string, args ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	l.logger(message, InfoLevel, l.fields).Sugar().Infow(message, l.fieldArgs()...)
+}
+
+// Warnf is Outputs a WARN log with the message formatted by fmt.Sprintf. See: Warnf
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	l.logger(message, WarnLevel, l.fields).Sugar().Warnw(message, l.fieldArgs()...)
+}
+
+// Errorf is Outputs an ERROR log with the message formatted by fmt.Sprintf. See: Errorf
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	l.logger(message, ErrorLevel, l.fields).Sugar().Errorw(message, l.fieldArgs()...)
+}
+
+// Debugw is Outputs a DEBUG log with the fields of the loosely typed key-value pairs. See: Debugw
+func (l *Logger) Debugw(message string, keysAndValues ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
+	keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], l.fieldArgs()...)
+	l.logger(message, DebugLevel, sweetenFields(keysAndValues)).Sugar().Debugw(message, keysAndValues...)
+}
+
+// Infow is Outputs an INFO log with the fields of the loosely typed key-value pairs. See: Infow
+func (l *Logger) Infow(message string, keysAndValues ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
+	keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], l.fieldArgs()...)
+	l.logger(message, InfoLevel, sweetenFields(keysAndValues)).Sugar().Infow(message, keysAndValues...)
+}
+
+// Warnw is Outputs a WARN log with the fields of the loosely typed key-value pairs. See: Warnw
+func (l *Logger) Warnw(message string, keysAndValues ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], l.fieldArgs()...)
+	l.logger(message, WarnLevel, sweetenFields(keysAndValues)).Sugar().Warnw(message, keysAndValues...)
+}
+
+// Errorw is Outputs an ERROR log with the fields of the loosely typed key-value pairs. See: Errorw
+func (l *Logger) Errorw(message string, keysAndValues ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
+	keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], l.fieldArgs()...)
+	l.logger(message, ErrorLevel, sweetenFields(keysAndValues)).Sugar().Errorw(message, keysAndValues...)
+}
+
+// loggerf writes the pretty log of the formatted message and returns the SugaredLogger.
+// The message is formatted only if the pretty log is written.
+func loggerf(level zapcore.Level, format string, args []interface{}) *zap.SugaredLogger {
+	checkInit()
+	if outputType == PrettyOutput && severityEnabled(level) {
+		pretty.log(fmt.Sprintf(format, args...), level, nil)
+	}
+	return zapLogger.Sugar()
+}
+
+// fieldArgs returns the default fields of the Logger as the arguments of the SugaredLogger.
+func (l *Logger) fieldArgs() []interface{} {
+	args := make([]interface{}, len(l.fields))
+	for i := range l.fields {
+		args[i] = l.fields[i]
+	}
+	return args
+}
+
+// sweetenFields converts the loosely typed key-value pairs to the fields in the same way as Zap's SugaredLogger
+// for the pretty log. A zap.Field in the pairs is used as it is.
+func sweetenFields(args []interface{}) []zap.Field {
+	var fields []zap.Field
+	for i := 0; i < len(args); i++ {
+		if f, ok := args[i].(zap.Field); ok {
+			fields = append(fields, f)
+			continue
+		}
+		key, ok := args[i].(string)
+		if !ok || i+1 == len(args) {
+			continue // The SugaredLogger reports the invalid pairs by the error log.
+		}
+		fields = append(fields, zap.Any(key, args[i+1]))
+		i++
+	}
+	return fields
+}
@@ -0,0 +1,78 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestInfof(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	Debugf("debug %d", 1)
+	Infof("info %s", "a")
+	Warnf("warn %v", true)
+	Errorf("error %.1f", 1.5)
+	Infow("USER", "id", 1, zap.String("name", "alice"))
+	Warnw("WARN_USER", "id", 2)
+	Debugw("DEBUG_USER")
+	Errorw("ERROR_USER", "id", 3)
+	l := New(zap.String("trace_id", "t1"))
+	l.Debugf("debug %d", 2)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 2)
+	l.Errorf("error %d", 2)
+	l.Infow("LOGGER_USER", "id", 4)
+	l.Debugw("LOGGER_DEBUG_USER")
+	l.Warnw("LOGGER_WARN_USER")
+	l.Errorw("LOGGER_ERROR_USER")
+
+	var got []string
+	for _, e := range obs.All()[1:] { // Skip INIT_LOGGER.
+		got = append(got, e.Level.CapitalString()+" "+e.Message)
+		assert.True(t, strings.HasSuffix(e.Caller.File, "sugar_test.go"), e.Caller.File)
+	}
+	assert.Equal(t, []string{
+		"DEBUG debug 1", "INFO info a", "WARN warn true", "ERROR error 1.5",
+		"INFO USER", "WARN WARN_USER", "DEBUG DEBUG_USER", "ERROR ERROR_USER",
+		"DEBUG debug 2", "INFO info 2", "WARN warn 2", "ERROR error 2",
+		"INFO LOGGER_USER", "DEBUG LOGGER_DEBUG_USER", "WARN LOGGER_WARN_USER", "ERROR LOGGER_ERROR_USER",
+	}, got)
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "name": "alice"}, obs.FilterMessage("USER").All()[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"trace_id": "t1"}, obs.FilterMessage("info 2").All()[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"id": int64(4), "trace_id": "t1"}, obs.FilterMessage("LOGGER_USER").All()[0].ContextMap())
+}
+
+func TestInfof_pretty(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetColor(ColorNever)
+	SetPrettyFields(PrettyFieldsKeyValue)
+	Init()
+	var buf bytes.Buffer
+	pretty = newPrettyLogger(&buf, os.Stderr)
+
+	Infof("loaded %d items", 3)
+	Infow("USER", "id", 1)
+	Debugf("not written %d", 1)
+	assert.Contains(t, buf.String(), "sugar_test.go:65: INFO loaded 3 items")
+	assert.Contains(t, buf.String(), "sugar_test.go:66: INFO USER")
+	assert.Contains(t, buf.String(), "id=1")
+	assert.NotContains(t, buf.String(), "not written")
+	ResetGlobalLoggerSettings()
+}
+
+func Test_sweetenFields(t *testing.T) {
+	assert.Equal(t, []zap.Field{zap.Any("a", 1), zap.String("b", "c")},
+		sweetenFields([]interface{}{"a", 1, zap.String("b", "c"), 2, "dangling"}))
+}
@@ -0,0 +1,112 @@
+package zl
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	au "github.com/logrusorgru/aurora/v4"
+	"go.uber.org/zap/zapcore"
+)
+
+const summaryTopMessages = 5
+
+var (
+	prettySummary bool
+	summary       = newSummaryStats()
+)
+
+type summaryStats struct {
+	mu       sync.Mutex
+	levels   map[zapcore.Level]int
+	messages map[summaryKey]int
+}
+
+type summaryKey struct {
+	level   zapcore.Level
+	message string
+}
+
+func newSummaryStats() *summaryStats {
+	return &summaryStats{
+		levels:   map[zapcore.Level]int{},
+		messages: map[summaryKey]int{},
+	}
+}
+
+// SetPrettySummary is set whether a summary is displayed on Sync when PrettyOutput is used.
+// The summary shows the counts per level, the most repeated messages, the total runtime and the log file path.
+// It is useful for batch jobs and CLI tools to see the result at a glance.
+func SetPrettySummary(val bool) {
+	prettySummary = val
+}
+
+func (s *summaryStats) add(level zapcore.Level, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.levels[level]++
+	s.messages[summaryKey{level: level, message: msg}]++
+}
+
+// topMessages returns the most repeated messages in descending order of the count.
+func (s *summaryStats) topMessages(n int) []summaryKey {
+	keys := make([]summaryKey, 0, len(s.messages))
+	for k := range s.messages {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if s.messages[keys[i]] != s.messages[keys[j]] {
+			return s.messages[keys[i]] > s.messages[keys[j]]
+		}
+		if keys[i].level != keys[j].level {
+			return keys[i].level > keys[j].level
+		}
+		return keys[i].message < keys[j].message
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func (l *prettyLogger) showSummary() {
+	if !prettySummary {
+		return
+	}
+	if err := l.write(l.summaryMsg(clock.Now().Sub(startTime))); err != nil {
+		l.internalLog.Println(err)
+	}
+}
+
+func (l *prettyLogger) summaryMsg(runtime time.Duration) string {
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+
+	var levels []string
+	for _, level := range []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel} {
+		if count := summary.levels[level]; count > 0 {
+			levels = append(levels, fmt.Sprintf("%d %s", count, l.coloredLevel(level)))
+		}
+	}
+	if levels == nil {
+		levels = append(levels, "0")
+	}
+
+	var b strings.Builder
+	b.WriteString("\n" + au.Bold("SUMMARY").String() + "\n")
+	b.WriteString(fmt.Sprintf("%v:\t%v\n", l.attr("Runtime"), runtime.Round(time.Millisecond)))
+	if path, err := filepath.Abs(currentFileName()); err == nil && fileName != "" {
+		b.WriteString(fmt.Sprintf("%v:\t%v\n", l.attr("LogFile"), path))
+	}
+	b.WriteString(fmt.Sprintf("%v:\t%v\n", l.attr("Logs"), strings.Join(levels, ", ")))
+	if top := summary.topMessages(summaryTopMessages); len(top) > 0 {
+		b.WriteString(fmt.Sprintf("%v:\n", l.attr("TopMessages")))
+		for _, k := range top {
+			b.WriteString(fmt.Sprintf("\t%d\t%s %s\n", summary.messages[k], l.coloredLevel(k.level), k.message))
+		}
+	}
+	return b.String()
+}
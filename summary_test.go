@@ -0,0 +1,58 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPrettySummary(t *testing.T) {
+	var buf bytes.Buffer
+	SetColor(ColorNever)
+	SetPrettySummary(true)
+	fileName = "./log/summary.jsonl"
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(0)
+	for i := 0; i < 3; i++ {
+		l.log("FETCH_DONE", InfoLevel, nil)
+	}
+	l.log("RETRY", WarnLevel, nil)
+	l.log("RETRY", WarnLevel, nil)
+	errForTest(l, "FETCH_ERROR", os.ErrNotExist)
+	buf.Reset()
+
+	l.showSummary()
+	assert.Regexp(t, "^\nSUMMARY\n"+
+		`  Runtime:\t\d+.*\n`+
+		`  LogFile:\t/.+/log/summary.jsonl\n`+
+		`  Logs:\t3 INFO, 2 WARN, 1 ERROR\n`+
+		"  TopMessages:\n"+
+		"\t3\tINFO FETCH_DONE\n"+
+		"\t2\tWARN RETRY\n"+
+		"\t1\tERROR FETCH_ERROR\n$",
+		buf.String(),
+	)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_prettyLogger_summaryMsg(t *testing.T) {
+	fileName = ""
+	l := newPrettyLogger(os.Stdout, os.Stderr)
+	assert.Equal(t, "\n\x1b[1mSUMMARY\x1b[0m\n  \x1b[36mRuntime\x1b[0m:\t1.235s\n  \x1b[36mLogs\x1b[0m:\t0\n",
+		l.summaryMsg(1234567*time.Microsecond))
+	ResetGlobalLoggerSettings()
+}
+
+func TestSetPrettySummary_disabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+	l.log("INFO_MESSAGE", InfoLevel, nil)
+	buf.Reset()
+	l.showSummary()
+	assert.Empty(t, buf.String())
+	assert.Empty(t, summary.levels)
+	ResetGlobalLoggerSettings()
+}
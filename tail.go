@@ -0,0 +1,153 @@
+package zl
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// tailBufferSize is the maximum number of the logs held by a buffered logger.
+// The oldest log is dropped when it is exceeded.
+const tailBufferSize = 1024
+
+// Buffered returns a new Logger that holds the DEBUG and INFO logs in memory until End is called.
+// The held logs are written by End only if the request has failed or is slow, and dropped otherwise.
+// This gives the full details of the failed requests without writing the logs of every successful request.
+// The DEBUG logs are held even if the level is higher, and WARN and higher logs are written immediately.
+// latencyThreshold is the elapsed time from Buffered to End to write the logs. 0 means it is not checked.
+// It applies to the json logs of the console and the file.
+// e.g.
+//
+//	cl := zl.New(zap.String("trace_id", traceID)).Buffered(time.Second)
+//	defer func() { cl.End(err) }()
+func (l *Logger) Buffered(latencyThreshold time.Duration) *Logger {
+	clone := l.clone()
+	clone.tail = &tailBuffer{start: clock.Now(), threshold: latencyThreshold}
+	clone.zapLogger = clone.tail.wrap(clone.zapLogger)
+	return clone
+}
+
+// End ends the buffering of the logger returned by Buffered.
+// The held logs are written if err is not nil, an ERROR or higher log has been written,
+// or the elapsed time exceeds the latency threshold. Otherwise, they are dropped.
+// The logs after End are written immediately. It does nothing if the logger is not buffered.
+func (l *Logger) End(err error) {
+	if l.tail != nil {
+		l.tail.end(err)
+	}
+}
+
+type tailEntry struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zap.Field
+}
+
+// tailBuffer is the logs of a request. It is shared by the cores of the logger.
+type tailBuffer struct {
+	mu        sync.Mutex
+	start     time.Time
+	threshold time.Duration
+	entries   []tailEntry
+	ended     bool
+	failed    bool
+}
+
+func (b *tailBuffer) wrap(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &tailCore{Core: core, buf: b}
+	}))
+}
+
+// hold holds the log and reports whether it is held.
+// An ERROR or higher log marks the request as failed, and the held logs are written before it.
+func (b *tailBuffer) hold(core zapcore.Core, ent zapcore.Entry, fields []zap.Field) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ended || b.failed {
+		return false
+	}
+	if ent.Level >= ErrorLevel {
+		b.failed = true
+		b.write()
+		return false
+	}
+	if ent.Level >= WarnLevel {
+		return false
+	}
+	if len(b.entries) == tailBufferSize {
+		b.entries = append(b.entries[:0], b.entries[1:]...)
+		addDropped(&droppedTailBuffer, 1)
+	}
+	b.entries = append(b.entries, tailEntry{
+		core:   core,
+		entry:  ent,
+		fields: append([]zap.Field(nil), fields...),
+	})
+	return true
+}
+
+func (b *tailBuffer) end(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ended {
+		return
+	}
+	b.ended = true
+	if err != nil || (b.threshold > 0 && clock.Now().Sub(b.start) > b.threshold) {
+		b.write()
+	}
+	b.entries = nil
+}
+
+// write writes the held logs. It must be called with mu locked.
+// The inner cores write the DEBUG logs without checking the level.
+func (b *tailBuffer) write() {
+	for _, e := range b.entries {
+		if err := e.core.Write(e.entry, e.fields); err != nil {
+			log.Println(err)
+		}
+	}
+	b.entries = nil
+}
+
+// tailCore is a zapcore.Core that holds the logs in tailBuffer.
+type tailCore struct {
+	zapcore.Core
+	buf *tailBuffer
+}
+
+// Enabled enables all the levels to hold the DEBUG logs.
+func (c *tailCore) Enabled(level zapcore.Level) bool {
+	return c.buffering() || c.Core.Enabled(level)
+}
+
+func (c *tailCore) With(fields []zap.Field) zapcore.Core {
+	return &tailCore{Core: c.Core.With(fields), buf: c.buf}
+}
+
+func (c *tailCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tailCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	if c.buf.hold(c.Core, ent, fields) {
+		return nil
+	}
+	if !c.Core.Enabled(ent.Level) {
+		return nil // It is not held because the request has ended or failed.
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *tailCore) buffering() bool {
+	c.buf.mu.Lock()
+	defer c.buf.mu.Unlock()
+	return !c.buf.ended && !c.buf.failed
+}
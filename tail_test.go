@@ -0,0 +1,94 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogger_Buffered(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		run       func(l *Logger)
+		want      string
+	}{
+		{
+			name: "success",
+			run: func(l *Logger) {
+				l.Debug("DEBUG")
+				l.Info("INFO")
+				l.Warn("WARN")
+				l.End(nil)
+			},
+			want: `{"severity":"WARN","message":"WARN","trace_id":"a"}` + "\n",
+		},
+		{
+			name: "ended with error",
+			run: func(l *Logger) {
+				l.Debug("DEBUG")
+				l.Info("INFO")
+				l.End(errors.New("error"))
+				l.Debug("AFTER_END")
+				l.Info("AFTER_END")
+			},
+			want: `{"severity":"DEBUG","message":"DEBUG","trace_id":"a"}` + "\n" +
+				`{"severity":"INFO","message":"INFO","trace_id":"a"}` + "\n" +
+				`{"severity":"INFO","message":"AFTER_END","trace_id":"a"}` + "\n",
+		},
+		{
+			name: "error log",
+			run: func(l *Logger) {
+				l.Debug("DEBUG")
+				l.Error("ERROR")
+				l.Info("INFO")
+				l.End(nil)
+			},
+			want: `{"severity":"DEBUG","message":"DEBUG","trace_id":"a"}` + "\n" +
+				`{"severity":"ERROR","message":"ERROR","trace_id":"a"}` + "\n" +
+				`{"severity":"INFO","message":"INFO","trace_id":"a"}` + "\n",
+		},
+		{
+			name:      "slow",
+			threshold: time.Millisecond,
+			run: func(l *Logger) {
+				l.Info("INFO")
+				time.Sleep(2 * time.Millisecond)
+				l.End(nil)
+			},
+			want: `{"severity":"INFO","message":"INFO","trace_id":"a"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetGlobalLoggerSettings()
+			file := filepath.Join(t.TempDir(), "app.jsonl")
+			SetOutput(FileOutput)
+			SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+			SetRotateFileName(file)
+			Init()
+
+			tt.run(New(zap.String("trace_id", "a")).Buffered(tt.threshold))
+			Sync()
+			b, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
+
+func Test_tailBuffer_hold(t *testing.T) {
+	b := &tailBuffer{}
+	for i := 0; i < tailBufferSize+1; i++ {
+		assert.True(t, b.hold(nil, zapcore.Entry{Level: DebugLevel}, []zap.Field{zap.Int("i", i)}))
+	}
+	assert.Len(t, b.entries, tailBufferSize)
+	assert.Equal(t, zap.Int("i", 1), b.entries[0].fields[0], "the oldest log is dropped")
+}
@@ -0,0 +1,35 @@
+package zl
+
+import (
+	"strings"
+	"testing"
+)
+
+// testTB is the test set by InitForTest.
+var testTB testing.TB
+
+// InitForTest initializes the logger for the unit tests of the packages that log globally.
+// All the output is written by t.Log instead of the console, and the log file is not written.
+// SyncWhenStop does not start the signal goroutine, and Fatal and FatalErr fail the test instead of exiting.
+// t.Cleanup calls Sync and ResetGlobalLoggerSettings.
+// The settings such as SetLevel and SetOutput must be called before it.
+func InitForTest(t testing.TB) {
+	t.Helper()
+	testTB = t
+	t.Cleanup(func() {
+		Sync()
+		ResetGlobalLoggerSettings()
+	})
+	Init()
+}
+
+// testLogWriter writes the logs by t.Log.
+type testLogWriter struct {
+	t testing.TB
+}
+
+func (w testLogWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,75 @@
+package zl
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordTB records the logs and the failures of InitForTest.
+type recordTB struct {
+	testing.TB
+	logs    []string
+	errors  []string
+	cleanup []func()
+}
+
+func (r *recordTB) Log(args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprint(args...))
+}
+
+func (r *recordTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordTB) Cleanup(f func()) {
+	r.cleanup = append(r.cleanup, f)
+}
+
+func (r *recordTB) runCleanup() {
+	for i := len(r.cleanup) - 1; i >= 0; i-- {
+		r.cleanup[i]()
+	}
+}
+
+func TestInitForTest(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		tb := &recordTB{TB: t}
+		SetOutput(FileOutput)
+		SetRotateFileName(t.TempDir() + "/app.jsonl")
+		SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+		InitForTest(tb)
+
+		Info("INFO")
+		Fatal("FATAL")
+		SyncWhenStop()
+		assert.Equal(t, []string{
+			`{"severity":"INFO","message":"INFO"}`,
+			`{"severity":"FATAL","message":"FATAL"}`,
+		}, tb.logs)
+		assert.Equal(t, []string{"zl: FATAL is called: FATAL"}, tb.errors)
+		assert.NoFileExists(t, fileName)
+
+		tb.runCleanup()
+		assert.Nil(t, zapLogger)
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		tb := &recordTB{TB: t}
+		file := t.TempDir() + "/app.jsonl"
+		SetRotateFileName(file)
+		InitForTest(tb)
+		pretty.Logger.SetFlags(0)
+
+		Info("INFO")
+		Sync()
+		assert.Equal(t, []string{"INFO INFO"}, tb.logs)
+		_, err := os.Stat(file)
+		assert.True(t, os.IsNotExist(err))
+		tb.runCleanup()
+	})
+}
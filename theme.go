@@ -0,0 +1,113 @@
+package zl
+
+import (
+	au "github.com/logrusorgru/aurora/v4"
+	"go.uber.org/zap/zapcore"
+)
+
+// PrettyTheme is the color theme of the console log when PrettyOutput is used.
+// Each color is an aurora Color. A zero Color means no color.
+// See: https://github.com/logrusorgru/aurora
+type PrettyTheme struct {
+	// Level colors.
+	Debug au.Color
+	Info  au.Color
+	Warn  au.Color
+	Error au.Color
+	Fatal au.Color
+
+	// DebugMessage is the color of the message and console fields of DEBUG logs.
+	DebugMessage au.Color
+	// ErrorMessage is the color of the error displayed after the message.
+	ErrorMessage au.Color
+	// Console is the colors of the console fields. They are used alternately.
+	Console []au.Color
+	// Fields is the color of the fields displayed by SetPrettyFields.
+	Fields au.Color
+	// Timestamp is the color of the timestamp.
+	Timestamp au.Color
+	// TimeFormat is the layout of the timestamp. ex. time.RFC3339 or time.Kitchen.
+	// Empty means the same format as log.Logger ("2006/01/02 15:04:05").
+	TimeFormat string
+	// Attr is the color of the attribute names in the error report.
+	Attr au.Color
+	// Icons is displayed before the level. ex. EmojiIcons.
+	Icons map[zapcore.Level]string
+}
+
+var (
+	// DarkTheme is a theme for terminals with dark background. It is Default setting.
+	DarkTheme = PrettyTheme{
+		Debug:        au.BrightFg | au.BlackFg,
+		Info:         au.BrightFg | au.BlueFg,
+		Warn:         au.YellowFg,
+		Error:        au.RedFg,
+		Fatal:        au.RedFg,
+		DebugMessage: au.FaintFm,
+		ErrorMessage: au.MagentaFg,
+		Console:      []au.Color{au.CyanFg, au.BlueFg},
+		Fields:       au.FaintFm,
+		Attr:         au.CyanFg,
+	}
+
+	// LightTheme is a theme for terminals with light background.
+	LightTheme = PrettyTheme{
+		Debug:        au.BrightFg | au.BlackFg,
+		Info:         au.BlueFg,
+		Warn:         au.Color(0).Index(130), // dark orange
+		Error:        au.RedFg | au.BoldFm,
+		Fatal:        au.RedFg | au.BoldFm,
+		DebugMessage: au.BrightFg | au.BlackFg,
+		ErrorMessage: au.MagentaFg,
+		Console:      []au.Color{au.Color(0).Index(25), au.Color(0).Index(30)}, // dark blue, dark cyan
+		Fields:       au.BrightFg | au.BlackFg,
+		Timestamp:    au.BrightFg | au.BlackFg,
+		Attr:         au.BlueFg,
+	}
+
+	// MonochromeTheme is a theme without colors. Only the ERROR and FATAL levels are bold.
+	MonochromeTheme = PrettyTheme{
+		Error: au.BoldFm,
+		Fatal: au.BoldFm,
+	}
+
+	// EmojiIcons is the level icons using emoji.
+	EmojiIcons = map[zapcore.Level]string{
+		DebugLevel: "🐛",
+		InfoLevel:  "💬",
+		WarnLevel:  "⚠️",
+		ErrorLevel: "🔥",
+		FatalLevel: "💀",
+	}
+)
+
+var theme = DarkTheme
+
+// SetPrettyTheme is set the color theme of the console log when PrettyOutput is used.
+// theme can use built-in themes (DarkTheme, LightTheme, MonochromeTheme) or a custom PrettyTheme.
+func SetPrettyTheme(val PrettyTheme) {
+	theme = val
+}
+
+func (t *PrettyTheme) levelColor(level zapcore.Level) au.Color {
+	switch level {
+	case FatalLevel:
+		return t.Fatal
+	case ErrorLevel:
+		return t.Error
+	case WarnLevel:
+		return t.Warn
+	case InfoLevel:
+		return t.Info
+	case DebugLevel:
+		return t.Debug
+	}
+	return t.Debug
+}
+
+func (t *PrettyTheme) consoleColor(i int) au.Color {
+	if len(t.Console) == 0 {
+		return 0
+	}
+	return t.Console[i%len(t.Console)]
+}
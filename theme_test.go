@@ -0,0 +1,71 @@
+package zl
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	au "github.com/logrusorgru/aurora/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetPrettyTheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		theme    PrettyTheme
+		expected string
+	}{
+		{
+			name:     "dark",
+			theme:    DarkTheme,
+			expected: "\x1b[33mWARN\x1b[0m WARN_MESSAGE \x1b[36mconsole\x1b[0m\n",
+		},
+		{
+			name:     "light",
+			theme:    LightTheme,
+			expected: "\x1b[38;5;130mWARN\x1b[0m WARN_MESSAGE \x1b[38;5;25mconsole\x1b[0m\n",
+		},
+		{
+			name:     "monochrome",
+			theme:    MonochromeTheme,
+			expected: "WARN WARN_MESSAGE console\n",
+		},
+		{
+			name:     "emoji icons",
+			theme:    PrettyTheme{Icons: EmojiIcons},
+			expected: "⚠️ WARN WARN_MESSAGE console\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			omitKeys = []Key{TimeKey}
+			SetPrettyTheme(tt.theme)
+
+			var buf bytes.Buffer
+			l := newPrettyLogger(&buf, os.Stderr)
+			l.Logger.SetFlags(0)
+			l.log("WARN_MESSAGE", WarnLevel, []zap.Field{Console("console")})
+			assert.Equal(t, tt.expected, buf.String())
+			ResetGlobalLoggerSettings()
+		})
+	}
+}
+
+func Test_prettyLogger_formatTime(t *testing.T) {
+	tm := time.Date(2023, 9, 9, 15, 53, 17, 287179000, time.UTC)
+	l := &prettyLogger{}
+	assert.Equal(t, "2023/09/09 15:53:17", l.formatTime(tm, log.Ldate|log.Ltime))
+
+	SetPrettyTheme(PrettyTheme{TimeFormat: time.Kitchen, Timestamp: au.FaintFm})
+	assert.Equal(t, "3:53PM", l.formatTime(tm, log.Ldate|log.Ltime))
+
+	var buf bytes.Buffer
+	l = newPrettyLogger(&buf, os.Stderr)
+	l.Logger.SetFlags(log.Ltime)
+	l.log("INFO_MESSAGE", InfoLevel, nil)
+	assert.Regexp(t, `^\x1b\[2m\d{1,2}:\d{2}(AM|PM)\x1b\[0m INFO INFO_MESSAGE\n$`, buf.String())
+	ResetGlobalLoggerSettings()
+}
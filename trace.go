@@ -0,0 +1,20 @@
+package zl
+
+import "context"
+
+// TraceIDKey is the field key of the trace id of the W3C Trace Context.
+const TraceIDKey = "trace_id"
+
+type traceIDContextKey struct{}
+
+// WithTraceID returns the context with traceID, which is shared by the subpackages such as zlhttp and zlmq.
+// e.g. zlhttp.Middleware sets the trace id of the request, and zlhttp.Transport logs and propagates it.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceID returns the trace id set by WithTraceID, or "" if it is not set.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDContextKey{}).(string)
+	return id
+}
@@ -0,0 +1,85 @@
+package zl
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TransportConfig is the TLS and the proxy config shared by the network sinks. See: OTLPConfig
+type TransportConfig struct {
+	// CAFile is the PEM file of the CA bundle to verify the server. The system pool is used if it is empty.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate for mTLS.
+	CertFile string
+	KeyFile  string
+	// ServerName is the server name of SNI and the verification. The host of the endpoint is used if it is empty.
+	ServerName string
+	// MinVersion is the minimum TLS version. e.g. tls.VersionTLS13. Default is tls.VersionTLS12.
+	MinVersion uint16
+	// InsecureSkipVerify disables the verification of the server. It must be used only for testing.
+	InsecureSkipVerify bool
+	// NoProxy disables the proxy. By default, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used.
+	NoProxy bool
+}
+
+// TLSConfig returns the tls.Config of c.
+func (c TransportConfig) TLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec // It is set explicitly by the user.
+	}
+	if conf.MinVersion == 0 {
+		conf.MinVersion = tls.VersionTLS12
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("zl: no certificates in " + c.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// HTTPClient returns the http.Client of c with timeout.
+// It can also be used for the custom sinks of NewBatchWriter.
+func (c TransportConfig) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	conf, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = conf
+	transport.Proxy = http.ProxyFromEnvironment
+	if c.NoProxy {
+		transport.Proxy = nil
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// Dial connects to address on network with TLS for the stream sinks. e.g. Dial("tcp", "logs.example.com:6514")
+// The proxy is not used.
+func (c TransportConfig) Dial(network, address string, timeout time.Duration) (net.Conn, error) {
+	conf, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, conf)
+}
@@ -0,0 +1,112 @@
+package zl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestClientCert writes a self-signed client certificate and returns the files and the certificate.
+func writeTestClientCert(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "zl-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestTransportConfig_HTTPClient(t *testing.T) {
+	certFile, keyFile, clientCert := writeTestClientCert(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "zl-client", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	config := TransportConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "example.com"}
+	client, err := config.HTTPClient(time.Second)
+	assert.NoError(t, err)
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NoError(t, res.Body.Close())
+
+	// The client certificate is required.
+	client, err = TransportConfig{CAFile: caFile}.HTTPClient(time.Second)
+	assert.NoError(t, err)
+	_, err = client.Get(server.URL) //nolint:bodyclose // It fails.
+	assert.Error(t, err)
+
+	conn, err := config.Dial("tcp", server.Listener.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
+
+func TestTransportConfig_TLSConfig(t *testing.T) {
+	conf, err := TransportConfig{}.TLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), conf.MinVersion)
+	assert.Nil(t, conf.RootCAs)
+
+	conf, err = TransportConfig{MinVersion: tls.VersionTLS13, ServerName: "logs.example.com"}.TLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), conf.MinVersion)
+	assert.Equal(t, "logs.example.com", conf.ServerName)
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	assert.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err = TransportConfig{CAFile: empty}.TLSConfig()
+	assert.EqualError(t, err, "zl: no certificates in "+empty)
+	_, err = TransportConfig{CertFile: "not_found.pem", KeyFile: "not_found.pem"}.TLSConfig()
+	assert.Error(t, err)
+}
+
+func TestNewOTLPWriter_transportError(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	w := NewOTLPWriter(OTLPConfig{Endpoint: "https://localhost", Transport: TransportConfig{CAFile: "not_found.pem"}})
+	_, err := w.Write([]byte("{}\n"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, w.Close(), os.ErrNotExist)
+	ResetGlobalLoggerSettings()
+}
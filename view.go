@@ -0,0 +1,172 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	au "github.com/logrusorgru/aurora/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ViewConfig is the config of View.
+type ViewConfig struct {
+	// Level is the minimum level of the logs to display. The zero value is InfoLevel.
+	Level zapcore.Level
+	// Since is the time of the oldest log to display. Zero means all the logs.
+	Since time.Time
+	// NoColor removes the colors. The colors are also removed if the writer is not a terminal. See: SetColor
+	NoColor bool
+}
+
+// View reads the json logs written by zl from r and writes them to w in the same format as PrettyOutput.
+// The pretty settings such as SetConsoleFields, SetPrettyFields, SetTheme and SetPrettyLayout are used,
+// and the time and the caller of each log are displayed instead of the current ones.
+// The lines that are not json logs are written as they are.
+// It is used by the zlv command. See: cmd/zlv
+func View(w io.Writer, r io.Reader, config ViewConfig) error {
+	return view(w, r, config, nil)
+}
+
+// view writes the json logs of r to w. pace is called with the time of each log before it is written if it is not nil.
+func view(w io.Writer, r io.Reader, config ViewConfig, pace func(t time.Time)) error {
+	l := &prettyLogger{
+		Logger:      log.New(w, "", log.Ldate|log.Ltime|log.Lshortfile),
+		internalLog: log.New(io.Discard, "", 0),
+		noColor:     config.NoColor || !colorEnabled(w),
+	}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err2 := l.view(line, config, pace); err2 != nil {
+				return err2
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// view writes a json log line.
+func (l *prettyLogger) view(line []byte, config ViewConfig, pace func(t time.Time)) error {
+	e, err := parseJSONLog(line)
+	if err != nil {
+		return l.write(string(bytes.TrimRight(line, "\n")) + "\n")
+	}
+	if e.level < config.Level || (!config.Since.IsZero() && !e.time.IsZero() && e.time.Before(config.Since)) {
+		return nil
+	}
+	l.name = e.logger
+	l.Logger.SetPrefix("")
+	if e.logger != "" {
+		l.Logger.SetPrefix(fmt.Sprintf("%s | ", e.logger))
+	}
+	entry, body, _, _, ok := l.format(e.level, e.message, e.err, e.err != nil, e.fields)
+	if !ok {
+		return nil
+	}
+	if prettyStacktrace && e.stacktrace != "" {
+		entry.stacktrace = fmt.Sprintf("\n\t%s:\n\t  %s",
+			au.Colorize("stacktrace", theme.Attr), strings.ReplaceAll(e.stacktrace, "\n", "\n\t  "))
+	}
+	if pace != nil && !e.time.IsZero() {
+		pace(e.time)
+	}
+	return l.outputAt(e.time, l.viewCaller(e.caller), body+entry.stacktrace, entry)
+}
+
+// viewCaller returns the caller formatted by formatCaller if it is `file:line`.
+func (l *prettyLogger) viewCaller(caller string) string {
+	i := strings.LastIndex(caller, ":")
+	if i < 0 {
+		return caller
+	}
+	line, err := strconv.Atoi(caller[i+1:])
+	if err != nil {
+		return caller
+	}
+	return formatCaller(caller[:i], line, l.Logger.Flags())
+}
+
+// jsonLog is a json log parsed by parseJSONLog.
+type jsonLog struct {
+	time       time.Time
+	level      zapcore.Level
+	logger     string
+	caller     string
+	message    string
+	err        error
+	stacktrace string
+	fields     []zap.Field // fields are in the order of the json log.
+}
+
+// parseJSONLog parses the json log written by the keys of SetFieldKey.
+func parseJSONLog(line []byte) (*jsonLog, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("zl: not a json log: %q", line)
+	}
+	e := &jsonLog{level: InfoLevel}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		s, isString := v.(string)
+		switch {
+		case key == fieldKey(TimeKey) && isString:
+			e.time, _ = time.Parse(time.RFC3339Nano, s)
+		case key == fieldKey(LevelKey) && isString:
+			_ = e.level.UnmarshalText([]byte(s))
+		case key == fieldKey(LoggerKey) && isString:
+			e.logger = s
+		case key == fieldKey(CallerKey) && isString:
+			e.caller = s
+		case key == fieldKey(MessageKey) && isString:
+			e.message = s
+		case key == fieldKey(StacktraceKey) && isString:
+			e.stacktrace = s
+		case key == "error" && isString:
+			e.err = errors.New(s)
+		case key == fieldKey(FunctionKey):
+		default:
+			e.fields = append(e.fields, jsonField(key, v))
+		}
+	}
+	return e, nil
+}
+
+// jsonField returns the field of the decoded json value.
+// The numbers are integers if possible so that they are displayed as console fields.
+func jsonField(key string, v interface{}) zap.Field {
+	switch val := v.(type) {
+	case string:
+		return zap.String(key, val)
+	case json.Number:
+		if i, err := strconv.ParseInt(val.String(), 10, 64); err == nil {
+			return zap.Int64(key, i)
+		}
+		f, _ := val.Float64()
+		return zap.Float64(key, f)
+	}
+	return zap.Any(key, v)
+}
@@ -0,0 +1,54 @@
+package zl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	SetConsoleFields("console", "user_id")
+	SetPrettyFields(PrettyFieldsKeyValue)
+	logs := strings.Join([]string{
+		`{"severity":"DEBUG","timestamp":"2026-10-14T09:00:00Z","message":"OLD_DEBUG"}`,
+		`{"severity":"INFO","timestamp":"2026-10-14T10:00:00Z","caller":"zl/app.go:12","message":"USER_CREATED","user_id":"u1","count":3}`,
+		`not a json log`,
+		`{"severity":"ERROR","timestamp":"2026-10-14T10:00:01Z","logger":"worker","caller":"zl/job.go:5","message":"JOB_FAILED","error":"boom"}`,
+		``,
+	}, "\n")
+
+	var buf bytes.Buffer
+	err := View(&buf, strings.NewReader(logs), ViewConfig{Level: InfoLevel, NoColor: true})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"2026/10/14 10:00:00 app.go:12: INFO USER_CREATED u1 count=3",
+		"not a json log",
+		"worker | 2026/10/14 10:00:01 job.go:5: ERROR JOB_FAILED boom",
+		"",
+	}, "\n"), buf.String())
+
+	buf.Reset()
+	since := time.Date(2026, 10, 14, 10, 0, 1, 0, time.UTC)
+	err = View(&buf, strings.NewReader(logs), ViewConfig{Since: since, NoColor: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "not a json log\nworker | 2026/10/14 10:00:01 job.go:5: ERROR JOB_FAILED boom\n", buf.String())
+}
+
+func Test_parseJSONLog(t *testing.T) {
+	e, err := parseJSONLog([]byte(`{"severity":"WARN","message":"M","stacktrace":"main.main","n":1.5,"tags":["a"]}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, WarnLevel, e.level)
+	assert.Equal(t, "M", e.message)
+	assert.Equal(t, "main.main", e.stacktrace)
+	assert.Len(t, e.fields, 2)
+	assert.Equal(t, "n", e.fields[0].Key)
+
+	_, err = parseJSONLog([]byte(`[1]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package zl
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// HighMemoryMessage is the message of the internal WARNING logged by WatchResources
+	// when the RSS crosses WatermarkConfig.MaxRSSMB.
+	HighMemoryMessage = "HIGH_MEMORY_USAGE"
+	// HighGoroutinesMessage is the message of the internal WARNING logged by WatchResources
+	// when the goroutine count crosses WatermarkConfig.MaxGoroutines.
+	HighGoroutinesMessage = "HIGH_GOROUTINE_COUNT"
+
+	// DefaultWatermarkInterval is the default interval of WatchResources.
+	DefaultWatermarkInterval = 10 * time.Second
+)
+
+var (
+	watermarkMu   sync.Mutex
+	watermarkStop func()
+
+	rssFunc          = residentMemory
+	numGoroutineFunc = runtime.NumGoroutine
+)
+
+// WatermarkConfig is the config of WatchResources.
+type WatermarkConfig struct {
+	// MaxRSSMB is the threshold of the resident memory in megabytes. 0 means it is not watched.
+	// The RSS is only supported on Linux.
+	MaxRSSMB int
+	// MaxGoroutines is the threshold of the goroutine count. 0 means it is not watched.
+	MaxGoroutines int
+	// Interval is the interval to check them. Default is DefaultWatermarkInterval.
+	Interval time.Duration
+}
+
+// watermarkState is whether the thresholds have been crossed at the last check.
+type watermarkState struct {
+	highRSS        bool
+	highGoroutines bool
+}
+
+// WatchResources checks the RSS and the goroutine count every interval in the background,
+// and logs the internal WARNING with the Console summary when they cross the thresholds and when they recover,
+// so that the small services without the metrics stack can notice the leaks and the spikes from the logs.
+// e.g. WatchResources(WatermarkConfig{MaxRSSMB: 512, MaxGoroutines: 10000})
+// It replaces the previous WatchResources. It returns the func to stop it.
+// It must be called after Init.
+func WatchResources(config WatermarkConfig) (stop func()) {
+	checkInit()
+	watermarkMu.Lock()
+	defer watermarkMu.Unlock()
+	stopWatchResources()
+	if config.MaxRSSMB <= 0 && config.MaxGoroutines <= 0 {
+		return func() {}
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultWatermarkInterval
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	done := make(chan struct{})
+	go func() {
+		state := &watermarkState{}
+		for {
+			select {
+			case <-ticker.C:
+				checkWatermarks(config, state)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	watermarkStop = func() {
+		stopOnce.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+	return watermarkStop
+}
+
+// checkWatermarks logs the crossings of the thresholds since the last check.
+func checkWatermarks(config WatermarkConfig, state *watermarkState) {
+	goroutines := numGoroutineFunc()
+	rss, rssOK := rssFunc()
+	fields := []zap.Field{zap.Int("goroutines", goroutines)}
+	if rssOK {
+		fields = append(fields, zap.Uint64("rss", rss))
+	}
+
+	if config.MaxRSSMB > 0 && rssOK {
+		high := rss >= uint64(config.MaxRSSMB)*megabyte
+		if high != state.highRSS {
+			state.highRSS = high
+			c := fmt.Sprintf("RSS: %d MB, Threshold: %d MB", rss/megabyte, config.MaxRSSMB)
+			if high {
+				iWarn(HighMemoryMessage, append(fields, Console(c))...)
+			} else {
+				iWarn("MEMORY_USAGE_RECOVERED", append(fields, Console(c))...)
+			}
+		}
+	}
+	if config.MaxGoroutines > 0 {
+		high := goroutines >= config.MaxGoroutines
+		if high != state.highGoroutines {
+			state.highGoroutines = high
+			c := fmt.Sprintf("Goroutines: %d, Threshold: %d", goroutines, config.MaxGoroutines)
+			if high {
+				iWarn(HighGoroutinesMessage, append(fields, Console(c))...)
+			} else {
+				iWarn("GOROUTINE_COUNT_RECOVERED", append(fields, Console(c))...)
+			}
+		}
+	}
+}
+
+func resetWatchResources() {
+	watermarkMu.Lock()
+	defer watermarkMu.Unlock()
+	stopWatchResources()
+}
+
+// stopWatchResources stops the current WatchResources. watermarkMu must be held.
+func stopWatchResources() {
+	if watermarkStop != nil {
+		watermarkStop()
+		watermarkStop = nil
+	}
+}
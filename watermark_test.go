@@ -0,0 +1,83 @@
+package zl
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stubResources(t *testing.T, rss *atomic.Uint64, goroutines *atomic.Int64) {
+	rssFunc = func() (uint64, bool) { return rss.Load(), true }
+	numGoroutineFunc = func() int { return int(goroutines.Load()) }
+	t.Cleanup(func() {
+		rssFunc = residentMemory
+		numGoroutineFunc = runtime.NumGoroutine
+	})
+}
+
+func Test_residentMemory(t *testing.T) {
+	rss, ok := residentMemory()
+	if !ok {
+		t.Skip("the RSS is not supported on this platform")
+	}
+	assert.Greater(t, rss, uint64(0))
+}
+
+func Test_checkWatermarks(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	var rss atomic.Uint64
+	var goroutines atomic.Int64
+	stubResources(t, &rss, &goroutines)
+	obs := NewTestObserver(t)
+	Init()
+	config := WatermarkConfig{MaxRSSMB: 100, MaxGoroutines: 1000}
+	state := &watermarkState{}
+
+	rss.Store(50 * megabyte)
+	goroutines.Store(10)
+	checkWatermarks(config, state)
+	assert.Equal(t, 0, obs.FilterLevel(WarnLevel).Len())
+
+	rss.Store(150 * megabyte)
+	goroutines.Store(2000)
+	checkWatermarks(config, state)
+	checkWatermarks(config, state)
+	if assert.Equal(t, 1, obs.FilterMessage(HighMemoryMessage).Len()) {
+		e := obs.FilterMessage(HighMemoryMessage).All()[0]
+		assert.Equal(t, WarnLevel, e.Level)
+		assert.Equal(t, "RSS: 150 MB, Threshold: 100 MB", e.ContextMap()[consoleFieldDefault])
+		assert.Equal(t, uint64(150*megabyte), e.ContextMap()["rss"])
+	}
+	if assert.Equal(t, 1, obs.FilterMessage(HighGoroutinesMessage).Len()) {
+		e := obs.FilterMessage(HighGoroutinesMessage).All()[0]
+		assert.Equal(t, "Goroutines: 2000, Threshold: 1000", e.ContextMap()[consoleFieldDefault])
+	}
+
+	rss.Store(50 * megabyte)
+	goroutines.Store(10)
+	checkWatermarks(config, state)
+	assert.Equal(t, 1, obs.FilterMessage("MEMORY_USAGE_RECOVERED").Len())
+	assert.Equal(t, 1, obs.FilterMessage("GOROUTINE_COUNT_RECOVERED").Len())
+}
+
+func TestWatchResources(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	var rss atomic.Uint64
+	var goroutines atomic.Int64
+	stubResources(t, &rss, &goroutines)
+	obs := NewTestObserver(t)
+	Init()
+
+	goroutines.Store(10)
+	stop := WatchResources(WatermarkConfig{MaxGoroutines: 5, Interval: 5 * time.Millisecond})
+	assert.Eventually(t, func() bool { return obs.FilterMessage(HighGoroutinesMessage).Len() == 1 }, time.Second, 5*time.Millisecond)
+	stop()
+	stop()
+
+	assert.NotNil(t, WatchResources(WatermarkConfig{}), "nothing is watched")
+}
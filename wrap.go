@@ -0,0 +1,72 @@
+package zl
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// wrappedError is the error returned by Wrap.
+type wrappedError struct {
+	message string
+	err     error
+	fields  []zap.Field
+}
+
+func (e *wrappedError) Error() string {
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// LogFields returns the fields of e and the errors wrapped by it. See: ErrorFielder
+func (e *wrappedError) LogFields() []zap.Field {
+	fields := e.fields[:len(e.fields):len(e.fields)]
+	var fielder ErrorFielder
+	if errors.As(e.err, &fielder) {
+		fields = append(fields, fielder.LogFields()...)
+	}
+	return fields
+}
+
+// isLogged reports whether err has already been logged by Wrap.
+func isLogged(err error) bool {
+	var w *wrappedError
+	return errors.As(err, &w)
+}
+
+// Wrap write error log and return err wrapped with message and fields.
+// The error message is "message: err", and the fields are added to the logs of the Err functions
+// such as ErrorErr that the wrapped error is passed to. See: ErrorFielder
+// The error log is written only by the innermost Wrap so that the same error is logged once
+// even if it is wrapped at each layer. It returns nil if err is nil.
+// e.g.
+//
+//	if err != nil {
+//	  return zl.Wrap(err, "READ_CONFIG", zap.String("path", path))
+//	}
+func Wrap(err error, message string, fields ...zap.Field) error {
+	if err == nil {
+		return nil
+	}
+	if !isLogged(err) {
+		loggerErr(message, ErrorLevel, err, fields).Error(message, appendFingerprint(append(fields, errorFields(err)...), message, err)...)
+	}
+	return &wrappedError{message: message, err: err, fields: append([]zap.Field(nil), fields...)}
+}
+
+// Wrap write error log and return err wrapped with message and fields. See: Wrap
+func (l *Logger) Wrap(err error, message string, fields ...zap.Field) error {
+	if err == nil {
+		return nil
+	}
+	if !isLogged(err) && l.enabled(ErrorLevel) {
+		fs := l.appendFields(fields, errorFields(err)...)
+		fs.fields = appendFingerprint(fs.fields, message, err)
+		l.loggerErr(message, ErrorLevel, err, fs.fields).Error(message, fs.fields...)
+		fs.free()
+	}
+	return &wrappedError{message: message, err: err, fields: append([]zap.Field(nil), fields...)}
+}
@@ -0,0 +1,44 @@
+package zl
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWrap(t *testing.T) {
+	obs := NewTestObserver(t)
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+	Init()
+
+	base := errors.New("no such file")
+	err := Wrap(base, "READ_CONFIG", zap.String("path", "config.yml"))
+	err = New().Wrap(err, "LOAD_SETTINGS", zap.String("env", "dev"))
+	assert.Equal(t, "LOAD_SETTINGS: READ_CONFIG: no such file", err.Error())
+	assert.ErrorIs(t, err, base)
+
+	// Only the innermost Wrap writes the log.
+	entries := obs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, ErrorLevel, entries[0].Level)
+		assert.Equal(t, "READ_CONFIG", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{"error": "no such file", "path": "config.yml"}, entries[0].ContextMap())
+	}
+
+	// The fields of all the layers are added when the wrapped error is logged.
+	ErrorErr("REQUEST_FAILED", err)
+	entries = obs.FilterMessage("REQUEST_FAILED").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, map[string]interface{}{
+			"error": "LOAD_SETTINGS: READ_CONFIG: no such file", "env": "dev", "path": "config.yml",
+		}, entries[0].ContextMap())
+	}
+
+	assert.Nil(t, Wrap(nil, "NOP"))
+	assert.Nil(t, New().Wrap(nil, "NOP"))
+}
@@ -0,0 +1,74 @@
+package zl
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	writeErrorHandler func(err error, p []byte)
+	writeRetries      int
+	writeRetryBackoff time.Duration
+
+	// writeRetrySleep is a variable so that tests can replace it.
+	writeRetrySleep = time.Sleep
+)
+
+// SetWriteErrorHandler set the func called when writing or syncing the log file fails. e.g. the disk is full or the permission is denied.
+// p is the part of the log that is not written, and it is nil for the errors of Sync.
+// p is reused after fn returns, so copy it to keep it. It is called after the retries of SetWriteRetry fail.
+// Do not write the logs to the log file in fn because it may fail again. Write them to the other outputs.
+// e.g.
+//
+//	zl.SetWriteErrorHandler(func(err error, p []byte) {
+//		fmt.Fprintf(os.Stderr, "zl: %v: %s", err, p)
+//	})
+func SetWriteErrorHandler(fn func(err error, p []byte)) {
+	writeErrorHandler = fn
+}
+
+// SetWriteRetry set the number of the retries when writing the log file fails, and the backoff of the first retry.
+// The backoff is doubled for each retry. e.g. SetWriteRetry(3, 100*time.Millisecond) retries after 100ms, 200ms and 400ms.
+// The retries block the logging, so use it with SetAsync. 0 retries (default) means it is not retried.
+func SetWriteRetry(retries int, backoff time.Duration) {
+	writeRetries = retries
+	writeRetryBackoff = backoff
+}
+
+// fileWriteSyncer is a zapcore.WriteSyncer of the log file that retries the failed writes
+// and calls the handler of SetWriteErrorHandler.
+type fileWriteSyncer struct {
+	zapcore.WriteSyncer
+}
+
+func newFileWriteSyncer(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if writeErrorHandler == nil && writeRetries <= 0 {
+		return ws
+	}
+	return fileWriteSyncer{WriteSyncer: ws}
+}
+
+func (s fileWriteSyncer) Write(p []byte) (int, error) {
+	n, err := s.WriteSyncer.Write(p)
+	backoff := writeRetryBackoff
+	for i := 0; err != nil && i < writeRetries; i++ {
+		writeRetrySleep(backoff)
+		backoff *= 2
+		var m int
+		m, err = s.WriteSyncer.Write(p[n:])
+		n += m
+	}
+	if err != nil && writeErrorHandler != nil {
+		writeErrorHandler(err, p[n:])
+	}
+	return n, err
+}
+
+func (s fileWriteSyncer) Sync() error {
+	err := s.WriteSyncer.Sync()
+	if err != nil && writeErrorHandler != nil {
+		writeErrorHandler(err, nil)
+	}
+	return err
+}
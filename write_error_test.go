@@ -0,0 +1,72 @@
+package zl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriteSyncer writes only a byte and fails for the first `failures` writes.
+type failingWriteSyncer struct {
+	failures int
+	written  []byte
+	syncErr  error
+}
+
+func (f *failingWriteSyncer) Write(p []byte) (int, error) {
+	if f.failures > 0 {
+		f.failures--
+		f.written = append(f.written, p[0])
+		return 1, errors.New("no space left on device")
+	}
+	f.written = append(f.written, p...)
+	return len(p), nil
+}
+
+func (f *failingWriteSyncer) Sync() error {
+	return f.syncErr
+}
+
+func Test_fileWriteSyncer(t *testing.T) {
+	t.Cleanup(ResetGlobalLoggerSettings)
+	var sleeps []time.Duration
+	org := writeRetrySleep
+	writeRetrySleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	t.Cleanup(func() { writeRetrySleep = org })
+	var handled []string
+	SetWriteErrorHandler(func(err error, p []byte) {
+		handled = append(handled, err.Error()+":"+string(p))
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		sleeps, handled = nil, nil
+		SetWriteRetry(3, 10*time.Millisecond)
+		ws := &failingWriteSyncer{failures: 2}
+		n, err := newFileWriteSyncer(ws).Write([]byte("abcd"))
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, "abcd", string(ws.written))
+		assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, sleeps)
+		assert.Empty(t, handled)
+	})
+	t.Run("failed", func(t *testing.T) {
+		sleeps, handled = nil, nil
+		SetWriteRetry(1, time.Millisecond)
+		ws := &failingWriteSyncer{failures: 3, syncErr: errors.New("sync failed")}
+		n, err := newFileWriteSyncer(ws).Write([]byte("abcd"))
+		assert.Error(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"no space left on device:cd"}, handled)
+
+		assert.Error(t, newFileWriteSyncer(ws).Sync())
+		assert.Equal(t, "sync failed:", handled[1])
+	})
+}
+
+func Test_newFileWriteSyncer_disabled(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	ws := &failingWriteSyncer{}
+	assert.Same(t, ws, newFileWriteSyncer(ws))
+}
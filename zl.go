@@ -5,6 +5,7 @@
 package zl
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -13,7 +14,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
-	"syscall"
+	"time"
 
 	"github.com/samber/lo"
 	"go.uber.org/zap"
@@ -25,55 +26,70 @@ const (
 )
 
 var (
-	once           sync.Once
-	pretty         *prettyLogger
-	zapLogger      *zap.Logger
-	encoderConfig  *zapcore.EncoderConfig
-	internalLogger *zap.Logger
-	outputType     Output
-	version        string
-	severityLevel  zapcore.Level // Default is InfoLevel
-	callerEncoder  zapcore.CallerEncoder
-	consoleFields  = []string{consoleFieldDefault}
-	omitKeys       []Key
-	fieldKeys      = make(map[Key]string)
-	isStdOut       bool
-	separator      = " "
-	pid            int
-	isTest         bool
+	once               sync.Once
+	pretty             *prettyLogger
+	zapLogger          *zap.Logger
+	encoderConfig      *zapcore.EncoderConfig
+	internalLogger     *zap.Logger
+	outputType         Output
+	version            string
+	severityLevel      zapcore.Level // Default is InfoLevel
+	consoleFields      = []string{consoleFieldDefault}
+	consoleFieldLevels map[string][]zapcore.Level // consoleFieldLevels is the levels of the fields added by AddLevelConsoleFields.
+	omitKeys           []Key
+	enableKeys         []Key
+	fieldKeys          = make(map[Key]string)
+	isStdOut           bool
+	separator          = " "
+	pid                int
+	isTest             bool
+	exitFunc           = os.Exit
+	quietInit          bool
 )
 
 type fatalHook struct{}
 
-func (f fatalHook) OnWrite(_ *zapcore.CheckedEntry, _ []zapcore.Field) {
-	pretty.showErrorReport(fileName, pid)
+func (f fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	flushAsync() // The queued logs are lost by the exit.
+	writeFatalCrashReport(ce)
+	if testTB != nil {
+		testTB.Errorf("zl: %s is called: %s", ce.Level.CapitalString(), ce.Message)
+		return
+	}
+	if pretty != nil {
+		pretty.showErrorReport(currentFileName(), pid)
+	}
 	if isTest {
 		fmt.Println("os.Exit(1) called.")
 	} else {
-		os.Exit(1)
+		exitFunc(1)
 	}
 }
 
 // Init initializes the logger.
 func Init() {
 	once.Do(func() {
+		startTime = clock.Now()
+		filterErr := setConsoleFilterFromEnv()
 		encoderConfig = newEncoderConfig()
 		zapLogger = newLogger(encoderConfig)
 		if outputType == PrettyOutput || isTest {
 			pretty = newPrettyLogger(getConsoleOutput(), os.Stderr)
-			zapLogger = zapLogger.WithOptions(zap.WithFatalHook(fatalHook{}))
 		}
 
 		encInternal := newEncoderConfig()
 		encInternal.EncodeCaller = zapcore.ShortCallerEncoder
-		internalLogger = newLogger(encInternal)
+		internalLogger = newLogger(encInternal).WithOptions(zap.WrapCore(withoutFieldTypeCheck), zap.WrapCore(withoutMessageCatalog))
+		if filterErr != nil {
+			iWarn("INVALID_CONSOLE_FILTER", Console(filterErr.Error()), zap.Error(filterErr))
+		}
 
 		var p, f string
 		if pid != 0 {
 			p = fmt.Sprintf(", PID: %d", pid)
 		}
 		if outputType == PrettyOutput || outputType == ConsoleAndFileOutput {
-			f = fmt.Sprintf(", File: %s", fileName)
+			f = fmt.Sprintf(", File: %s", currentFileName())
 		}
 
 		c := fmt.Sprintf(
@@ -83,7 +99,9 @@ func Init() {
 			f,
 			p,
 		)
-		iDebug("INIT_LOGGER", Console(c))
+		if !quietInit {
+			iDebug("INIT_LOGGER", Console(c))
+		}
 	})
 }
 
@@ -115,16 +133,35 @@ func fieldKey(key Key) string {
 
 // See https://pkg.go.dev/go.uber.org/zap
 func newLogger(enc *zapcore.EncoderConfig) *zap.Logger {
-	core := zapcore.NewCore(
+	return newLoggerWithSyncers(enc, getSyncers(func() *rotator {
+		setRotateDefault()
+		return getRotator(fileName)
+	}))
+}
+
+func newLoggerWithSyncers(enc *zapcore.EncoderConfig, syncers []zapcore.WriteSyncer) *zap.Logger {
+	console, syncers := splitConsoleSyncers(syncers)
+	ioCore := zapcore.NewCore(
 		zapcore.NewJSONEncoder(*enc),
-		zapcore.NewMultiWriteSyncer(getSyncers()...),
-		severityLevel,
+		newAsyncWriteSyncer(statsWriteSyncer{zapcore.NewMultiWriteSyncer(syncers...)}),
+		minSeverityLevel(),
 	)
-	return zap.New(core,
+	if console != nil {
+		ioCore = zapcore.NewTee(ioCore, zapcore.NewCore(
+			newConsoleEncoder(enc),
+			newAsyncWriteSyncer(statsWriteSyncer{zapcore.NewMultiWriteSyncer(console...)}),
+			minSeverityLevel(),
+		))
+	}
+	core := newGoroutineDumpCore(newRequiredFieldsCore(newRedactCore(newRateLimitCore(newDedupCore(&statsCore{Core: newRuntimeStatsCore(newEntryFieldsCore(newFunctionNameCore(ioCore)))})))))
+	opts := []zap.Option{
 		zap.AddCallerSkip(1),
 		zap.AddCaller(),
 		zap.AddStacktrace(zapcore.ErrorLevel),
-	).With(getAdditionalFields()...)
+		zap.WithClock(clock),
+		zap.WithFatalHook(fatalHook{}),
+	}
+	return zap.New(newFieldTypeCore(newMessageCatalogCore(newLevelOverrideCore(newFlightRecorderCore(newPackageLevelCore(newTestObserverCore(newFileLevelCore(core))), enc)))), opts...).With(getAdditionalFields()...)
 }
 
 func setOmitKeys(enc *zapcore.EncoderConfig) {
@@ -149,32 +186,80 @@ func setOmitKeys(enc *zapcore.EncoderConfig) {
 }
 
 func getAdditionalFields() (fields []zapcore.Field) {
-	if !lo.Contains(omitKeys, VersionKey) {
-		fields = append(fields, zap.String(string(VersionKey), GetVersion()))
-	}
+	fields = append(fields, getServiceFields()...)
 	if !lo.Contains(omitKeys, HostnameKey) {
-		fields = append(fields, zap.String(string(HostnameKey), *getHost()))
+		fields = append(fields, zap.String(string(HostnameKey), *cachedHost()))
 	}
 	if !lo.Contains(omitKeys, PIDKey) {
-		pid = os.Getpid()
+		pid = cachedPID()
 		fields = append(fields, zap.Int(string(PIDKey), pid))
 	}
+	if lo.Contains(enableKeys, ContainerIDKey) {
+		if id := cachedContainerID(); id != "" {
+			fields = append(fields, zap.String(string(ContainerIDKey), id))
+		}
+	}
+	if lo.Contains(enableKeys, ContainerImageKey) {
+		if image := getContainerImage(); image != "" {
+			fields = append(fields, zap.String(string(ContainerImageKey), image))
+		}
+	}
+	if lo.Contains(enableKeys, VCSTimeKey) {
+		if t := getVCSTime(); t != "" {
+			fields = append(fields, zap.String(string(VCSTimeKey), t))
+		}
+	}
+	if lo.Contains(enableKeys, GoVersionKey) {
+		fields = append(fields, zap.String(string(GoVersionKey), getGoVersion()))
+	}
+	if lo.Contains(enableKeys, UserKey) {
+		if name := cachedUserName(); name != "" {
+			fields = append(fields, zap.String(string(UserKey), name))
+		}
+	}
+	if lo.Contains(enableKeys, ExecutableKey) {
+		if exe := cachedExecutable(); exe != "" {
+			fields = append(fields, zap.String(string(ExecutableKey), exe))
+		}
+	}
+	if lo.Contains(enableKeys, ArgsKey) {
+		fields = append(fields, zap.Strings(string(ArgsKey), redactArgs(os.Args[1:])))
+	}
+	if lo.Contains(enableKeys, PPIDKey) {
+		fields = append(fields, zap.Int(string(PPIDKey), os.Getppid()))
+	}
 	return fields
 }
 
 // GetVersion return version when version is set.
-// or return git commit hash when version is not set.
+// or return the vcs revision embedded in the binary by the go command when version is not set.
+// If SetGitVersionFallback is enabled, it returns git commit hash when neither is available.
+// The embedded version and the git commit hash are computed once in the process.
 func GetVersion() string {
 	if version != "" {
 		return version
 	}
-	if out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output(); err == nil {
-		return strings.TrimRight(string(out), "\n")
+	if v := cachedBuildVersion(); v != "" {
+		return v
+	}
+	if gitVersionFallback {
+		if v := cachedGitVersion(); v != "" {
+			return v
+		}
 	}
 
 	return "undefined"
 }
 
+// getGitVersion returns the short commit hash of the working directory. It returns empty if git fails.
+func getGitVersion() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(out), "\n")
+}
+
 // Sync is wrapper of Zap's Sync.
 //
 // Flushes any buffered log entries.(See: https://pkg.go.dev/go.uber.org/zap#Logger.Sync)
@@ -185,48 +270,94 @@ func GetVersion() string {
 //
 // An error will occur if zap's Sync is executed when the output destination is console.
 // (See: https://github.com/uber-go/zap/issues/880 )
-// Therefore, Sync is executed only when console is not included in the zap output destination.
+// Therefore, the console output of zap is not synced.
 func Sync() {
-	if outputType != PrettyOutput && outputType != FileOutput {
-		return
-	}
+	flushAsync()
+	reportDropped()
 	if err := zapLogger.Sync(); err != nil {
 		log.Println(err)
 	}
-	if outputType == PrettyOutput {
-		pretty.showErrorReport(fileName, pid)
+	if outputType == PrettyOutput && testTB == nil {
+		pretty.progress("")
+		pretty.showErrorReport(currentFileName(), pid)
+		pretty.showSummary()
 	}
 }
 
 // SyncWhenStop flush log buffer. when interrupt or terminated.
-func SyncWhenStop() {
-	if outputType != PrettyOutput && outputType != FileOutput {
-		return
+// It exits the process after that. Use SyncOnShutdown to exit by the application.
+// The signals and the exit code can be changed by WithSignals and WithBeforeExit.
+// It returns the func to unregister the handler.
+// e.g.
+//
+//	stop := zl.SyncWhenStop(zl.WithSignals(syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT), zl.WithBeforeExit(func(sig os.Signal, code int) int {
+//		db.Close()
+//		return code
+//	}))
+//	defer stop()
+func SyncWhenStop(opts ...StopOption) (stop func()) {
+	if testTB != nil || (outputType != PrettyOutput && outputType != FileOutput) {
+		return func() {}
 	}
+	config := newStopConfig(opts)
 
 	c := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	signal.Notify(c, config.signals...)
 
 	go func() {
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		s := <-c
-
-		sigCode := 0
-		switch s.String() {
-		case "interrupt":
-			sigCode = 2
-		case "terminated":
-			sigCode = 15
+		var s os.Signal
+		select {
+		case s = <-c:
+		case <-stopped:
+			return
 		}
 
-		iDebug(fmt.Sprintf("GOT_SIGNAL_%v", strings.ToUpper(s.String())))
+		if !quietInit {
+			iDebug(fmt.Sprintf("GOT_SIGNAL_%v", strings.ToUpper(s.String())))
+		}
+		code := 128 + signalNumber(s)
+		if config.beforeExit != nil {
+			code = config.beforeExit(s, code)
+		}
 		Sync() // flush log buffer
 
 		if isTest {
-			fmt.Printf("os.Exit(%d) called.", 128+sigCode)
+			fmt.Printf("os.Exit(%d) called.", code)
 		} else {
-			os.Exit(128 + sigCode)
+			exitFunc(code)
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(c)
+			close(stopped)
+		})
+	}
+}
+
+// SyncOnShutdown flushes the log buffer when ctx is done, and closes the returned channel after that.
+// Unlike SyncWhenStop, it does not exit the process, so that the application keeps control of the termination.
+// e.g.
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	flushed := zl.SyncOnShutdown(ctx)
+//	// ... run the servers until ctx is done.
+//	<-flushed
+func SyncOnShutdown(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		if !quietInit {
+			iDebug("SHUTDOWN", zap.NamedError("cause", context.Cause(ctx)))
 		}
+		Sync()
 	}()
+	return done
 }
 
 func getHost() *string {
@@ -239,25 +370,41 @@ func getHost() *string {
 }
 
 func getCallerEncoder() zapcore.CallerEncoder {
-	if callerEncoder != nil {
-		return callerEncoder
+	if len(repositoryRoots) > 0 {
+		return repositoryCallerEncoder
 	}
 	return zapcore.ShortCallerEncoder
 }
 
-func getSyncers() (syncers []zapcore.WriteSyncer) {
+// getSyncers returns the syncers of the outputType. newFile is called only if the logs are written to the file.
+func getSyncers(newFile func() *rotator) (syncers []zapcore.WriteSyncer) {
+	if testTB != nil {
+		if outputType != PrettyOutput {
+			syncers = append(syncers, consoleSyncer())
+		}
+		return append(syncers, sinks...)
+	}
 	switch outputType {
 	case PrettyOutput, FileOutput:
-		syncers = append(syncers, zapcore.AddSync(newRotator()))
+		syncers = append(syncers, newFileWriteSyncer(zapcore.AddSync(newFile())))
 	case ConsoleAndFileOutput:
-		syncers = append(syncers, zapcore.AddSync(getConsoleOutput()), zapcore.AddSync(newRotator()))
+		syncers = append(syncers, consoleSyncer(), newFileWriteSyncer(zapcore.AddSync(newFile())))
 	case ConsoleOutput:
-		syncers = append(syncers, zapcore.AddSync(getConsoleOutput()))
+		syncers = append(syncers, consoleSyncer())
 	}
-	return
+	return append(syncers, sinks...)
+}
+
+// consoleSyncer returns the console output that is not synced,
+// because syncing stdout and stderr fails for the terminals and the pipes.
+func consoleSyncer() zapcore.WriteSyncer {
+	return consoleWriteSyncer{getConsoleOutput()}
 }
 
 func getConsoleOutput() io.Writer {
+	if testTB != nil {
+		return testLogWriter{t: testTB}
+	}
 	if isStdOut {
 		return os.Stdout
 	} else {
@@ -275,19 +422,106 @@ func ResetGlobalLoggerSettings() {
 	internalLogger = nil
 	outputType = PrettyOutput
 	version = ""
+	pid = 0
+	errorFingerprint = false
+	redactKeys = nil
+	scrubbers = nil
+	hashKeys = nil
+	hashSecret = nil
+	closeAudit()
+	auditFileName = AuditFileNameDefault
+	auditSigner = nil
+	auditSignEvery = 0
+	resetRequiredFields()
+	gitVersionFallback = false
+	serviceName = ""
+	environment = ""
+	schema = DefaultSchema
 	severityLevel = zapcore.InfoLevel
-	callerEncoder = nil
+	consoleLevel = nil
+	fileLevel = nil
+	repositoryRoots = nil
+	functionNameFormat = FunctionNameFull
+	resetProcessInfo()
 	consoleFields = []string{consoleFieldDefault}
+	consoleFieldLevels = nil
 	omitKeys = nil
+	enableKeys = nil
+	containerImage = ""
 	fieldKeys = make(map[Key]string)
 	isStdOut = false
 	separator = " "
+	prettyFields = PrettyFieldsNone
+	prettyFieldsWidth = 0
+	theme = DarkTheme
+	colorMode = ColorAuto
+	consoleEncoding = ConsoleEncodingJSON
+	prettyCollapse = false
+	lastLineKey = ""
+	lastLineCount = 0
+	prettyLayout = nil
+	prettyStacktrace = false
+	dumpConfig = newDumpConfig()
+	dumpToFile = false
+	progressLine = ""
+	prettySummary = false
+	summary = newSummaryStats()
+	consoleFilter = nil
+	timeMode = TimeWallClock
+	startTime = time.Time{}
+	lastTime = time.Time{}
+	callerLink = CallerLinkNone
 	fileName = ""
 	maxSize = 0
 	maxBackups = 0
 	maxAge = 0
 	localTime = false
 	compress = false
+	interval = 0
+	onRotate = nil
+	compression = CompressionGzip
+	compressionLevel = 0
+	diskGuardMinFree = 0
+	diskGuardAction = DiskGuardConsoleOnly
+	diskGuard = &diskGuardState{}
+	fileMode = 0
+	dirMode = 0
+	fileUID, fileGID = -1, -1
+	reopen = false
+	namedFiles = make(map[string]string)
+	resetAutoSync()
+	resetWatchResources()
+	resetAsync()
+	closeRotators()
+	dedupWindow = 0
+	dedupKeys = nil
+	dedup.reset()
+	resetRateLimits()
+	flightRecorderSize = 0
+	crashReportFile = ""
+	recorder = nil
+	levelOverrides = nil
+	packageLevels = nil
+	resetMessageCatalog()
+	measureThreshold = 0
+	sampledCounters = sync.Map{}
+	resetEntryFields()
+	goroutineLabels = sync.Map{}
+	invalidEventNames = sync.Map{}
+	goroutineDump = GoroutineDumpNone
+	goroutineDumpFile = ""
+	sinks = nil
+	resetStats()
+	testObserverCore = nil
+	testTB = nil
+	clock = zapcore.DefaultClock
+	exitFunc = os.Exit
+	quietInit = false
+	writeErrorHandler = nil
+	writeRetries, writeRetryBackoff = 0, 0
+	healthWindow = 0
+	resetFieldTypeCheck()
+	resetRuntimeStats()
 }
 
 // Cleanup
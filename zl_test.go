@@ -1,8 +1,15 @@
 package zl
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestResetGlobalLoggerSettings(t *testing.T) {
@@ -16,3 +23,36 @@ func TestResetCleanup(t *testing.T) {
 	Cleanup()
 	assert.Equal(t, PrettyOutput, outputType)
 }
+
+func TestSyncOnShutdown(t *testing.T) {
+	ResetGlobalLoggerSettings()
+	t.Cleanup(ResetGlobalLoggerSettings)
+	file := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(file)
+	Init()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := SyncOnShutdown(ctx)
+	Info("RUNNING")
+	select {
+	case <-done:
+		t.Fatal("done before the context is canceled")
+	case <-time.After(10 * time.Millisecond):
+	}
+	cancel(errors.New("stopped"))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("not flushed")
+	}
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[1], `"message":"RUNNING"`)
+	assert.Contains(t, lines[2], `"message":"SHUTDOWN"`)
+	assert.Contains(t, lines[2], `"cause":"stopped"`)
+}
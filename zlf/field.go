@@ -0,0 +1,120 @@
+// Package zlf provides the helpers to build the fields of the common types in the same conventions.
+// e.g. zl.Info("REQUEST", zlf.HTTPRequest(r), zlf.Bytes("size", n))
+package zlf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTPRequestKey is the field key of HTTPRequest.
+const HTTPRequestKey = "http_request"
+
+// HTTPRequest returns the HTTPRequestKey field of the method, the url, the host, the remote address,
+// the user agent, the referer, the protocol and the content length of r.
+// The body and the headers are not added because they may have the credentials.
+func HTTPRequest(r *http.Request) zap.Field {
+	if r == nil {
+		return zap.Skip()
+	}
+	return zap.Object(HTTPRequestKey, httpRequest{r})
+}
+
+type httpRequest struct {
+	r *http.Request
+}
+
+func (h httpRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("method", h.r.Method)
+	if h.r.URL != nil {
+		enc.AddString("url", h.r.URL.String())
+		enc.AddString("path", h.r.URL.Path)
+	}
+	enc.AddString("host", h.r.Host)
+	enc.AddString("remote_addr", h.r.RemoteAddr)
+	if ua := h.r.UserAgent(); ua != "" {
+		enc.AddString("user_agent", ua)
+	}
+	if ref := h.r.Referer(); ref != "" {
+		enc.AddString("referer", ref)
+	}
+	enc.AddString("proto", h.r.Proto)
+	enc.AddInt64("content_length", h.r.ContentLength)
+	return nil
+}
+
+// Duration returns the field of d as the human readable string such as "1.5s".
+// Use zap.Duration to write it as the number of the nanoseconds.
+func Duration(key string, d time.Duration) zap.Field {
+	return zap.String(key, d.String())
+}
+
+// Bytes returns the field of the size of n bytes as the human readable string in the IEC units such as "1.5 MiB".
+func Bytes(key string, n int64) zap.Field {
+	return zap.String(key, formatBytes(n))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Stringer returns the field of v.String(). It is safe for the nil values unlike zap.Stringer.
+func Stringer(key string, v fmt.Stringer) zap.Field {
+	if v == nil {
+		return zap.String(key, "<nil>")
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return zap.String(key, "<nil>")
+	}
+	return zap.Stringer(key, v)
+}
+
+// JSON returns the field of v embedded as json.
+// v can be the encoded json such as []byte, json.RawMessage and string, or a value encoded by encoding/json.
+// The invalid json is written as a string.
+func JSON(key string, v interface{}) zap.Field {
+	var b []byte
+	switch val := v.(type) {
+	case json.RawMessage:
+		b = val
+	case []byte:
+		b = val
+	case string:
+		b = []byte(val)
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return zap.String(key, fmt.Sprintf("%+v", v))
+		}
+	}
+	if !json.Valid(b) {
+		return zap.ByteString(key, b)
+	}
+	return zap.Reflect(key, json.RawMessage(b))
+}
+
+// Redacted returns the field of zl.RedactedValue instead of v.
+// It is used to show that the value exists without writing it.
+func Redacted(key string, _ interface{}) zap.Field {
+	return zap.String(key, zl.RedactedValue)
+}
@@ -0,0 +1,75 @@
+package zlf
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func encode(t *testing.T, fields ...zap.Field) string {
+	t.Helper()
+	buf, err := zapcore.NewJSONEncoder(zapcore.EncoderConfig{}).EncodeEntry(zapcore.Entry{}, fields)
+	assert.NoError(t, err)
+	return buf.String()
+}
+
+func TestHTTPRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "http://example.com/users?id=1", nil)
+	r.Header.Set("User-Agent", "test")
+	r.Header.Set("Authorization", "Bearer secret")
+	assert.Equal(t, `{"http_request":{"method":"POST","url":"http://example.com/users?id=1","path":"/users",`+
+		`"host":"example.com","remote_addr":"192.0.2.1:1234","user_agent":"test","proto":"HTTP/1.1","content_length":0}}`+"\n",
+		encode(t, HTTPRequest(r)))
+	assert.Equal(t, "{}\n", encode(t, HTTPRequest(nil)))
+}
+
+func TestDuration(t *testing.T) {
+	assert.Equal(t, `{"elapsed":"1.5s"}`+"\n", encode(t, Duration("elapsed", 1500*time.Millisecond)))
+}
+
+func TestBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:              "0 B",
+		1023:           "1023 B",
+		1024:           "1.0 KiB",
+		1536 * 1024:    "1.5 MiB",
+		-2048:          "-2.0 KiB",
+		5 * (1 << 40):  "5.0 TiB",
+		int64(1) << 62: "4.0 EiB",
+	}
+	for n, want := range tests {
+		assert.Equal(t, want, formatBytes(n), n)
+	}
+	assert.Equal(t, zap.String("size", "1.0 KiB"), Bytes("size", 1024))
+}
+
+type testStringer struct{}
+
+func (*testStringer) String() string { return "stringer" }
+
+func TestStringer(t *testing.T) {
+	var nilPtr *testStringer
+	assert.Equal(t, `{"a":"stringer","b":"<nil>","c":"<nil>"}`+"\n",
+		encode(t, Stringer("a", &testStringer{}), Stringer("b", nilPtr), Stringer("c", nil)))
+}
+
+func TestJSON(t *testing.T) {
+	assert.Equal(t, `{"a":{"id":1},"b":[1,2],"c":{"x":true},"d":"not json","e":{"k":"v"}}`+"\n", encode(t,
+		JSON("a", `{"id":1}`),
+		JSON("b", []byte(`[1,2]`)),
+		JSON("c", json.RawMessage(`{"x":true}`)),
+		JSON("d", "not json"),
+		JSON("e", map[string]string{"k": "v"}),
+	))
+	assert.Equal(t, `{"f":"(1+2i)"}`+"\n", encode(t, JSON("f", complex(1, 2))))
+}
+
+func TestRedacted(t *testing.T) {
+	assert.Equal(t, zap.String("token", zl.RedactedValue), Redacted("token", "secret"))
+}
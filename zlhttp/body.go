@@ -0,0 +1,213 @@
+package zlhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/nkmr-jp/zl/zlf"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestBodyKey is the field key of the request body.
+	RequestBodyKey = "request_body"
+	// ResponseBodyKey is the field key of the response body.
+	ResponseBodyKey = "response_body"
+
+	// DefaultMaxBodyBytes is the default max bytes of each body to log.
+	DefaultMaxBodyBytes = 4 * 1024
+
+	// truncatedSuffix is the suffix of the field key that is true if the body is truncated.
+	truncatedSuffix = "_truncated"
+)
+
+// DefaultBodyContentTypes is the default media types of the bodies to log.
+var DefaultBodyContentTypes = []string{"application/json", "application/*+json", "text/*"}
+
+// BodyConfig is the config of the request and the response bodies logged by Middleware.
+// The bodies are logged only for the routes of Routes and the media types of ContentTypes,
+// so that the binary bodies and the routes with the credentials are not logged by mistake.
+// The json bodies are embedded as json, and the others are written as strings.
+type BodyConfig struct {
+	// Routes are the patterns of the routes whose bodies are logged. The routes not matched are not logged.
+	// A pattern is a path of path.Match with an optional method. e.g. "/api/users/*", "POST /api/orders"
+	Routes []string
+	// ContentTypes are the patterns of path.Match of the media types to log. Default is DefaultBodyContentTypes.
+	ContentTypes []string
+	// MaxBytes is the max bytes of each body to log. The rest is not logged,
+	// and the field of the key with the "_truncated" suffix is added. Default is DefaultMaxBodyBytes.
+	MaxBytes int
+	// Redact returns the body to log from the captured body. e.g. RedactJSONKeys("password", "token")
+	// The values of the keys set by zl.SetRedactKeys are not redacted in the bodies without it.
+	Redact func(contentType string, body []byte) []byte
+}
+
+// routeAllowed reports whether the bodies of r are logged.
+func (c *BodyConfig) routeAllowed(r *http.Request) bool {
+	if c == nil {
+		return false
+	}
+	for _, route := range c.Routes {
+		pattern := route
+		if method, p, ok := strings.Cut(route, " "); ok {
+			if method != r.Method {
+				continue
+			}
+			pattern = strings.TrimSpace(p)
+		}
+		if ok, _ := path.Match(pattern, r.URL.Path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// contentTypeAllowed reports whether the body of contentType is logged.
+func (c *BodyConfig) contentTypeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	types := c.ContentTypes
+	if types == nil {
+		types = DefaultBodyContentTypes
+	}
+	for _, pattern := range types {
+		if ok, _ := path.Match(pattern, mediaType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *BodyConfig) maxBytes() int {
+	if c.MaxBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return c.MaxBytes
+}
+
+// newCapture returns the capture of the body of contentType, or nil if it is not logged.
+func (c *BodyConfig) newCapture(contentType string) *bodyCapture {
+	if !c.contentTypeAllowed(contentType) {
+		return nil
+	}
+	return &bodyCapture{contentType: contentType, max: c.maxBytes()}
+}
+
+// captureRequest replaces the body of r to capture the bytes read by the handler.
+func (c *BodyConfig) captureRequest(r *http.Request) *bodyCapture {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	capture := c.newCapture(r.Header.Get("Content-Type"))
+	if capture == nil {
+		return nil
+	}
+	r.Body = &captureReadCloser{ReadCloser: r.Body, capture: capture}
+	return capture
+}
+
+// bodyCapture keeps the first max bytes of a body.
+type bodyCapture struct {
+	contentType string
+	max         int
+	buf         bytes.Buffer
+	truncated   bool
+}
+
+func (b *bodyCapture) add(p []byte) {
+	if b == nil {
+		return
+	}
+	if rest := b.max - b.buf.Len(); len(p) > rest {
+		p = p[:rest]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+}
+
+// fields returns the fields of the captured body.
+func (b *bodyCapture) fields(key string, c *BodyConfig) []zap.Field {
+	if b == nil || (b.buf.Len() == 0 && !b.truncated) {
+		return nil
+	}
+	body := b.buf.Bytes()
+	if c.Redact != nil {
+		body = c.Redact(b.contentType, body)
+	}
+	var fields []zap.Field
+	if !b.truncated && json.Valid(body) {
+		fields = append(fields, zlf.JSON(key, body))
+	} else {
+		fields = append(fields, zap.ByteString(key, body))
+	}
+	if b.truncated {
+		fields = append(fields, zap.Bool(key+truncatedSuffix, true))
+	}
+	return fields
+}
+
+// captureReadCloser captures the bytes read from the request body.
+type captureReadCloser struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *captureReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.add(p[:n])
+	return n, err
+}
+
+// RedactJSONKeys returns the Redact func of BodyConfig that replaces the values of keys in the json bodies
+// with zl.RedactedValue. The keys are case-insensitive. The bodies of the other media types are returned as they are.
+// The json bodies that cannot be parsed such as the truncated ones are replaced with zl.RedactedValue entirely
+// because the keys cannot be found in them.
+func RedactJSONKeys(keys ...string) func(contentType string, body []byte) []byte {
+	lower := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		lower[strings.ToLower(key)] = true
+	}
+	return func(contentType string, body []byte) []byte {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return body
+		}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return []byte(zl.RedactedValue)
+		}
+		b, err := json.Marshal(redactJSON(v, lower))
+		if err != nil {
+			return body
+		}
+		return b
+	}
+}
+
+func redactJSON(v interface{}, keys map[string]bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if keys[strings.ToLower(k)] {
+				val[k] = zl.RedactedValue
+			} else {
+				val[k] = redactJSON(child, keys)
+			}
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = redactJSON(val[i], keys)
+		}
+	}
+	return v
+}
@@ -0,0 +1,93 @@
+package zlhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/stretchr/testify/assert"
+)
+
+func bodyHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(b)
+	})
+}
+
+func TestMiddleware_body(t *testing.T) {
+	config := Config{Body: &BodyConfig{
+		Routes:   []string{"/api/*", "POST /login"},
+		MaxBytes: 32,
+		Redact:   RedactJSONKeys("password"),
+	}}
+	serve := func(method, path, contentType, body string) map[string]interface{} {
+		obs := zl.NewTestObserver(t)
+		zl.Init()
+		h := Middleware(bodyHandler(t), config)
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		assert.Equal(t, body, rec.Body.String(), "the bodies are not changed")
+		logs := obs.FilterMessage(DefaultMessage).All()
+		if !assert.Len(t, logs, 1) {
+			return nil
+		}
+		return logs[0].ContextMap()
+	}
+
+	t.Run("json", func(t *testing.T) {
+		fields := serve("POST", "/login", "application/json", `{"user":"alice","password":"x"}`)
+		assert.Equal(t, json.RawMessage(`{"password":"[REDACTED]","user":"alice"}`), fields[RequestBodyKey])
+		assert.Equal(t, fields[RequestBodyKey], fields[ResponseBodyKey])
+		assert.NotContains(t, fields, RequestBodyKey+truncatedSuffix)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		fields := serve("POST", "/api/users", "text/plain; charset=utf-8", strings.Repeat("a", 40))
+		assert.Equal(t, strings.Repeat("a", 32), fields[RequestBodyKey])
+		assert.Equal(t, true, fields[RequestBodyKey+truncatedSuffix])
+		assert.Equal(t, true, fields[ResponseBodyKey+truncatedSuffix])
+	})
+	t.Run("truncated json is redacted", func(t *testing.T) {
+		fields := serve("POST", "/api/users", "application/json", `{"name":"alice","password":"secret-password"}`)
+		assert.Equal(t, zl.RedactedValue, fields[RequestBodyKey])
+	})
+	t.Run("content type not allowed", func(t *testing.T) {
+		fields := serve("POST", "/api/upload", "application/octet-stream", "binary")
+		assert.NotContains(t, fields, RequestBodyKey)
+		assert.NotContains(t, fields, ResponseBodyKey)
+	})
+	t.Run("route not allowed", func(t *testing.T) {
+		fields := serve("GET", "/login", "application/json", `{"a":1}`)
+		assert.NotContains(t, fields, RequestBodyKey)
+		fields = serve("POST", "/api/users/1", "application/json", `{"a":1}`)
+		assert.NotContains(t, fields, RequestBodyKey)
+	})
+}
+
+func TestBodyConfig_contentTypeAllowed(t *testing.T) {
+	c := &BodyConfig{}
+	assert.True(t, c.contentTypeAllowed("application/json; charset=utf-8"))
+	assert.True(t, c.contentTypeAllowed("application/problem+json"))
+	assert.True(t, c.contentTypeAllowed("text/html"))
+	assert.False(t, c.contentTypeAllowed("image/png"))
+	assert.False(t, c.contentTypeAllowed(""))
+	c.ContentTypes = []string{"application/x-www-form-urlencoded"}
+	assert.True(t, c.contentTypeAllowed("application/x-www-form-urlencoded"))
+	assert.False(t, c.contentTypeAllowed("application/json"))
+}
+
+func TestRedactJSONKeys(t *testing.T) {
+	redact := RedactJSONKeys("Token")
+	assert.Equal(t, `{"items":[{"token":"[REDACTED]"}],"n":1}`,
+		string(redact("application/json", []byte(`{"items":[{"token":"x"}],"n":1}`))))
+	assert.Equal(t, "token=x", string(redact("text/plain", []byte("token=x"))))
+	assert.Equal(t, zl.RedactedValue, string(redact("application/json", []byte(`{"token":`))))
+}
@@ -0,0 +1,186 @@
+// Package zlhttp provides the net/http middleware and the helpers to log the HTTP requests with zl.
+// e.g. http.ListenAndServe(":8080", zlhttp.Middleware(mux, zlhttp.Config{}))
+package zlhttp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/nkmr-jp/zl/zlf"
+	"github.com/nkmr-jp/zl/zlnet"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultMessage is the default message of the access logs of Middleware.
+	DefaultMessage = "HTTP_REQUEST"
+
+	// StatusKey is the field key of the status code of the response.
+	StatusKey = "status"
+	// ResponseSizeKey is the field key of the bytes written to the response.
+	ResponseSizeKey = "response_size"
+)
+
+// Config is the config of Middleware.
+type Config struct {
+	// Logger is the logger of the access logs. Default is zl.New(), which is created by the first request.
+	Logger *zl.Logger
+	// Message is the message of the access logs. Default is DefaultMessage.
+	Message string
+	// Body is the config of the request and the response bodies to log. nil (default) logs no bodies.
+	Body *BodyConfig
+	// LogHijackedConns logs the lifecycle of the connections hijacked by the handlers such as the WebSocket upgrades.
+	// See: zlnet.WrapConn
+	LogHijackedConns bool
+}
+
+// Middleware returns the handler that logs an access log of each request after next has served it.
+// The log has the request (see: zlf.HTTPRequest), the status, the response size and the elapsed time,
+// and its level is WARN for 4xx and ERROR for 5xx.
+// The trace id of the traceparent header is added to the log and the context of the request. See: zl.WithTraceID
+func Middleware(next http.Handler, config Config) http.Handler {
+	if config.Message == "" {
+		config.Message = DefaultMessage
+	}
+	// The default logger is created by the first request so that Middleware can be called before zl.Init.
+	defaultLogger := sync.OnceValue(func() *zl.Logger { return zl.New() })
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := config.Logger
+		if l == nil {
+			l = defaultLogger()
+		}
+		start := zl.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		if config.LogHijackedConns {
+			rw.connLogger = l
+		}
+		traceID := requestTraceID(r)
+		if traceID != "" {
+			r = r.WithContext(zl.WithTraceID(r.Context(), traceID))
+		}
+		var reqBody *bodyCapture
+		if config.Body.routeAllowed(r) {
+			reqBody = config.Body.captureRequest(r)
+			rw.body = config.Body
+		}
+		next.ServeHTTP(rw, r)
+
+		elapsed := zl.Now().Sub(start)
+		fields := []zap.Field{
+			zl.Console(fmt.Sprintf("%s %s %d %s", r.Method, r.URL.Path, rw.status, elapsed.Round(time.Microsecond))),
+			zlf.HTTPRequest(r),
+			zap.Int(StatusKey, rw.status),
+			zap.Int64(ResponseSizeKey, rw.size),
+			zap.Duration(zl.ElapsedKey, elapsed),
+		}
+		if traceID != "" {
+			fields = append(fields, zap.String(zl.TraceIDKey, traceID))
+		}
+		fields = append(fields, reqBody.fields(RequestBodyKey, config.Body)...)
+		fields = append(fields, rw.capture.fields(ResponseBodyKey, config.Body)...)
+		log(l, statusLevel(rw.status), config.Message, fields)
+	})
+}
+
+// statusLevel returns the level of the access log of status.
+func statusLevel(status int) zapcore.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return zl.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return zl.WarnLevel
+	}
+	return zl.InfoLevel
+}
+
+func log(l *zl.Logger, level zapcore.Level, message string, fields []zap.Field) {
+	switch level {
+	case zl.ErrorLevel:
+		l.Error(message, fields...)
+	case zl.WarnLevel:
+		l.Warn(message, fields...)
+	default:
+		l.Info(message, fields...)
+	}
+}
+
+// responseWriter records the status, the size and the body of the response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+
+	body    *BodyConfig // body is the config of the body capture. nil means the body is not captured.
+	capture *bodyCapture
+
+	connLogger *zl.Logger // connLogger is the logger of the hijacked connection. nil means it is not logged.
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.body != nil && w.capture == nil {
+		w.capture = w.body.newCapture(w.Header().Get("Content-Type"))
+	}
+	w.capture.add(p)
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer implements it.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying writer implements it.
+// The status of the access log is 101 Switching Protocols if the handler has not written it.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("zlhttp: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	if w.connLogger != nil {
+		conn = zlnet.WrapConn(conn, w.connLogger)
+		// The buffers are replaced so that the bytes through them are counted by the wrapped conn.
+		buffered, _ := rw.Reader.Peek(rw.Reader.Buffered())
+		rw = bufio.NewReadWriter(
+			bufio.NewReader(io.MultiReader(bytes.NewReader(bytes.Clone(buffered)), conn)),
+			bufio.NewWriter(conn),
+		)
+	}
+	return conn, rw, nil
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
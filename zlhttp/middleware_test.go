@@ -0,0 +1,135 @@
+package zlhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/nkmr-jp/zl/zlnet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(http.StatusOK)
+		default:
+			_, _ = io.WriteString(w, "hello")
+		}
+	}), Config{})
+
+	for _, path := range []string{"/users", "/missing", "/fail"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	logs := obs.FilterMessage(DefaultMessage).All()
+	if assert.Len(t, logs, 3) {
+		assert.Equal(t, zl.InfoLevel, logs[0].Level)
+		assert.Equal(t, int64(http.StatusOK), logs[0].ContextMap()[StatusKey])
+		assert.Equal(t, int64(5), logs[0].ContextMap()[ResponseSizeKey])
+		assert.Contains(t, logs[0].ContextMap(), zl.ElapsedKey)
+		assert.Equal(t, "GET", logs[0].ContextMap()["http_request"].(map[string]interface{})["method"])
+		assert.True(t, strings.HasPrefix(logs[0].ContextMap()["console"].(string), "GET /users 200 "))
+		assert.Equal(t, zl.WarnLevel, logs[1].Level)
+		assert.Equal(t, zl.ErrorLevel, logs[2].Level)
+		assert.Equal(t, int64(http.StatusInternalServerError), logs[2].ContextMap()[StatusKey])
+		assert.NotContains(t, logs[0].ContextMap(), RequestBodyKey, "the bodies are not logged by default")
+	}
+}
+
+// stepClock is a zapcore.Clock that advances by step for each Now.
+type stepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func (c *stepClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func TestMiddleware_clock(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), Config{})
+	zl.SetClock(&stepClock{step: 1500 * time.Microsecond})
+	zl.Init()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+
+	logs := obs.FilterMessage(DefaultMessage).All()
+	if assert.Len(t, logs, 1, "the default logger is created after zl.Init") {
+		assert.Equal(t, 1500*time.Microsecond, logs[0].ContextMap()[zl.ElapsedKey])
+		assert.Equal(t, "GET /users 200 1.5ms", logs[0].ContextMap()["console"])
+	}
+}
+
+func TestMiddleware_logger(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		assert.NotNil(t, http.NewResponseController(w))
+	}), Config{Logger: zl.New().Named("api"), Message: "ACCESS"})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	logs := obs.FilterMessage("ACCESS").All()
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, "api", logs[0].LoggerName)
+	}
+}
+
+func TestMiddleware_traceID(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", zl.TraceID(r.Context()))
+	}), Config{})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, 1, obs.FilterField(zl.TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736").Len())
+}
+
+func TestMiddleware_hijack(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	srv := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\nping")
+		_ = rw.Flush()
+	}), Config{LogHijackedConns: true}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ping", string(b))
+
+	assert.Eventually(t, func() bool { return obs.FilterMessage(zlnet.ConnClosedMessage).Len() == 1 }, time.Second, 5*time.Millisecond)
+	closed := obs.FilterMessage(zlnet.ConnClosedMessage).All()[0]
+	assert.Greater(t, closed.ContextMap()[zlnet.BytesWrittenKey], int64(4))
+	assert.Equal(t, 1, obs.FilterMessage(DefaultMessage).FilterField(StatusKey, http.StatusSwitchingProtocols).Len())
+}
@@ -0,0 +1,36 @@
+package zlhttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceParentHeader is the header of the W3C Trace Context. See: https://www.w3.org/TR/trace-context/
+const TraceParentHeader = "traceparent"
+
+// parseTraceParent returns the trace id of the traceparent header value. e.g. "00-<trace id>-<span id>-01"
+func parseTraceParent(v string) (traceID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || parts[1] == strings.Repeat("0", 32) {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// requestTraceID returns the trace id of the traceparent header of r.
+func requestTraceID(r *http.Request) string {
+	id, _ := parseTraceParent(r.Header.Get(TraceParentHeader))
+	return id
+}
+
+// newTraceParent returns the traceparent header value of traceID with a new span id.
+func newTraceParent(traceID string) string {
+	span := make([]byte, 8)
+	_, _ = rand.Read(span)
+	return "00-" + traceID + "-" + hex.EncodeToString(span) + "-01"
+}
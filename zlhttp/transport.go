@@ -0,0 +1,185 @@
+package zlhttp
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTransportMessage is the default message of the logs of Transport.
+	DefaultTransportMessage = "HTTP_CLIENT_REQUEST"
+
+	// DefaultRetryBackoff is the default backoff of the first retry of Transport.
+	DefaultRetryBackoff = 100 * time.Millisecond
+
+	// RetriesKey is the field key of the number of the retries of Transport.
+	RetriesKey = "retries"
+)
+
+// Transport is the http.RoundTripper that logs the outbound requests, so that all the egress calls are observable
+// by setting it to the clients. e.g. client := &http.Client{Transport: zlhttp.NewTransport(nil)}
+// The log has the method, the url, the status, the elapsed time and the retries, and its level is WARN for 4xx,
+// the slow requests, and ERROR for 5xx and the errors.
+// The trace id of the traceparent header or the context (see: zl.WithTraceID) is logged,
+// and the traceparent header is added to the request if it does not have it.
+type Transport struct {
+	// Base is the transport that sends the requests. Default is http.DefaultTransport.
+	Base http.RoundTripper
+	// Logger is the logger of the logs. Default is zl.New(), which is created by the first request.
+	Logger *zl.Logger
+	// Message is the message of the logs. Default is DefaultTransportMessage.
+	Message string
+	// SlowThreshold is the elapsed time over which the request is logged as WARN with the zl.ThresholdKey field.
+	// 0 (default) disables it.
+	SlowThreshold time.Duration
+	// MaxRetries is the max retries of the idempotent requests on the errors and the 502, 503 and 504 responses.
+	// The requests with the body are retried only if they have GetBody. 0 (default) disables the retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, and it doubles each retry. Default is DefaultRetryBackoff.
+	RetryBackoff time.Duration
+
+	defaultLoggerOnce sync.Once
+	defaultLogger     *zl.Logger
+}
+
+// NewTransport returns the Transport of base. nil base means http.DefaultTransport.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	traceID := requestTraceID(req)
+	if traceID == "" {
+		if traceID = zl.TraceID(req.Context()); traceID != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set(TraceParentHeader, newTraceParent(traceID))
+		}
+	}
+
+	start := zl.Now()
+	resp, retries, err := t.roundTrip(req)
+	elapsed := zl.Now().Sub(start)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	fields := []zap.Field{
+		zl.Console(fmt.Sprintf("%s %s %d %s", req.Method, req.URL.Redacted(), status, elapsed.Round(time.Microsecond))),
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.Redacted()),
+		zap.Int(StatusKey, status),
+		zap.Duration(zl.ElapsedKey, elapsed),
+		zap.Int(RetriesKey, retries),
+	}
+	if traceID != "" {
+		fields = append(fields, zap.String(zl.TraceIDKey, traceID))
+	}
+	level := statusLevel(status)
+	if t.SlowThreshold > 0 && elapsed > t.SlowThreshold {
+		fields = append(fields, zap.Duration(zl.ThresholdKey, t.SlowThreshold))
+		if level < zl.WarnLevel {
+			level = zl.WarnLevel
+		}
+	}
+	l := t.logger()
+	message := t.Message
+	if message == "" {
+		message = DefaultTransportMessage
+	}
+	if err != nil {
+		l.ErrorErr(message, err, fields...)
+	} else {
+		log(l, level, message, fields)
+	}
+	return resp, err
+}
+
+// logger returns Logger, or the default logger if it is nil.
+func (t *Transport) logger() *zl.Logger {
+	if t.Logger != nil {
+		return t.Logger
+	}
+	t.defaultLoggerOnce.Do(func() { t.defaultLogger = zl.New() })
+	return t.defaultLogger
+}
+
+// roundTrip sends req with the retries and returns the last response and the number of the retries.
+func (t *Transport) roundTrip(req *http.Request) (resp *http.Response, retries int, err error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	backoff := t.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	for {
+		resp, err = base.RoundTrip(req)
+		if retries >= t.MaxRetries || !retryable(req, resp, err) {
+			return resp, retries, err
+		}
+		next, ok := rewind(req)
+		if !ok {
+			return resp, retries, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, retries, req.Context().Err()
+		case <-timer.C:
+		}
+		req = next
+		retries++
+		backoff *= 2
+	}
+}
+
+// retryable reports whether req can be retried after resp or err.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewind returns the copy of req with the new body to retry.
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, true
+}
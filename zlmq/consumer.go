@@ -0,0 +1,182 @@
+// Package zlmq provides the consumer middleware of the message queues such as Kafka, NATS and SQS to log the messages with zl.
+// e.g.
+//
+//	handler := zlmq.Wrap(func(ctx context.Context, m *kafka.Message) error {
+//		zlmq.FromContext(ctx).Info("ORDER_RECEIVED")
+//		return nil
+//	}, zlmq.Config[*kafka.Message]{Metadata: func(m *kafka.Message) zlmq.Metadata {
+//		return zlmq.Metadata{System: "kafka", Topic: m.Topic, Partition: m.Partition, Offset: m.Offset, HasOffset: true}
+//	}})
+package zlmq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"go.uber.org/zap"
+)
+
+const (
+	// MessageReceivedMessage is the message of the DEBUG log written before the handler is called.
+	MessageReceivedMessage = "MESSAGE_RECEIVED"
+	// MessageProcessedMessage is the message of the INFO log written when the handler returns nil.
+	MessageProcessedMessage = "MESSAGE_PROCESSED"
+	// MessageFailedMessage is the message of the ERROR log written when the handler returns an error or panics.
+	MessageFailedMessage = "MESSAGE_FAILED"
+
+	// SystemKey is the field key of the messaging system. e.g. "kafka"
+	SystemKey = "messaging_system"
+	// TopicKey is the field key of the topic, the subject or the queue of the message.
+	TopicKey = "topic"
+	// PartitionKey is the field key of the partition of the message.
+	PartitionKey = "partition"
+	// OffsetKey is the field key of the offset or the sequence of the message.
+	OffsetKey = "offset"
+	// MessageIDKey is the field key of the id of the message.
+	MessageIDKey = "message_id"
+	// RetriesKey is the field key of the number of the redeliveries of the message.
+	RetriesKey = "retries"
+	// OutcomeKey is the field key of the outcome of the handler. It is one of OutcomeSuccess, OutcomeError and OutcomePanic.
+	OutcomeKey = "outcome"
+)
+
+// The values of OutcomeKey.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomePanic   = "panic"
+)
+
+// Handler is the handler of the messages of type T.
+type Handler[T any] func(ctx context.Context, msg T) error
+
+// Metadata is the metadata of a message logged by Wrap. The empty values are not logged.
+type Metadata struct {
+	// System is the messaging system. e.g. "kafka", "nats", "sqs"
+	System string
+	// Topic is the topic, the subject or the queue of the message.
+	Topic string
+	// Partition and Offset are the position of the message. They are logged only if HasOffset is true
+	// because 0 is a valid partition and offset. e.g. the partition and the offset of Kafka, the stream sequence of NATS JetStream
+	Partition int32
+	Offset    int64
+	HasOffset bool
+	// ID is the id of the message. e.g. the message id of SQS
+	ID string
+	// Retries is the number of the redeliveries of the message.
+	// e.g. NumDelivered-1 of NATS JetStream, ApproximateReceiveCount-1 of SQS
+	Retries int
+	// TraceID is the trace id of the message, which is set to the context by zl.WithTraceID
+	// so that the requests sent by the handler through zlhttp.Transport have the same trace id.
+	TraceID string
+}
+
+// fields returns the fields of m.
+func (m Metadata) fields() []zap.Field {
+	var fields []zap.Field
+	if m.System != "" {
+		fields = append(fields, zap.String(SystemKey, m.System))
+	}
+	if m.Topic != "" {
+		fields = append(fields, zap.String(TopicKey, m.Topic))
+	}
+	if m.HasOffset {
+		fields = append(fields, zap.Int32(PartitionKey, m.Partition), zap.Int64(OffsetKey, m.Offset))
+	}
+	if m.ID != "" {
+		fields = append(fields, zap.String(MessageIDKey, m.ID))
+	}
+	fields = append(fields, zap.Int(RetriesKey, m.Retries))
+	if m.TraceID != "" {
+		fields = append(fields, zap.String(zl.TraceIDKey, m.TraceID))
+	}
+	return fields
+}
+
+// Config is the config of Wrap.
+type Config[T any] struct {
+	// Logger is the logger of the logs. Default is zl.New(), which is created by the first message.
+	Logger *zl.Logger
+	// Metadata returns the metadata of msg. The metadata are not logged if it is nil.
+	Metadata func(msg T) Metadata
+}
+
+// Wrap returns the handler that logs MessageReceivedMessage, and MessageProcessedMessage or MessageFailedMessage
+// with the elapsed time and the outcome for each message.
+// All the logs have the fields of the metadata, and the logger with them is set to the context of handler.
+// See: FromContext
+// The panic of handler is logged with the stack by zl.Logger.PanicErr and repanicked.
+func Wrap[T any](handler Handler[T], config Config[T]) Handler[T] {
+	// The default logger is created by the first message so that Wrap can be called before zl.Init.
+	defaultLogger := sync.OnceValue(func() *zl.Logger { return zl.New() })
+	return func(ctx context.Context, msg T) (err error) {
+		var meta Metadata
+		if config.Metadata != nil {
+			meta = config.Metadata(msg)
+		}
+		base := config.Logger
+		if base == nil {
+			base = defaultLogger()
+		}
+		l := base.With(meta.fields()...)
+		ctx = NewContext(ctx, l)
+		if meta.TraceID != "" {
+			ctx = zl.WithTraceID(ctx, meta.TraceID)
+		}
+
+		l.Debug(MessageReceivedMessage)
+		start := zl.Now()
+		defer func() {
+			elapsed := zl.Now().Sub(start)
+			fields := []zap.Field{zl.Console(consoleSummary(meta, elapsed)), zap.Duration(zl.ElapsedKey, elapsed)}
+			if r := recover(); r != nil {
+				l.PanicErr(MessageFailedMessage, r, append(fields, zap.String(OutcomeKey, OutcomePanic))...)
+				panic(r)
+			}
+			if err != nil {
+				l.ErrorErr(MessageFailedMessage, err, append(fields, zap.String(OutcomeKey, OutcomeError))...)
+				return
+			}
+			l.Info(MessageProcessedMessage, append(fields, zap.String(OutcomeKey, OutcomeSuccess))...)
+		}()
+		return handler(ctx, msg)
+	}
+}
+
+// consoleSummary returns the Console summary of the message. e.g. "orders[2]@15 1.2ms"
+func consoleSummary(meta Metadata, elapsed time.Duration) string {
+	s := meta.Topic
+	if meta.HasOffset {
+		s += fmt.Sprintf("[%d]@%d", meta.Partition, meta.Offset)
+	} else if meta.ID != "" {
+		s += " " + meta.ID
+	}
+	if meta.Retries > 0 {
+		s += fmt.Sprintf(" retry %d", meta.Retries)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s", s, elapsed.Round(time.Microsecond)))
+}
+
+type loggerContextKey struct{}
+
+// NewContext returns the context with l. See: FromContext
+func NewContext(ctx context.Context, l *zl.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// contextDefaultLogger is the logger of FromContext for the contexts without the logger.
+// It is created by the first call so that it has the settings of zl.Init.
+var contextDefaultLogger = sync.OnceValue(func() *zl.Logger { return zl.New() })
+
+// FromContext returns the logger of the message set by Wrap,
+// or the default logger shared by all the calls (zl.New()) if it is not set.
+func FromContext(ctx context.Context) *zl.Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*zl.Logger); ok {
+		return l
+	}
+	return contextDefaultLogger()
+}
@@ -0,0 +1,150 @@
+package zlmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testMessage struct {
+	topic     string
+	partition int32
+	offset    int64
+	delivered int
+}
+
+func testMetadata(m testMessage) Metadata {
+	return Metadata{
+		System:    "kafka",
+		Topic:     m.topic,
+		Partition: m.partition,
+		Offset:    m.offset,
+		HasOffset: true,
+		Retries:   m.delivered - 1,
+		TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+}
+
+func TestWrap(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.SetLevel(zl.DebugLevel)
+	zl.Init()
+	errFailed := errors.New("failed")
+	h := Wrap(func(ctx context.Context, m testMessage) error {
+		FromContext(ctx).Info("HANDLED", zap.String("step", "save"))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", zl.TraceID(ctx))
+		if m.offset == 2 {
+			return errFailed
+		}
+		return nil
+	}, Config[testMessage]{Logger: zl.New().Named("consumer"), Metadata: testMetadata})
+
+	assert.NoError(t, h(context.Background(), testMessage{topic: "orders", partition: 1, offset: 1, delivered: 1}))
+	assert.ErrorIs(t, h(context.Background(), testMessage{topic: "orders", partition: 1, offset: 2, delivered: 3}), errFailed)
+
+	assert.Equal(t, 2, obs.FilterMessage(MessageReceivedMessage).FilterLevel(zl.DebugLevel).Len())
+	handled := obs.FilterMessage("HANDLED").All()
+	if assert.Len(t, handled, 2) {
+		fields := handled[0].ContextMap()
+		assert.Equal(t, "consumer", handled[0].LoggerName)
+		assert.Equal(t, "kafka", fields[SystemKey])
+		assert.Equal(t, "orders", fields[TopicKey])
+		assert.Equal(t, int32(1), fields[PartitionKey])
+		assert.Equal(t, int64(1), fields[OffsetKey])
+		assert.Equal(t, int64(0), fields[RetriesKey])
+		assert.Equal(t, "save", fields["step"])
+	}
+	processed := obs.FilterMessage(MessageProcessedMessage).All()
+	if assert.Len(t, processed, 1) {
+		assert.Equal(t, zl.InfoLevel, processed[0].Level)
+		assert.Equal(t, OutcomeSuccess, processed[0].ContextMap()[OutcomeKey])
+		assert.Contains(t, processed[0].ContextMap(), zl.ElapsedKey)
+	}
+	failed := obs.FilterMessage(MessageFailedMessage).All()
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, zl.ErrorLevel, failed[0].Level)
+		assert.Equal(t, OutcomeError, failed[0].ContextMap()[OutcomeKey])
+		assert.Equal(t, "failed", failed[0].ContextMap()["error"])
+		assert.Equal(t, int64(2), failed[0].ContextMap()[RetriesKey])
+	}
+}
+
+// testClock is a zapcore.Clock that returns the time advanced by add.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWrap_clock(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	clock := &testClock{now: time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)}
+	h := Wrap(func(ctx context.Context, m testMessage) error {
+		clock.add(1500 * time.Microsecond)
+		return nil
+	}, Config[testMessage]{Metadata: testMetadata})
+	zl.SetClock(clock)
+	zl.Init()
+
+	assert.NoError(t, h(context.Background(), testMessage{topic: "orders", partition: 2, offset: 15, delivered: 1}))
+	processed := obs.FilterMessage(MessageProcessedMessage).All()
+	if assert.Len(t, processed, 1, "the default logger is created after zl.Init") {
+		assert.Equal(t, 1500*time.Microsecond, processed[0].ContextMap()[zl.ElapsedKey])
+		assert.Equal(t, "orders[2]@15 1.5ms", processed[0].ContextMap()["console"])
+	}
+}
+
+func TestWrap_panic(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	h := Wrap(func(ctx context.Context, m string) error {
+		panic("boom")
+	}, Config[string]{})
+
+	assert.PanicsWithValue(t, "boom", func() { _ = h(context.Background(), "msg") })
+	failed := obs.FilterMessage(MessageFailedMessage).All()
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, OutcomePanic, failed[0].ContextMap()[OutcomeKey])
+		assert.Equal(t, "string", failed[0].ContextMap()[zl.PanicTypeKey])
+		assert.Equal(t, "panic: boom", failed[0].ContextMap()["error"])
+		assert.Contains(t, failed[0].ContextMap()["errorVerbose"], "consumer_test.go:")
+		assert.NotContains(t, failed[0].ContextMap(), TopicKey, "no metadata")
+	}
+}
+
+func Test_consoleSummary(t *testing.T) {
+	assert.Equal(t, "orders[2]@15 retry 1 1.5ms",
+		consoleSummary(Metadata{Topic: "orders", Partition: 2, Offset: 15, HasOffset: true, Retries: 1}, 1500*time.Microsecond))
+	assert.Equal(t, "jobs abc 1ms", consoleSummary(Metadata{Topic: "jobs", ID: "abc"}, time.Millisecond))
+	assert.Equal(t, "1ms", consoleSummary(Metadata{}, time.Millisecond))
+}
+
+func TestFromContext(t *testing.T) {
+	zl.NewTestObserver(t)
+	zl.Init()
+	assert.NotNil(t, FromContext(context.Background()))
+	assert.Same(t, FromContext(context.Background()), FromContext(context.TODO()), "the default logger is shared")
+	l := zl.New()
+	assert.Same(t, l, FromContext(NewContext(context.Background(), l)))
+}
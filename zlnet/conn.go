@@ -0,0 +1,157 @@
+// Package zlnet provides the wrappers of net.Conn and net.Listener that log the lifecycle of the connections with zl,
+// for the servers of the long-lived connections such as WebSockets and the streaming protocols.
+// e.g. ln = zlnet.WrapListener(ln, nil)
+package zlnet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"go.uber.org/zap"
+)
+
+const (
+	// ConnOpenedMessage is the message of the log written when the connection is wrapped.
+	ConnOpenedMessage = "CONN_OPENED"
+	// ConnClosedMessage is the message of the log written when the connection is closed.
+	ConnClosedMessage = "CONN_CLOSED"
+	// ConnErrorMessage is the message of the log written at the first read or write error of the connection.
+	ConnErrorMessage = "CONN_ERROR"
+
+	// ConnIDKey is the field key of the connection id.
+	ConnIDKey = "conn_id"
+	// BytesReadKey is the field key of the bytes read from the connection.
+	BytesReadKey = "bytes_read"
+	// BytesWrittenKey is the field key of the bytes written to the connection.
+	BytesWrittenKey = "bytes_written"
+)
+
+// Conn is the net.Conn that logs ConnOpenedMessage, ConnClosedMessage with the bytes transferred and the duration,
+// and ConnErrorMessage at the first error other than io.EOF and net.ErrClosed.
+// All the logs have the ConnIDKey field. Use Field to add it to the other logs of the connection.
+type Conn struct {
+	net.Conn
+	id      string
+	logger  *zl.Logger
+	start   time.Time
+	read    atomic.Int64
+	written atomic.Int64
+
+	errOnce   sync.Once
+	closeOnce sync.Once
+	failed    atomic.Bool
+}
+
+// WrapConn returns the Conn of conn and logs ConnOpenedMessage. nil logger means zl.New().
+func WrapConn(conn net.Conn, logger *zl.Logger) *Conn {
+	if logger == nil {
+		logger = zl.New()
+	}
+	c := &Conn{Conn: conn, id: newConnID(), logger: logger, start: zl.Now()}
+	c.logger.Info(ConnOpenedMessage,
+		zl.Console(fmt.Sprintf("%s %s", conn.RemoteAddr(), c.id)),
+		c.Field(),
+		zap.String("network", conn.LocalAddr().Network()),
+		zap.Stringer("local_addr", conn.LocalAddr()),
+		zap.Stringer("remote_addr", conn.RemoteAddr()),
+	)
+	return c
+}
+
+// ID returns the connection id.
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Field returns the ConnIDKey field of the connection.
+func (c *Conn) Field() zap.Field {
+	return zap.String(ConnIDKey, c.id)
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	c.logError("read", err)
+	return n, err
+}
+
+func (c *Conn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	c.logError("write", err)
+	return n, err
+}
+
+// Close closes the connection and logs ConnClosedMessage once. The level is WARN if an error has been logged.
+func (c *Conn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		elapsed := zl.Now().Sub(c.start)
+		fields := []zap.Field{
+			zl.Console(fmt.Sprintf("%s %s, read: %d B, written: %d B, %s",
+				c.RemoteAddr(), c.id, c.read.Load(), c.written.Load(), elapsed.Round(time.Millisecond))),
+			c.Field(),
+			zap.Stringer("remote_addr", c.RemoteAddr()),
+			zap.Int64(BytesReadKey, c.read.Load()),
+			zap.Int64(BytesWrittenKey, c.written.Load()),
+			zap.Duration(zl.ElapsedKey, elapsed),
+		}
+		if c.failed.Load() {
+			c.logger.Warn(ConnClosedMessage, fields...)
+		} else {
+			c.logger.Info(ConnClosedMessage, fields...)
+		}
+	})
+	return err
+}
+
+// logError logs ConnErrorMessage at the first error of the connection.
+func (c *Conn) logError(op string, err error) {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+	c.errOnce.Do(func() {
+		c.failed.Store(true)
+		c.logger.WarnErr(ConnErrorMessage, err, c.Field(), zap.String("op", op))
+	})
+}
+
+// newConnID returns a random id of the connection.
+func newConnID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// listener wraps the connections accepted by the listener with WrapConn.
+type listener struct {
+	net.Listener
+	logger func() *zl.Logger
+}
+
+// WrapListener returns the listener that wraps the accepted connections with WrapConn.
+// nil logger means zl.New(), which is created by the first connection and shared by all the connections.
+// e.g. http.Serve(zlnet.WrapListener(ln, nil), handler)
+func WrapListener(ln net.Listener, logger *zl.Logger) net.Listener {
+	l := &listener{Listener: ln, logger: func() *zl.Logger { return logger }}
+	if logger == nil {
+		l.logger = sync.OnceValue(func() *zl.Logger { return zl.New() })
+	}
+	return l
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return WrapConn(conn, l.logger()), nil
+}
@@ -0,0 +1,146 @@
+package zlnet
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nkmr-jp/zl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapConn(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	server, client := net.Pipe()
+	c := WrapConn(server, nil)
+	assert.Len(t, c.ID(), 16)
+
+	go func() {
+		_, _ = client.Write([]byte("hello"))
+		b := make([]byte, 3)
+		_, _ = io.ReadFull(client, b)
+		client.Close()
+	}()
+	b := make([]byte, 5)
+	_, err := io.ReadFull(c, b)
+	assert.NoError(t, err)
+	_, err = c.Write([]byte("bye"))
+	assert.NoError(t, err)
+	_, err = c.Read(b)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.NoError(t, c.Close())
+	_ = c.Close()
+
+	assert.Equal(t, 1, obs.FilterMessage(ConnOpenedMessage).FilterField(ConnIDKey, c.ID()).Len())
+	assert.Equal(t, 0, obs.FilterMessage(ConnErrorMessage).Len(), "io.EOF is not an error")
+	closed := obs.FilterMessage(ConnClosedMessage).All()
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, zl.InfoLevel, closed[0].Level)
+		assert.Equal(t, c.ID(), closed[0].ContextMap()[ConnIDKey])
+		assert.Equal(t, int64(5), closed[0].ContextMap()[BytesReadKey])
+		assert.Equal(t, int64(3), closed[0].ContextMap()[BytesWrittenKey])
+		assert.Contains(t, closed[0].ContextMap(), zl.ElapsedKey)
+	}
+}
+
+// testClock is a zapcore.Clock that returns the time advanced by add.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWrapConn_clock(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	clock := &testClock{now: time.Date(2023, 9, 9, 15, 0, 0, 0, time.UTC)}
+	zl.SetClock(clock)
+	zl.Init()
+	server, client := net.Pipe()
+	defer client.Close()
+	c := WrapConn(server, nil)
+	clock.add(90 * time.Second)
+	assert.NoError(t, c.Close())
+
+	closed := obs.FilterMessage(ConnClosedMessage).All()
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, 90*time.Second, closed[0].ContextMap()[zl.ElapsedKey])
+	}
+}
+
+func TestWrapConn_error(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	server, client := net.Pipe()
+	defer client.Close()
+	c := WrapConn(server, zl.New().Named("ws"))
+	assert.NoError(t, c.SetReadDeadline(time.Now()))
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Read(make([]byte, 1))
+		assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+	}
+	assert.NoError(t, c.Close())
+
+	errs := obs.FilterMessage(ConnErrorMessage).All()
+	if assert.Len(t, errs, 1, "only the first error is logged") {
+		assert.Equal(t, "ws", errs[0].LoggerName)
+		assert.Equal(t, "read", errs[0].ContextMap()["op"])
+	}
+	assert.Equal(t, 1, obs.FilterMessage(ConnClosedMessage).FilterLevel(zl.WarnLevel).Len())
+}
+
+func TestWrapListener(t *testing.T) {
+	obs := zl.NewTestObserver(t)
+	zl.Init()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	ln = WrapListener(ln, nil)
+	defer ln.Close()
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err == nil {
+				conn.Close()
+			}
+		}
+	}()
+	var conns []*Conn
+	for i := 0; i < 2; i++ {
+		conn, err := ln.Accept()
+		assert.NoError(t, err)
+		if assert.IsType(t, &Conn{}, conn) {
+			conns = append(conns, conn.(*Conn))
+		}
+		assert.NoError(t, conn.Close())
+	}
+	if assert.Len(t, conns, 2) {
+		assert.Same(t, conns[0].logger, conns[1].logger, "the default logger is shared")
+	}
+
+	opened := obs.FilterMessage(ConnOpenedMessage).All()
+	if assert.Len(t, opened, 2) {
+		assert.Equal(t, "tcp", opened[0].ContextMap()["network"])
+		assert.Equal(t, ln.Addr().String(), opened[0].ContextMap()["local_addr"])
+	}
+}